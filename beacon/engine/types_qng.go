@@ -3,11 +3,28 @@ package engine
 import (
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/trie"
 	"math/big"
 )
 
+// QngEngineVersion is the version of the QNG engine API implemented by this
+// build. It is bumped on every incompatible change of the QNG methods.
+const QngEngineVersion = 1
+
+// IncompatibleQngEngine is returned by the QNG capability handshake if the two
+// sides can't agree on a common set of methods and payload versions.
+var IncompatibleQngEngine = &EngineAPIError{code: -38100, msg: "Incompatible QNG engine"}
+
+// QngCapabilities describes the QNG engine API supported by one side of a
+// MeerDAG driver <-> execution node connection.
+type QngCapabilities struct {
+	Version         hexutil.Uint64   `json:"version"`
+	PayloadVersions []hexutil.Uint64 `json:"payloadVersions"`
+	Methods         []string         `json:"methods"`
+}
+
 func ExecutableDataToBlockQng(params ExecutableData, versionedHashes []common.Hash, beaconRoot *common.Hash) (*types.Block, error) {
 	txs, err := decodeTransactions(params.Transactions)
 	if err != nil {
@@ -67,3 +84,18 @@ func ExecutableDataToBlockQng(params ExecutableData, versionedHashes []common.Ha
 	}
 	return block, nil
 }
+
+// ForcedTransactions decodes the transactions the QNG driver forces at the top
+// of the payload, in order.
+func (p *PayloadAttributes) ForcedTransactions() ([]*types.Transaction, error) {
+	txs, err := decodeTransactions(p.Transactions)
+	if err != nil {
+		return nil, err
+	}
+	for i, tx := range txs {
+		if tx.Type() == types.BlobTxType && tx.BlobTxSidecar() == nil {
+			return nil, fmt.Errorf("forced transaction %d: blob transaction without sidecar", i)
+		}
+	}
+	return txs, nil
+}
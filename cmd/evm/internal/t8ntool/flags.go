@@ -137,6 +137,10 @@ var (
 		Usage: "ChainID to use",
 		Value: 1,
 	}
+	MeerNetworksFlag = &cli.StringFlag{
+		Name:  "state.meernetworks",
+		Usage: "File name of a JSON list of Qitmeer networks to register, selected by the chain id",
+	}
 	ForknameFlag = &cli.StringFlag{
 		Name: "state.fork",
 		Usage: fmt.Sprintf("Name of ruleset to use."+
@@ -181,6 +181,18 @@ func Transition(ctx *cli.Context) error {
 	// Set the chain id
 	chainConfig.ChainID = big.NewInt(ctx.Int64(ChainIDFlag.Name))
 
+	// Register the Qitmeer networks the chain id may refer to
+	if file := ctx.String(MeerNetworksFlag.Name); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return NewError(ErrorIO, fmt.Errorf("failed reading Qitmeer networks: %v", err))
+		}
+		if err := params.MeerNetworks.LoadJSON(data); err != nil {
+			return NewError(ErrorConfig, fmt.Errorf("failed loading Qitmeer networks: %v", err))
+		}
+	}
+	chainConfig.ResolveMeerNetwork()
+
 	if txIt, err = loadTransactions(txStr, inputData, prestate.Env, chainConfig); err != nil {
 		return err
 	}
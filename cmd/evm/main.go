@@ -138,6 +138,16 @@ var (
 		Usage:    "enable return data output",
 		Category: flags.VMCategory,
 	}
+	TracerFlag = &cli.StringFlag{
+		Name:     "tracer",
+		Usage:    "Native or js tracer to run the code with, e.g. callTracer or gasProfiler. The tracer result is output as json",
+		Category: flags.VMCategory,
+	}
+	TracerConfigFlag = &cli.StringFlag{
+		Name:     "tracer.config",
+		Usage:    "The configuration of the tracer specified by --tracer, in JSON format",
+		Category: flags.VMCategory,
+	}
 )
 
 var stateTransitionCommand = &cli.Command{
@@ -162,6 +172,7 @@ var stateTransitionCommand = &cli.Command{
 		t8ntool.InputTxsFlag,
 		t8ntool.ForknameFlag,
 		t8ntool.ChainIDFlag,
+		t8ntool.MeerNetworksFlag,
 		t8ntool.RewardFlag,
 	},
 }
@@ -220,6 +231,8 @@ var traceFlags = []cli.Flag{
 	DisableStackFlag,
 	DisableStorageFlag,
 	DisableReturnDataFlag,
+	TracerFlag,
+	TracerConfigFlag,
 }
 
 var app = flags.NewApp("the evm command line interface")
@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/params"
@@ -118,6 +119,7 @@ func runCmd(ctx *cli.Context) error {
 
 	var (
 		tracer      *tracing.Hooks
+		namedTracer *tracers.Tracer
 		debugLogger *logger.StructLogger
 		statedb     *state.StateDB
 		chainConfig *params.ChainConfig
@@ -127,7 +129,17 @@ func runCmd(ctx *cli.Context) error {
 		blobHashes  []common.Hash  // TODO (MariusVanDerWijden) implement blob hashes in state tests
 		blobBaseFee = new(big.Int) // TODO (MariusVanDerWijden) implement blob fee in state tests
 	)
-	if ctx.Bool(MachineFlag.Name) {
+	if name := ctx.String(TracerFlag.Name); name != "" {
+		var config json.RawMessage
+		if ctx.IsSet(TracerConfigFlag.Name) {
+			config = json.RawMessage(ctx.String(TracerConfigFlag.Name))
+		}
+		var err error
+		if namedTracer, err = tracers.DefaultDirectory.New(name, &tracers.Context{}, config); err != nil {
+			return fmt.Errorf("failed to create tracer %q: %v", name, err)
+		}
+		tracer = namedTracer.Hooks
+	} else if ctx.Bool(MachineFlag.Name) {
 		tracer = logger.NewJSONLogger(logconfig, os.Stdout)
 	} else if ctx.Bool(DebugFlag.Name) {
 		debugLogger = logger.NewStructLogger(logconfig)
@@ -157,6 +169,9 @@ func runCmd(ctx *cli.Context) error {
 	genesis := genesisConfig.MustCommit(db, triedb)
 	sdb := state.NewDatabaseWithNodeDB(db, triedb)
 	statedb, _ = state.New(genesis.Root(), sdb, nil)
+	if namedTracer != nil {
+		statedb.SetLogger(namedTracer.Hooks)
+	}
 	chainConfig = genesisConfig.Config
 
 	if ctx.String(SenderFlag.Name) != "" {
@@ -301,6 +316,13 @@ allocations:     %d
 allocated bytes: %d
 `, initialGas-leftOverGas, stats.time, stats.allocs, stats.bytesAllocated)
 	}
+	if namedTracer != nil {
+		result, err := namedTracer.GetResult()
+		if err != nil {
+			return fmt.Errorf("failed to retrieve tracer result: %v", err)
+		}
+		fmt.Println(string(result))
+	}
 	if tracer == nil {
 		fmt.Printf("%#x\n", output)
 		if err != nil {
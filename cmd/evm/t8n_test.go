@@ -115,6 +115,7 @@ func TestT8n(t *testing.T) {
 	for i, tc := range []struct {
 		base        string
 		input       t8nInput
+		flags       []string
 		output      t8nOutput
 		expExitCode int
 		expOut      string
@@ -287,10 +288,20 @@ func TestT8n(t *testing.T) {
 			output: t8nOutput{alloc: true, result: true},
 			expOut: "exp.json",
 		},
+		{ // Qitmeer UTXO bridge precompile
+			base: "./testdata/33",
+			input: t8nInput{
+				"alloc.json", "txs.json", "env.json", "Paris", "",
+			},
+			flags:  []string{"--state.chainid", "990300", "--state.meernetworks", "./testdata/33/networks.json"},
+			output: t8nOutput{alloc: true, result: true},
+			expOut: "exp.json",
+		},
 	} {
 		args := []string{"t8n"}
 		args = append(args, tc.output.get()...)
 		args = append(args, tc.input.get(tc.base)...)
+		args = append(args, tc.flags...)
 		var qArgs []string // quoted args for debugging purposes
 		for _, arg := range args {
 			if len(arg) == 0 {
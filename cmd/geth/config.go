@@ -181,6 +181,25 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 
 	backend, eth := utils.RegisterEthService(stack, &cfg.Eth)
 
+	// Ensure the local chain is the one pinned by the selected Qitmeer network
+	network := utils.MakeMeerNetwork(ctx)
+	if network != nil && eth != nil {
+		if network.GenesisHash != (common.Hash{}) {
+			if genesis := eth.BlockChain().Genesis().Hash(); genesis != network.GenesisHash {
+				utils.Fatalf("Genesis mismatch for Qitmeer network %s: have %x, want %x", network.Name, genesis, network.GenesisHash)
+			}
+		}
+		config := eth.BlockChain().Config()
+		if config.ChainID == nil || config.ChainID.Cmp(network.ChainID) != 0 {
+			utils.Fatalf("Chain id mismatch for Qitmeer network %s: have %v, want %v", network.Name, config.ChainID, network.ChainID)
+		}
+		if network.Forks != nil {
+			if err := network.Forks.CheckCompatible(config); err != nil {
+				utils.Fatalf("Fork schedule mismatch for Qitmeer network %s: %v", network.Name, err)
+			}
+		}
+	}
+
 	// Create gauge with geth system and build information
 	if eth != nil { // The 'eth' backend may be nil in light mode
 		var protos []string
@@ -231,10 +250,22 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 		blsyncer.SetEngineRPC(rpc.DialInProc(srv))
 		stack.RegisterLifecycle(blsyncer)
 	} else {
-		// Launch the engine API for interacting with external consensus client.
-		err := catalyst.Register(stack, eth)
-		if err != nil {
-			utils.Fatalf("failed to register catalyst service: %v", err)
+		// Launch the engine API for interacting with external consensus client,
+		// along with the QNG engine API for an external MeerDAG driver if requested,
+		// or by default on the networks driven through it.
+		qngEngine := network != nil && network.QngEngine
+		if ctx.IsSet(utils.QngEngineFlag.Name) {
+			qngEngine = ctx.Bool(utils.QngEngineFlag.Name)
+		}
+		if qngEngine {
+			if err := catalyst.RegisterQng(stack, eth); err != nil {
+				utils.Fatalf("failed to register QNG catalyst service: %v", err)
+			}
+		} else {
+			err := catalyst.Register(stack, eth)
+			if err != nil {
+				utils.Fatalf("failed to register catalyst service: %v", err)
+			}
 		}
 	}
 	return stack
@@ -71,8 +71,14 @@ var (
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
 		utils.TxPoolRejournalFlag,
+		utils.TxPoolSnapshotFlag,
+		utils.TxPoolSnapshotLimitFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
+		utils.TxPoolTipBumpFlag,
+		utils.TxPoolFeeCapBumpFlag,
+		utils.TxPoolMinBumpFlag,
+		utils.TxPoolRecipientBumpFlag,
 		utils.TxPoolAccountSlotsFlag,
 		utils.TxPoolGlobalSlotsFlag,
 		utils.TxPoolAccountQueueFlag,
@@ -81,6 +87,10 @@ var (
 		utils.BlobPoolDataDirFlag,
 		utils.BlobPoolDataCapFlag,
 		utils.BlobPoolPriceBumpFlag,
+		utils.BlobPoolTipBumpFlag,
+		utils.BlobPoolFeeCapBumpFlag,
+		utils.BlobPoolMinBumpFlag,
+		utils.BlobPoolRecipientBumpFlag,
 		utils.SyncModeFlag,
 		utils.SyncTargetFlag,
 		utils.ExitWhenSyncedFlag,
@@ -88,6 +98,7 @@ var (
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag, // deprecated
 		utils.TransactionHistoryFlag,
+		utils.TraceIndexHistoryFlag,
 		utils.StateHistoryFlag,
 		utils.LightServeFlag,    // deprecated
 		utils.LightIngressFlag,  // deprecated
@@ -121,6 +132,8 @@ var (
 		utils.MinerEtherbaseFlag, // deprecated
 		utils.MinerExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
+		utils.MinerTxOrderingFlag,
+		utils.MinerPrioritySendersFlag,
 		utils.MinerPendingFeeRecipientFlag,
 		utils.MinerNewPayloadTimeoutFlag, // deprecated
 		utils.NATFlag,
@@ -138,7 +151,10 @@ var (
 		utils.VMEnableDebugFlag,
 		utils.VMTraceFlag,
 		utils.VMTraceJsonConfigFlag,
+		utils.TraceIndexFlag,
 		utils.NetworkIdFlag,
+		utils.MeerNetworkFlag,
+		utils.MeerNetworksFlag,
 		utils.EthStatsURLFlag,
 		utils.NoCompactionFlag,
 		utils.GpoBlocksFlag,
@@ -167,6 +183,8 @@ var (
 		utils.AuthPortFlag,
 		utils.AuthVirtualHostsFlag,
 		utils.JWTSecretFlag,
+		utils.QngEngineFlag,
+		utils.QngPersistInvalidFlag,
 		utils.HTTPVirtualHostsFlag,
 		utils.GraphQLEnabledFlag,
 		utils.GraphQLCORSDomainFlag,
@@ -243,6 +261,8 @@ func init() {
 		utils.ShowDeprecated,
 		// See snapshot.go
 		snapshotCommand,
+		// See txpoolcmd_qng.go
+		txpoolCommand,
 		// See verkle.go
 		verkleCommand,
 	}
@@ -267,7 +287,7 @@ func init() {
 			return err
 		}
 		flags.CheckEnvVars(ctx, app.Flags, "GETH")
-		return nil
+		return utils.LoadMeerNetworks(ctx)
 	}
 	app.After = func(ctx *cli.Context) error {
 		debug.Exit()
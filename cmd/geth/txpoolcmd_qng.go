@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	txpoolCommand = &cli.Command{
+		Name:  "txpool",
+		Usage: "Export and import the transaction pool of a running node",
+		Subcommands: []*cli.Command{
+			{
+				Action:    exportTxPool,
+				Name:      "export",
+				Usage:     "Export the transaction pool contents into a file",
+				ArgsUsage: "<filename> [endpoint]",
+				Flags:     []cli.Flag{utils.DataDirFlag, utils.HttpHeaderFlag},
+				Description: `
+Exports the pending and queued transactions of a running node into a file, in a
+streaming RLP format recording the sender and first seen time of every
+transaction. If the file ends with .gz, the output is gzipped. The file is
+written by the node, so it's resolved on the node's filesystem.`,
+			},
+			{
+				Action:    importTxPool,
+				Name:      "import",
+				Usage:     "Import a transaction pool export into a running node",
+				ArgsUsage: "<filename> [endpoint]",
+				Flags:     []cli.Flag{utils.DataDirFlag, utils.HttpHeaderFlag},
+				Description: `
+Replays the transactions of an export file through the transaction pool
+validation of a running node. If the file ends with .gz, the input is assumed to
+be gzipped. The file is read by the node, so it's resolved on the node's
+filesystem.`,
+			},
+		},
+	}
+)
+
+// dialTxPoolNode parses the file and optional endpoint arguments of the txpool
+// commands, connecting to the node.
+func dialTxPoolNode(ctx *cli.Context) (*rpc.Client, string) {
+	if ctx.Args().Len() < 1 || ctx.Args().Len() > 2 {
+		utils.Fatalf("This command requires a file name and an optional endpoint.")
+	}
+	file, err := filepath.Abs(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Invalid file name: %v", err)
+	}
+	endpoint := ctx.Args().Get(1)
+	if endpoint == "" {
+		cfg := defaultNodeConfig()
+		utils.SetDataDir(ctx, &cfg)
+		endpoint = cfg.IPCEndpoint()
+	}
+	client, err := utils.DialRPCWithHeaders(endpoint, ctx.StringSlice(utils.HttpHeaderFlag.Name))
+	if err != nil {
+		utils.Fatalf("Unable to attach to remote geth: %v", err)
+	}
+	return client, file
+}
+
+func exportTxPool(ctx *cli.Context) error {
+	client, file := dialTxPoolNode(ctx)
+	defer client.Close()
+
+	var count int
+	if err := client.Call(&count, "admin_exportTxPool", file); err != nil {
+		utils.Fatalf("Export error: %v", err)
+	}
+	fmt.Printf("Exported %d transactions to %s\n", count, file)
+	return nil
+}
+
+func importTxPool(ctx *cli.Context) error {
+	client, file := dialTxPoolNode(ctx)
+	defer client.Close()
+
+	var result eth.TxPoolImportResult
+	if err := client.Call(&result, "admin_importTxPool", file); err != nil {
+		utils.Fatalf("Import error: %v", err)
+	}
+	fmt.Printf("Imported %d transactions from %s, %d rejected\n", result.Total-result.Dropped, file, result.Dropped)
+	return nil
+}
@@ -42,6 +42,8 @@ import (
 	"github.com/ethereum/go-ethereum/common/fdlimit"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -137,6 +139,16 @@ var (
 		Value:    ethconfig.Defaults.NetworkId,
 		Category: flags.EthCategory,
 	}
+	MeerNetworkFlag = &cli.StringFlag{
+		Name:     "meer.network",
+		Usage:    "Qitmeer network to join, by name (e.g. qng, amana-test); the datadir must be initialized with its genesis",
+		Category: flags.EthCategory,
+	}
+	MeerNetworksFlag = &cli.StringFlag{
+		Name:     "meer.networks",
+		Usage:    "Path to a JSON file with additional or overridden Qitmeer network definitions",
+		Category: flags.EthCategory,
+	}
 	MainnetFlag = &cli.BoolFlag{
 		Name:     "mainnet",
 		Usage:    "Ethereum mainnet",
@@ -283,6 +295,12 @@ var (
 		Value:    ethconfig.Defaults.TransactionHistory,
 		Category: flags.StateCategory,
 	}
+	TraceIndexHistoryFlag = &cli.Uint64Flag{
+		Name:     "history.traces",
+		Usage:    "Number of recent blocks to maintain the trace index for, if enabled (default = 90,000 blocks, 0 = entire chain)",
+		Value:    ethconfig.Defaults.TraceIndexHistory,
+		Category: flags.StateCategory,
+	}
 	// Beacon client light sync settings
 	BeaconApiFlag = &cli.StringSliceFlag{
 		Name:     "beacon.api",
@@ -358,6 +376,18 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Rejournal,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolSnapshotFlag = &cli.StringFlag{
+		Name:     "txpool.snapshot",
+		Usage:    "Disk snapshot of local and remote transactions to survive node restarts, blob transactions excluded (disabled if empty)",
+		Value:    ethconfig.Defaults.TxPoolSnapshot.Path,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolSnapshotLimitFlag = &cli.Uint64Flag{
+		Name:     "txpool.snapshotlimit",
+		Usage:    "Disk space in bytes the transaction pool snapshot may grow to before being compacted",
+		Value:    ethconfig.Defaults.TxPoolSnapshot.Limit,
+		Category: flags.TxPoolCategory,
+	}
 	TxPoolPriceLimitFlag = &cli.Uint64Flag{
 		Name:     "txpool.pricelimit",
 		Usage:    "Minimum gas price tip to enforce for acceptance into the pool",
@@ -370,6 +400,26 @@ var (
 		Value:    ethconfig.Defaults.TxPool.PriceBump,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolTipBumpFlag = &cli.Uint64Flag{
+		Name:     "txpool.tipbump",
+		Usage:    "Gas tip cap bump percentage to replace an already existing transaction (default = txpool.pricebump)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolFeeCapBumpFlag = &cli.Uint64Flag{
+		Name:     "txpool.feecapbump",
+		Usage:    "Gas fee cap bump percentage to replace an already existing transaction (default = txpool.pricebump)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolMinBumpFlag = &flags.BigFlag{
+		Name:     "txpool.minbump",
+		Usage:    "Minimum absolute fee bump in wei to replace an already existing transaction",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolRecipientBumpFlag = &cli.Uint64Flag{
+		Name:     "txpool.recipientbump",
+		Usage:    "Fee bump percentage to replace an already existing transaction with a different recipient (0 = regular bumps)",
+		Category: flags.TxPoolCategory,
+	}
 	TxPoolAccountSlotsFlag = &cli.Uint64Flag{
 		Name:     "txpool.accountslots",
 		Usage:    "Minimum number of executable transaction slots guaranteed per account",
@@ -419,6 +469,26 @@ var (
 		Value:    ethconfig.Defaults.BlobPool.PriceBump,
 		Category: flags.BlobPoolCategory,
 	}
+	BlobPoolTipBumpFlag = &cli.Uint64Flag{
+		Name:     "blobpool.tipbump",
+		Usage:    "Gas tip cap bump percentage to replace an already existing blob transaction (default = blobpool.pricebump)",
+		Category: flags.BlobPoolCategory,
+	}
+	BlobPoolFeeCapBumpFlag = &cli.Uint64Flag{
+		Name:     "blobpool.feecapbump",
+		Usage:    "Gas and blob fee cap bump percentage to replace an already existing blob transaction (default = blobpool.pricebump)",
+		Category: flags.BlobPoolCategory,
+	}
+	BlobPoolMinBumpFlag = &flags.BigFlag{
+		Name:     "blobpool.minbump",
+		Usage:    "Minimum absolute fee bump in wei to replace an already existing blob transaction",
+		Category: flags.BlobPoolCategory,
+	}
+	BlobPoolRecipientBumpFlag = &cli.Uint64Flag{
+		Name:     "blobpool.recipientbump",
+		Usage:    "Fee bump percentage to replace an already existing blob transaction with a different recipient (0 = regular bumps)",
+		Category: flags.BlobPoolCategory,
+	}
 	// Performance tuning settings
 	CacheFlag = &cli.IntFlag{
 		Name:     "cache",
@@ -502,6 +572,17 @@ var (
 		Value:    ethconfig.Defaults.Miner.Recommit,
 		Category: flags.MinerCategory,
 	}
+	MinerTxOrderingFlag = &cli.StringFlag{
+		Name:     "miner.txordering",
+		Usage:    "Ordering of the pending transactions in mined blocks (price, fifo or priority)",
+		Value:    "price",
+		Category: flags.MinerCategory,
+	}
+	MinerPrioritySendersFlag = &cli.StringFlag{
+		Name:     "miner.prioritysenders",
+		Usage:    "Comma separated accounts whose transactions are included first by the priority ordering",
+		Category: flags.MinerCategory,
+	}
 	MinerPendingFeeRecipientFlag = &cli.StringFlag{
 		Name:     "miner.pending.feeRecipient",
 		Usage:    "0x prefixed public address for the pending block producer (not used for actual block production)",
@@ -549,6 +630,11 @@ var (
 		Usage:    "Tracer configuration (JSON)",
 		Category: flags.VMCategory,
 	}
+	TraceIndexFlag = &cli.BoolFlag{
+		Name:     "traceindex",
+		Usage:    "Maintain a persistent index of the internal calls and value transfers of recent blocks",
+		Category: flags.VMCategory,
+	}
 	// API options.
 	RPCGlobalGasCapFlag = &cli.Uint64Flag{
 		Name:     "rpc.gascap",
@@ -592,6 +678,16 @@ var (
 		Usage:    "Path to a JWT secret to use for authenticated RPC endpoints",
 		Category: flags.APICategory,
 	}
+	QngEngineFlag = &cli.BoolFlag{
+		Name:     "authrpc.qngengine",
+		Usage:    "Enable the QNG engine API (qngengine namespace) on the authenticated RPC endpoint (default on Qitmeer networks driven by it)",
+		Category: flags.APICategory,
+	}
+	QngPersistInvalidFlag = &cli.BoolFlag{
+		Name:     "authrpc.qngengine.persistinvalid",
+		Usage:    "Persist the payloads rejected by the QNG engine API across restarts",
+		Category: flags.APICategory,
+	}
 
 	// Logging and debug settings
 	EthStatsURLFlag = &cli.StringFlag{
@@ -1047,6 +1143,8 @@ func setBootstrapNodes(ctx *cli.Context, cfg *p2p.Config) {
 			return // Already set by config file, don't apply defaults.
 		}
 		switch {
+		case ctx.IsSet(MeerNetworkFlag.Name):
+			urls = MakeMeerNetwork(ctx).Bootnodes
 		case ctx.Bool(HoleskyFlag.Name):
 			urls = params.HoleskyBootnodes
 		case ctx.Bool(SepoliaFlag.Name):
@@ -1058,6 +1156,38 @@ func setBootstrapNodes(ctx *cli.Context, cfg *p2p.Config) {
 	cfg.BootstrapNodes = mustParseBootnodes(urls)
 }
 
+// LoadMeerNetworks loads any extra Qitmeer network definitions into the
+// registry and checks that the network selected on the command line is known.
+// It must run once before the configs are assembled from the flags.
+func LoadMeerNetworks(ctx *cli.Context) error {
+	if ctx.IsSet(MeerNetworksFlag.Name) {
+		data, err := os.ReadFile(ctx.String(MeerNetworksFlag.Name))
+		if err != nil {
+			return fmt.Errorf("failed to read Qitmeer networks: %v", err)
+		}
+		if err := params.MeerNetworks.LoadJSON(data); err != nil {
+			return fmt.Errorf("failed to load Qitmeer networks: %v", err)
+		}
+	}
+	if ctx.IsSet(MeerNetworkFlag.Name) && params.MeerNetworks.GetByName(ctx.String(MeerNetworkFlag.Name)) == nil {
+		return fmt.Errorf("unknown Qitmeer network: %s", ctx.String(MeerNetworkFlag.Name))
+	}
+	return nil
+}
+
+// MakeMeerNetwork returns the Qitmeer network selected on the command line, or
+// nil if none is. The extra network definitions must already be loaded.
+func MakeMeerNetwork(ctx *cli.Context) *params.MeerChainConfig {
+	if !ctx.IsSet(MeerNetworkFlag.Name) {
+		return nil
+	}
+	network := params.MeerNetworks.GetByName(ctx.String(MeerNetworkFlag.Name))
+	if network == nil {
+		Fatalf("Unknown Qitmeer network: %s", ctx.String(MeerNetworkFlag.Name))
+	}
+	return network
+}
+
 func mustParseBootnodes(urls []string) []*enode.Node {
 	nodes := make([]*enode.Node, 0, len(urls))
 	for _, url := range urls {
@@ -1528,6 +1658,18 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolPriceBumpFlag.Name) {
 		cfg.PriceBump = ctx.Uint64(TxPoolPriceBumpFlag.Name)
 	}
+	if ctx.IsSet(TxPoolTipBumpFlag.Name) {
+		cfg.Replacement.TipBump = ctx.Uint64(TxPoolTipBumpFlag.Name)
+	}
+	if ctx.IsSet(TxPoolFeeCapBumpFlag.Name) {
+		cfg.Replacement.FeeCapBump = ctx.Uint64(TxPoolFeeCapBumpFlag.Name)
+	}
+	if ctx.IsSet(TxPoolMinBumpFlag.Name) {
+		cfg.Replacement.MinBump = flags.GlobalBig(ctx, TxPoolMinBumpFlag.Name)
+	}
+	if ctx.IsSet(TxPoolRecipientBumpFlag.Name) {
+		cfg.Replacement.RecipientBump = ctx.Uint64(TxPoolRecipientBumpFlag.Name)
+	}
 	if ctx.IsSet(TxPoolAccountSlotsFlag.Name) {
 		cfg.AccountSlots = ctx.Uint64(TxPoolAccountSlotsFlag.Name)
 	}
@@ -1545,6 +1687,39 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	}
 }
 
+func setBlobPool(ctx *cli.Context, cfg *blobpool.Config) {
+	if ctx.IsSet(BlobPoolDataDirFlag.Name) {
+		cfg.Datadir = ctx.String(BlobPoolDataDirFlag.Name)
+	}
+	if ctx.IsSet(BlobPoolDataCapFlag.Name) {
+		cfg.Datacap = ctx.Uint64(BlobPoolDataCapFlag.Name)
+	}
+	if ctx.IsSet(BlobPoolPriceBumpFlag.Name) {
+		cfg.PriceBump = ctx.Uint64(BlobPoolPriceBumpFlag.Name)
+	}
+	if ctx.IsSet(BlobPoolTipBumpFlag.Name) {
+		cfg.Replacement.TipBump = ctx.Uint64(BlobPoolTipBumpFlag.Name)
+	}
+	if ctx.IsSet(BlobPoolFeeCapBumpFlag.Name) {
+		cfg.Replacement.FeeCapBump = ctx.Uint64(BlobPoolFeeCapBumpFlag.Name)
+	}
+	if ctx.IsSet(BlobPoolMinBumpFlag.Name) {
+		cfg.Replacement.MinBump = flags.GlobalBig(ctx, BlobPoolMinBumpFlag.Name)
+	}
+	if ctx.IsSet(BlobPoolRecipientBumpFlag.Name) {
+		cfg.Replacement.RecipientBump = ctx.Uint64(BlobPoolRecipientBumpFlag.Name)
+	}
+}
+
+func setTxPoolSnapshot(ctx *cli.Context, cfg *txpool.SnapshotConfig) {
+	if ctx.IsSet(TxPoolSnapshotFlag.Name) {
+		cfg.Path = ctx.String(TxPoolSnapshotFlag.Name)
+	}
+	if ctx.IsSet(TxPoolSnapshotLimitFlag.Name) {
+		cfg.Limit = ctx.Uint64(TxPoolSnapshotLimitFlag.Name)
+	}
+}
+
 func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.Bool(MiningEnabledFlag.Name) {
 		log.Warn("The flag --mine is deprecated and will be removed")
@@ -1565,6 +1740,21 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 		log.Warn("The flag --miner.newpayload-timeout is deprecated and will be removed, please use --miner.recommit")
 		cfg.Recommit = ctx.Duration(MinerNewPayloadTimeoutFlag.Name)
 	}
+	if ctx.IsSet(MinerPrioritySendersFlag.Name) {
+		for _, account := range strings.Split(ctx.String(MinerPrioritySendersFlag.Name), ",") {
+			if trimmed := strings.TrimSpace(account); !common.IsHexAddress(trimmed) {
+				Fatalf("Invalid account in --miner.prioritysenders: %s", trimmed)
+			} else {
+				cfg.PrioritySenders = append(cfg.PrioritySenders, common.HexToAddress(trimmed))
+			}
+		}
+	}
+	if ctx.IsSet(MinerTxOrderingFlag.Name) {
+		cfg.TxOrdering = ctx.String(MinerTxOrderingFlag.Name)
+	}
+	if _, err := miner.NewTxOrderer(cfg.TxOrdering, cfg.PrioritySenders); err != nil {
+		Fatalf("Invalid --miner.txordering: %v", err)
+	}
 }
 
 func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1646,6 +1836,8 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setEtherbase(ctx, cfg)
 	setGPO(ctx, &cfg.GPO)
 	setTxPool(ctx, &cfg.TxPool)
+	setBlobPool(ctx, &cfg.BlobPool)
+	setTxPoolSnapshot(ctx, &cfg.TxPoolSnapshot)
 	setMiner(ctx, &cfg.Miner)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
@@ -1677,6 +1869,8 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	}
 	if ctx.IsSet(NetworkIdFlag.Name) {
 		cfg.NetworkId = ctx.Uint64(NetworkIdFlag.Name)
+	} else if ctx.IsSet(MeerNetworkFlag.Name) {
+		cfg.NetworkId = MakeMeerNetwork(ctx).ChainID.Uint64()
 	}
 	if ctx.IsSet(CacheFlag.Name) || ctx.IsSet(CacheDatabaseFlag.Name) {
 		cfg.DatabaseCache = ctx.Int(CacheFlag.Name) * ctx.Int(CacheDatabaseFlag.Name) / 100
@@ -1775,6 +1969,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.Float64(RPCGlobalTxFeeCapFlag.Name)
 	}
+	if ctx.IsSet(QngPersistInvalidFlag.Name) {
+		cfg.QngPersistInvalidPayloads = ctx.Bool(QngPersistInvalidFlag.Name)
+	}
 	if ctx.IsSet(NoDiscoverFlag.Name) {
 		cfg.EthDiscoveryURLs, cfg.SnapDiscoveryURLs = []string{}, []string{}
 	} else if ctx.IsSet(DNSDiscoveryFlag.Name) {
@@ -1888,6 +2085,17 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		if !ctx.IsSet(MinerGasPriceFlag.Name) {
 			cfg.Miner.GasPrice = big.NewInt(1)
 		}
+	case ctx.IsSet(MeerNetworkFlag.Name):
+		// Qitmeer networks ship no genesis spec, so the chain must have been
+		// initialized with one instead of falling back to the mainnet genesis.
+		if cfg.Genesis == nil {
+			chaindb := tryMakeReadOnlyDatabase(ctx, stack)
+			fresh := rawdb.ReadCanonicalHash(chaindb, 0) == (common.Hash{})
+			chaindb.Close()
+			if fresh {
+				Fatalf("Qitmeer network %s has no built-in genesis, initialize the datadir with 'geth init' first", ctx.String(MeerNetworkFlag.Name))
+			}
+		}
 	default:
 		if cfg.NetworkId == 1 {
 			SetDNSDiscoveryDefaults(cfg, params.MainnetGenesisHash)
@@ -1913,6 +2121,13 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 			cfg.VMTraceJsonConfig = config
 		}
 	}
+	// Trace index config.
+	if ctx.IsSet(TraceIndexFlag.Name) {
+		cfg.TraceIndex = ctx.Bool(TraceIndexFlag.Name)
+	}
+	if ctx.IsSet(TraceIndexHistoryFlag.Name) {
+		cfg.TraceIndexHistory = ctx.Uint64(TraceIndexHistoryFlag.Name)
+	}
 }
 
 // SetDNSDiscoveryDefaults configures DNS discovery with the given URL if
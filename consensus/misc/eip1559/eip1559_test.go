@@ -129,3 +129,62 @@ func TestCalcBaseFee(t *testing.T) {
 		}
 	}
 }
+
+// TestMeerFeePolicy tests that the fee policy of Qitmeer networks is honored
+// from its activation block onwards.
+func TestMeerFeePolicy(t *testing.T) {
+	config := config()
+	config.SetMeerFeePolicies([]*params.MeerFeePolicy{
+		{Block: 100, GasLimit: 30000000, ElasticityMultiplier: 4, BaseFeeChangeDenominator: 16},
+	})
+
+	// The base fee must follow the policy of the block being built
+	for i, tc := range []struct {
+		pNum    int64
+		baseFee int64
+	}{
+		{98, 962500000},  // Default policy: target 10M, denominator 8
+		{99, 1025000000}, // Network policy: target 5M, denominator 16
+	} {
+		parent := &types.Header{
+			Number:   big.NewInt(tc.pNum),
+			GasLimit: 20000000,
+			GasUsed:  7000000,
+			BaseFee:  big.NewInt(params.InitialBaseFee),
+		}
+		if have, want := CalcBaseFee(config, parent), big.NewInt(tc.baseFee); have.Cmp(want) != 0 {
+			t.Errorf("test %d: base fee mismatch: have %d, want %d", i, have, want)
+		}
+	}
+	// The gas limit may only jump to the new target at the activation block
+	for i, tc := range []struct {
+		pNum     int64
+		gasLimit uint64
+		ok       bool
+	}{
+		{98, 30000000, false}, // Before activation
+		{99, 30000000, true},  // Activation block
+		{99, 29000000, false}, // Activation block, not the target
+		{99, 20019530, true},  // Activation block, regular adjustment
+		{100, 40000000, false},
+	} {
+		parent := &types.Header{
+			Number:   big.NewInt(tc.pNum),
+			GasLimit: 20000000,
+			GasUsed:  10000000,
+			BaseFee:  big.NewInt(params.InitialBaseFee),
+		}
+		header := &types.Header{
+			Number:   big.NewInt(tc.pNum + 1),
+			GasLimit: tc.gasLimit,
+			BaseFee:  CalcBaseFee(config, parent),
+		}
+		err := VerifyEIP1559Header(config, parent, header)
+		if tc.ok && err != nil {
+			t.Errorf("test %d: expected valid header: %v", i, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("test %d: expected invalid header", i)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// InsertChainWithoutSetHead executes a contiguous batch of blocks through the
+// regular import pipeline, without setting any of them as the new head. It's
+// the batch counterpart of InsertBlockWithoutSetHead, used by the QNG engine
+// API to catch up on a backlog of payloads. The index of the first block that
+// failed to import is returned along with the error.
+func (bc *BlockChain) InsertChainWithoutSetHead(chain types.Blocks) (int, error) {
+	if len(chain) == 0 {
+		return 0, nil
+	}
+	for i := 1; i < len(chain); i++ {
+		block, prev := chain[i], chain[i-1]
+		if block.NumberU64() != prev.NumberU64()+1 || block.ParentHash() != prev.Hash() {
+			return 0, fmt.Errorf("non contiguous insert: item %d is #%d [%x..], item %d is #%d [%x..] (parent [%x..])", i-1, prev.NumberU64(),
+				prev.Hash().Bytes()[:4], i, block.NumberU64(), block.Hash().Bytes()[:4], block.ParentHash().Bytes()[:4])
+		}
+	}
+	if !bc.chainmu.TryLock() {
+		return 0, errChainStopped
+	}
+	defer bc.chainmu.Unlock()
+
+	return bc.insertChain(chain, false)
+}
@@ -0,0 +1,227 @@
+package rawdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// InvalidPayload is a payload rejected by the QNG engine API, as recorded on disk.
+type InvalidPayload struct {
+	Header *types.Header
+	Reason string
+	Time   uint64 // Unix time of the first rejection
+}
+
+// invalidPayloadKey = qngInvalidPrefix + hash
+func invalidPayloadKey(hash common.Hash) []byte {
+	return append(append([]byte{}, qngInvalidPrefix...), hash.Bytes()...)
+}
+
+// ReadInvalidPayloads retrieves all the invalid QNG payloads in the database.
+func ReadInvalidPayloads(db ethdb.Iteratee) []*InvalidPayload {
+	it := db.NewIterator(qngInvalidPrefix, nil)
+	defer it.Release()
+
+	var payloads []*InvalidPayload
+	for it.Next() {
+		if len(it.Key()) != len(qngInvalidPrefix)+common.HashLength {
+			continue
+		}
+		payload := new(InvalidPayload)
+		if err := rlp.DecodeBytes(it.Value(), payload); err != nil {
+			log.Warn("Failed to decode invalid payload", "err", err)
+			continue
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads
+}
+
+// WriteInvalidPayload stores an invalid QNG payload into the database.
+func WriteInvalidPayload(db ethdb.KeyValueWriter, payload *InvalidPayload) {
+	data, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		log.Crit("Failed to encode invalid payload", "err", err)
+	}
+	if err := db.Put(invalidPayloadKey(payload.Header.Hash()), data); err != nil {
+		log.Crit("Failed to write invalid payload", "err", err)
+	}
+}
+
+// DeleteInvalidPayload deletes an invalid QNG payload from the database.
+func DeleteInvalidPayload(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(invalidPayloadKey(hash)); err != nil {
+		log.Crit("Failed to delete invalid payload", "err", err)
+	}
+}
+
+// TraceRecord is a compact call frame of a transaction, as stored in the trace
+// index. Frames reverted by themselves or by any of their ancestors are flagged.
+type TraceRecord struct {
+	TxIndex  uint64         // Index of the transaction within the block
+	Depth    uint64         // Call depth of the frame, 0 being the transaction itself
+	Type     byte           // Opcode that created the frame (CALL, CREATE, SELFDESTRUCT, ...)
+	From     common.Address // Caller of the frame
+	To       common.Address // Callee, created contract or selfdestruct beneficiary
+	Value    *big.Int       // Value transferred along the frame
+	Reverted bool           // Whether the frame was rolled back
+}
+
+// traceIndexBlock is the RLP representation of a block in the trace freezer.
+type traceIndexBlock struct {
+	Hash    common.Hash
+	Records []*TraceRecord
+}
+
+// traceIndexKey = qngTraceIndexPrefix + address + num (uint64 big endian)
+func traceIndexKey(addr common.Address, number uint64) []byte {
+	key := make([]byte, len(qngTraceIndexPrefix)+common.AddressLength+8)
+	copy(key, qngTraceIndexPrefix)
+	copy(key[len(qngTraceIndexPrefix):], addr.Bytes())
+	binary.BigEndian.PutUint64(key[len(qngTraceIndexPrefix)+common.AddressLength:], number)
+	return key
+}
+
+// traceRecordAddresses returns the distinct addresses involved in the records.
+func traceRecordAddresses(records []*TraceRecord) []common.Address {
+	var (
+		addrs []common.Address
+		seen  = make(map[common.Address]struct{})
+	)
+	for _, record := range records {
+		for _, addr := range []common.Address{record.From, record.To} {
+			if _, ok := seen[addr]; !ok {
+				seen[addr] = struct{}{}
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	return addrs
+}
+
+// ReadTraceIndexOffset retrieves the number of the block stored as the first
+// item of the trace freezer. Nil is returned if the trace index was never used.
+func ReadTraceIndexOffset(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(qngTraceIndexOffsetKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteTraceIndexOffset stores the number of the block stored as the first
+// item of the trace freezer.
+func WriteTraceIndexOffset(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(qngTraceIndexOffsetKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store trace index offset", "err", err)
+	}
+}
+
+// ReadTraceIndexBlock retrieves the hash and the call frames of the block
+// stored as the given item of the trace freezer.
+func ReadTraceIndexBlock(db ethdb.AncientReaderOp, item uint64) (common.Hash, []*TraceRecord, error) {
+	blob, err := db.Ancient(TraceFreezerBlockTable, item)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	var block traceIndexBlock
+	if err := rlp.DecodeBytes(blob, &block); err != nil {
+		return common.Hash{}, nil, err
+	}
+	return block.Hash, block.Records, nil
+}
+
+// WriteTraceIndexBlock appends the call frames of a block to the trace freezer
+// as the given item.
+func WriteTraceIndexBlock(op ethdb.AncientWriteOp, item uint64, hash common.Hash, records []*TraceRecord) error {
+	return op.Append(TraceFreezerBlockTable, item, &traceIndexBlock{Hash: hash, Records: records})
+}
+
+// WriteTraceIndexEntries stores an address index entry for the given block
+// number for every address involved in the records.
+func WriteTraceIndexEntries(db ethdb.KeyValueWriter, number uint64, records []*TraceRecord) {
+	for _, addr := range traceRecordAddresses(records) {
+		if err := db.Put(traceIndexKey(addr, number), []byte{}); err != nil {
+			log.Crit("Failed to store trace index entry", "err", err)
+		}
+	}
+}
+
+// DeleteTraceIndexEntries removes the address index entries of the given block
+// number for every address involved in the records.
+func DeleteTraceIndexEntries(db ethdb.KeyValueWriter, number uint64, records []*TraceRecord) {
+	for _, addr := range traceRecordAddresses(records) {
+		if err := db.Delete(traceIndexKey(addr, number)); err != nil {
+			log.Crit("Failed to delete trace index entry", "err", err)
+		}
+	}
+}
+
+// ReadTraceIndexNumbers retrieves the numbers of the blocks within [from, to]
+// in which the given address was involved in a call frame, in ascending order.
+func ReadTraceIndexNumbers(db ethdb.Iteratee, addr common.Address, from, to uint64) []uint64 {
+	prefix := append(append([]byte{}, qngTraceIndexPrefix...), addr.Bytes()...)
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	var (
+		numbers []uint64
+		limit   = traceIndexKey(addr, to)
+	)
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(limit) || bytes.Compare(key, limit) > 0 {
+			break
+		}
+		numbers = append(numbers, binary.BigEndian.Uint64(key[len(prefix):]))
+	}
+	return numbers
+}
+
+// traceGapKey = qngTraceGapPrefix + num (uint64 big endian)
+func traceGapKey(number uint64) []byte {
+	return append(append([]byte{}, qngTraceGapPrefix...), encodeBlockNumber(number)...)
+}
+
+// WriteTraceIndexGap flags the given block as indexed without its call frames,
+// as it couldn't be traced.
+func WriteTraceIndexGap(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(traceGapKey(number), []byte{}); err != nil {
+		log.Crit("Failed to store trace index gap", "err", err)
+	}
+}
+
+// DeleteTraceIndexGap removes the gap flag of the given block, if any.
+func DeleteTraceIndexGap(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Delete(traceGapKey(number)); err != nil {
+		log.Crit("Failed to delete trace index gap", "err", err)
+	}
+}
+
+// ReadTraceIndexGaps retrieves the numbers of the blocks within [from, to]
+// indexed without their call frames, in ascending order.
+func ReadTraceIndexGaps(db ethdb.Iteratee, from, to uint64) []uint64 {
+	it := db.NewIterator(qngTraceGapPrefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	var (
+		numbers []uint64
+		limit   = traceGapKey(to)
+	)
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(limit) || bytes.Compare(key, limit) > 0 {
+			break
+		}
+		numbers = append(numbers, binary.BigEndian.Uint64(key[len(qngTraceGapPrefix):]))
+	}
+	return numbers
+}
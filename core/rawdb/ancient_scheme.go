@@ -77,7 +77,7 @@ var (
 )
 
 // freezers the collections of all builtin freezers.
-var freezers = []string{ChainFreezerName, StateFreezerName}
+var freezers = []string{ChainFreezerName, StateFreezerName, TraceFreezerName}
 
 // NewStateFreezer initializes the ancient store for state history.
 //
@@ -0,0 +1,36 @@
+package rawdb
+
+import (
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+const (
+	// traceIndexTableSize defines the maximum size of trace index data files.
+	traceIndexTableSize = 2 * 1000 * 1000 * 1000
+
+	// TraceFreezerBlockTable indicates the name of the freezer table holding
+	// the call frames recorded for each indexed block.
+	TraceFreezerBlockTable = "blocks"
+)
+
+var traceFreezerNoSnappy = map[string]bool{
+	TraceFreezerBlockTable: false,
+}
+
+// TraceFreezerName is the folder name of the trace index ancient store.
+var TraceFreezerName = "trace"
+
+// NewTraceFreezer initializes the ancient store for the QNG trace index.
+//
+//   - if the empty directory is given, initializes the pure in-memory
+//     trace freezer (e.g. dev mode).
+//   - if non-empty directory is given, initializes the regular file-based
+//     trace freezer.
+func NewTraceFreezer(ancientDir string, readOnly bool) (ethdb.ResettableAncientStore, error) {
+	if ancientDir == "" {
+		return NewMemoryFreezer(readOnly, traceFreezerNoSnappy), nil
+	}
+	return newResettableFreezer(filepath.Join(ancientDir, TraceFreezerName), "eth/db/trace", readOnly, traceIndexTableSize, traceFreezerNoSnappy)
+}
@@ -474,6 +474,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		storageTries    stat
 		codes           stat
 		txLookups       stat
+		traceIndex      stat
 		accountSnaps    stat
 		storageSnaps    stat
 		preimages       stat
@@ -524,6 +525,12 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			codes.Add(size)
 		case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
 			txLookups.Add(size)
+		case bytes.HasPrefix(key, qngTraceIndexPrefix) && len(key) == (len(qngTraceIndexPrefix)+common.AddressLength+8):
+			traceIndex.Add(size)
+		case bytes.HasPrefix(key, qngTraceGapPrefix) && len(key) == (len(qngTraceGapPrefix)+8):
+			traceIndex.Add(size)
+		case bytes.HasPrefix(key, qngInvalidPrefix) && len(key) == (len(qngInvalidPrefix)+common.HashLength):
+			metadata.Add(size)
 		case bytes.HasPrefix(key, SnapshotAccountPrefix) && len(key) == (len(SnapshotAccountPrefix)+common.HashLength):
 			accountSnaps.Add(size)
 		case bytes.HasPrefix(key, SnapshotStoragePrefix) && len(key) == (len(SnapshotStoragePrefix)+2*common.HashLength):
@@ -558,6 +565,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
 				uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
 				persistentStateIDKey, trieJournalKey, snapshotSyncStatusKey, snapSyncStatusFlagKey,
+				qngTraceIndexOffsetKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
@@ -584,6 +592,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Block number->hash", numHashPairings.Size(), numHashPairings.Count()},
 		{"Key-Value store", "Block hash->number", hashNumPairings.Size(), hashNumPairings.Count()},
 		{"Key-Value store", "Transaction index", txLookups.Size(), txLookups.Count()},
+		{"Key-Value store", "Trace index", traceIndex.Size(), traceIndex.Count()},
 		{"Key-Value store", "Bloombit index", bloomBits.Size(), bloomBits.Count()},
 		{"Key-Value store", "Contract codes", codes.Size(), codes.Count()},
 		{"Key-Value store", "Hash trie nodes", legacyTries.Size(), legacyTries.Count()},
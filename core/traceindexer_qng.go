@@ -0,0 +1,591 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// traceIndexCacheLimit is the number of imported blocks whose call frames are
+// kept in memory until they become canonical and get indexed.
+const traceIndexCacheLimit = 1024
+
+// ErrTraceRangeNotIndexed is returned if the requested block range is not
+// fully covered by the trace index.
+var ErrTraceRangeNotIndexed = errors.New("block range not indexed")
+
+// IndexedTrace is a call frame retrieved from the trace index, along with the
+// block it belongs to.
+type IndexedTrace struct {
+	BlockNumber uint64
+	BlockHash   common.Hash
+	*rawdb.TraceRecord
+}
+
+// TraceIndexer records the call frames of the imported blocks through the live
+// tracing hooks, and maintains a persistent per-address index of them for the
+// most recent canonical blocks. Blocks imported without being executed (known
+// blocks, or blocks imported while the indexer was disabled) are re-executed
+// when indexed, as long as their parent state is still available.
+type TraceIndexer struct {
+	limit   uint64                                        // Number of recent blocks to keep indexed, 0 for the entire chain
+	db      ethdb.Database                                // Key-value store holding the address index, in memory along with the freezer
+	freezer ethdb.ResettableAncientStore                  // Ancient store holding the call frames of the indexed blocks
+	pending *lru.Cache[common.Hash, []*rawdb.TraceRecord] // Call frames recorded during import, not yet indexed
+
+	// Fields only accessed from the block import routine
+	live     *traceCollector
+	liveHash common.Hash
+
+	offset uint64       // Number of the block stored as the first freezer item
+	lock   sync.RWMutex // Lock protecting the indexed range
+
+	chain  *BlockChain
+	term   chan chan struct{}
+	closed chan struct{}
+}
+
+// NewTraceIndexer creates a trace indexer keeping the given number of recent
+// blocks indexed, 0 meaning the entire chain. The indexer only records blocks
+// until it is started.
+func NewTraceIndexer(db ethdb.Database, limit uint64) (*TraceIndexer, error) {
+	// Databases without a backing chain freezer keep the trace index in memory,
+	// the address index included to not leak entries across restarts
+	ancient, err := db.AncientDatadir()
+	if err != nil {
+		ancient = ""
+	}
+	if ancient == "" {
+		db = rawdb.NewMemoryDatabase()
+	}
+	freezer, err := rawdb.NewTraceFreezer(ancient, false)
+	if err != nil {
+		return nil, err
+	}
+	indexer := &TraceIndexer{
+		limit:   limit,
+		db:      db,
+		freezer: freezer,
+		pending: lru.NewCache[common.Hash, []*rawdb.TraceRecord](traceIndexCacheLimit),
+		term:    make(chan chan struct{}),
+		closed:  make(chan struct{}),
+	}
+	if offset := rawdb.ReadTraceIndexOffset(db); offset != nil {
+		indexer.offset = *offset
+	}
+	return indexer, nil
+}
+
+// Hooks returns the live tracing hooks recording the call frames of the blocks
+// imported into the chain.
+func (indexer *TraceIndexer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnBlockStart: func(event tracing.BlockEvent) {
+			indexer.live, indexer.liveHash = new(traceCollector), event.Block.Hash()
+		},
+		OnBlockEnd: func(err error) {
+			if indexer.live != nil && err == nil {
+				indexer.pending.Add(indexer.liveHash, indexer.live.records)
+			}
+			indexer.live = nil
+		},
+		OnSystemCallStart: func() {
+			if indexer.live != nil {
+				indexer.live.onSystemCallStart()
+			}
+		},
+		OnSystemCallEnd: func() {
+			if indexer.live != nil {
+				indexer.live.onSystemCallEnd()
+			}
+		},
+		OnTxStart: func(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+			if indexer.live != nil {
+				indexer.live.onTxStart(env, tx, from)
+			}
+		},
+		OnEnter: func(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+			if indexer.live != nil {
+				indexer.live.onEnter(depth, typ, from, to, input, gas, value)
+			}
+		},
+		OnExit: func(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+			if indexer.live != nil {
+				indexer.live.onExit(depth, output, gasUsed, err, reverted)
+			}
+		},
+	}
+}
+
+// Start launches the background indexing of the canonical chain.
+func (indexer *TraceIndexer) Start(chain *BlockChain) {
+	indexer.chain = chain
+	go indexer.loop()
+}
+
+// Close stops the background indexing and closes the trace freezer. Safe to
+// be called for multiple times.
+func (indexer *TraceIndexer) Close() error {
+	if indexer.chain == nil {
+		return indexer.freezer.Close()
+	}
+	ch := make(chan struct{})
+	select {
+	case indexer.term <- ch:
+		<-ch
+		return indexer.freezer.Close()
+	case <-indexer.closed:
+		return nil
+	}
+}
+
+// loop is the scheduler of the indexer, indexing the canonical chain up to the
+// announced heads.
+func (indexer *TraceIndexer) loop() {
+	defer close(indexer.closed)
+
+	var (
+		stop     chan struct{} // Non-nil if background routine is active.
+		done     chan struct{} // Non-nil if background routine is active.
+		lastHead uint64        // The latest announced chain head
+		rerun    bool          // Whether a head was announced while indexing
+
+		headCh = make(chan ChainHeadEvent)
+		sub    = indexer.chain.SubscribeChainHeadEvent(headCh)
+	)
+	defer sub.Unsubscribe()
+
+	run := func(head uint64) {
+		stop = make(chan struct{})
+		done = make(chan struct{})
+		go indexer.run(head, stop, done)
+	}
+	// Catch up with the current head, the chain might not progress
+	lastHead = indexer.chain.CurrentBlock().Number.Uint64()
+	run(lastHead)
+
+	for {
+		select {
+		case head := <-headCh:
+			lastHead = head.Block.NumberU64()
+			if done == nil {
+				run(lastHead)
+			} else {
+				rerun = true
+			}
+		case <-done:
+			stop, done = nil, nil
+			if rerun {
+				rerun = false
+				run(lastHead)
+			}
+		case ch := <-indexer.term:
+			if stop != nil {
+				close(stop)
+			}
+			if done != nil {
+				log.Info("Waiting background trace indexer to exit")
+				<-done
+			}
+			close(ch)
+			return
+		}
+	}
+}
+
+// run indexes the canonical chain up to the given head, in the background.
+func (indexer *TraceIndexer) run(head uint64, stop chan struct{}, done chan struct{}) {
+	defer close(done)
+
+	if err := indexer.index(head, stop); err != nil {
+		log.Error("Failed to index traces", "head", head, "err", err)
+	}
+}
+
+// index unwinds the indexed blocks which are no longer canonical, indexes the
+// blocks up to the given head and unindexes the ones beyond the retention limit.
+func (indexer *TraceIndexer) index(head uint64, stop chan struct{}) error {
+	from := uint64(0)
+	if indexer.limit != 0 && head >= indexer.limit {
+		from = head - indexer.limit + 1
+	}
+	items, tail, err := indexer.items()
+	if err != nil {
+		return err
+	}
+	// Unwind the indexed blocks reorged out of the canonical chain
+	for items > tail {
+		hash, _, err := rawdb.ReadTraceIndexBlock(indexer.freezer, items-1)
+		if err != nil {
+			return err
+		}
+		if number := indexer.offset + items - 1; number <= head && indexer.chain.GetCanonicalHash(number) == hash {
+			break
+		}
+		if err := indexer.truncateHead(items - 1); err != nil {
+			return err
+		}
+		items--
+	}
+	// Start over if the indexed blocks can't be extended up to the head
+	if next := indexer.offset + items; next < from || next > head+1 {
+		if err := indexer.reset(from); err != nil {
+			return err
+		}
+		items = 0
+	}
+	var (
+		start   = time.Now()
+		logged  = time.Now()
+		indexed int
+	)
+	for number := indexer.offset + items; number <= head; number++ {
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+		block := indexer.chain.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("canonical block #%d not found", number)
+		}
+		var (
+			records, ok = indexer.pending.Get(block.Hash())
+			gap         bool
+		)
+		if !ok {
+			if records, err = indexer.trace(block); err != nil {
+				// The block can't be traced anymore. Start indexing after it if
+				// nothing is indexed yet, otherwise record the gap to not drop
+				// the indexed history.
+				if items, tail, err := indexer.items(); err != nil {
+					return err
+				} else if items == tail {
+					log.Debug("Failed to trace block for indexing", "number", number, "hash", block.Hash(), "err", err)
+					if err := indexer.reset(number + 1); err != nil {
+						return err
+					}
+					continue
+				}
+				log.Warn("Recorded trace index gap", "number", number, "hash", block.Hash(), "err", err)
+				records, gap = nil, true
+			}
+		}
+		if err := indexer.append(number, block.Hash(), records, gap); err != nil {
+			return err
+		}
+		indexer.pending.Remove(block.Hash())
+
+		indexed++
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing traces", "number", number, "head", head, "blocks", indexed, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if indexed > 1 {
+		log.Debug("Indexed traces", "head", head, "blocks", indexed, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	// Unindex the blocks beyond the retention limit
+	if items, tail, err = indexer.items(); err != nil {
+		return err
+	}
+	if indexer.offset+tail < from {
+		return indexer.truncateTail(min(from-indexer.offset, items))
+	}
+	return nil
+}
+
+// trace re-executes the block on top of its parent state, collecting the call
+// frames of its transactions.
+func (indexer *TraceIndexer) trace(block *types.Block) ([]*rawdb.TraceRecord, error) {
+	if block.NumberU64() == 0 {
+		return nil, nil
+	}
+	parent := indexer.chain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, errors.New("parent not found")
+	}
+	statedb, err := indexer.chain.StateAt(parent.Root)
+	if err != nil {
+		return nil, err
+	}
+	collector := new(traceCollector)
+	if _, _, _, err := indexer.chain.processor.Process(block, statedb, vm.Config{Tracer: collector.hooks()}); err != nil {
+		return nil, err
+	}
+	return collector.records, nil
+}
+
+// items returns the number of items and the tail of the trace freezer.
+func (indexer *TraceIndexer) items() (uint64, uint64, error) {
+	items, err := indexer.freezer.Ancients()
+	if err != nil {
+		return 0, 0, err
+	}
+	tail, err := indexer.freezer.Tail()
+	if err != nil {
+		return 0, 0, err
+	}
+	return items, tail, nil
+}
+
+// append indexes the call frames of the given canonical block, which must be
+// the successor of the last indexed one. Blocks that couldn't be traced are
+// indexed as gaps, failing the queries covering them.
+func (indexer *TraceIndexer) append(number uint64, hash common.Hash, records []*rawdb.TraceRecord, gap bool) error {
+	indexer.lock.Lock()
+	defer indexer.lock.Unlock()
+
+	// Write the address index entries before the frozen call frames, so that a
+	// crash in between can't leave an indexed block without its entries. Stray
+	// entries of an unindexed block only make the queries visit an extra block,
+	// whose frames are matched against the address anyway.
+	item := number - indexer.offset
+	batch := indexer.db.NewBatch()
+	if item == 0 {
+		rawdb.WriteTraceIndexOffset(batch, indexer.offset)
+	}
+	rawdb.WriteTraceIndexEntries(batch, number, records)
+	if gap {
+		rawdb.WriteTraceIndexGap(batch, number)
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	_, err := indexer.freezer.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		return rawdb.WriteTraceIndexBlock(op, item, hash, records)
+	})
+	return err
+}
+
+// unindex removes the address index entries of the given freezer items.
+func (indexer *TraceIndexer) unindex(from, to uint64) error {
+	batch := indexer.db.NewBatch()
+	for item := from; item < to; item++ {
+		_, records, err := rawdb.ReadTraceIndexBlock(indexer.freezer, item)
+		if err != nil {
+			return err
+		}
+		rawdb.DeleteTraceIndexEntries(batch, indexer.offset+item, records)
+		rawdb.DeleteTraceIndexGap(batch, indexer.offset+item)
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	return batch.Write()
+}
+
+// truncateHead unindexes the blocks stored as freezer items above the given one.
+func (indexer *TraceIndexer) truncateHead(items uint64) error {
+	indexer.lock.Lock()
+	defer indexer.lock.Unlock()
+
+	head, err := indexer.freezer.Ancients()
+	if err != nil {
+		return err
+	}
+	if err := indexer.unindex(items, head); err != nil {
+		return err
+	}
+	_, err = indexer.freezer.TruncateHead(items)
+	return err
+}
+
+// truncateTail unindexes the blocks stored as freezer items below the given one.
+func (indexer *TraceIndexer) truncateTail(tail uint64) error {
+	indexer.lock.Lock()
+	defer indexer.lock.Unlock()
+
+	old, err := indexer.freezer.Tail()
+	if err != nil {
+		return err
+	}
+	if err := indexer.unindex(old, tail); err != nil {
+		return err
+	}
+	_, err = indexer.freezer.TruncateTail(tail)
+	return err
+}
+
+// reset drops the entire index, restarting it at the given block number.
+func (indexer *TraceIndexer) reset(number uint64) error {
+	items, tail, err := indexer.items()
+	if err != nil {
+		return err
+	}
+	indexer.lock.Lock()
+	defer indexer.lock.Unlock()
+
+	if items > 0 {
+		if err := indexer.unindex(tail, items); err != nil {
+			return err
+		}
+		if err := indexer.freezer.Reset(); err != nil {
+			return err
+		}
+	}
+	indexer.offset = number
+	return nil
+}
+
+// indexed returns the numbers of the first and last indexed blocks. The lock
+// must be held by the caller.
+func (indexer *TraceIndexer) indexed() (uint64, uint64, bool) {
+	items, tail, err := indexer.items()
+	if err != nil || items == tail {
+		return 0, 0, false
+	}
+	return indexer.offset + tail, indexer.offset + items - 1, true
+}
+
+// Range returns the numbers of the first and last indexed blocks, or false if
+// nothing is indexed yet.
+func (indexer *TraceIndexer) Range() (uint64, uint64, bool) {
+	indexer.lock.RLock()
+	defer indexer.lock.RUnlock()
+
+	return indexer.indexed()
+}
+
+// checkRange returns an error if the given block range is not fully indexed.
+// The lock must be held by the caller.
+func (indexer *TraceIndexer) checkRange(from, to uint64) error {
+	first, last, ok := indexer.indexed()
+	if !ok {
+		return ErrTraceRangeNotIndexed
+	}
+	if from < first || to > last {
+		return fmt.Errorf("%w: [%d, %d], indexed [%d, %d]", ErrTraceRangeNotIndexed, from, to, first, last)
+	}
+	if gaps := rawdb.ReadTraceIndexGaps(indexer.db, from, to); len(gaps) > 0 {
+		return fmt.Errorf("%w: block #%d could not be traced", ErrTraceRangeNotIndexed, gaps[0])
+	}
+	return nil
+}
+
+// Blocks returns the numbers of the blocks within [from, to] in which any of
+// the given addresses is the sender or the recipient of a call frame, in
+// ascending order.
+func (indexer *TraceIndexer) Blocks(addrs []common.Address, from, to uint64) ([]uint64, error) {
+	indexer.lock.RLock()
+	defer indexer.lock.RUnlock()
+
+	if err := indexer.checkRange(from, to); err != nil {
+		return nil, err
+	}
+	var numbers []uint64
+	for _, addr := range addrs {
+		numbers = append(numbers, rawdb.ReadTraceIndexNumbers(indexer.db, addr, from, to)...)
+	}
+	slices.Sort(numbers)
+	return slices.Compact(numbers), nil
+}
+
+// Traces returns the call frames within the blocks [from, to] in which the
+// given address is the sender or the recipient. At most limit+1 frames are
+// returned, allowing the caller to detect truncated results.
+func (indexer *TraceIndexer) Traces(addr common.Address, from, to uint64, limit int) ([]*IndexedTrace, error) {
+	indexer.lock.RLock()
+	defer indexer.lock.RUnlock()
+
+	if err := indexer.checkRange(from, to); err != nil {
+		return nil, err
+	}
+	var traces []*IndexedTrace
+	for _, number := range rawdb.ReadTraceIndexNumbers(indexer.db, addr, from, to) {
+		hash, records, err := rawdb.ReadTraceIndexBlock(indexer.freezer, number-indexer.offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if record.From != addr && record.To != addr {
+				continue
+			}
+			traces = append(traces, &IndexedTrace{BlockNumber: number, BlockHash: hash, TraceRecord: record})
+			if len(traces) > limit {
+				return traces, nil
+			}
+		}
+	}
+	return traces, nil
+}
+
+// traceCollector gathers the call frames of the transactions in a block.
+type traceCollector struct {
+	records []*rawdb.TraceRecord
+	frames  []int  // Indexes of the records of the open call frames
+	txs     uint64 // Number of transactions started
+	system  bool   // Whether a system call is being executed
+}
+
+func (c *traceCollector) hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnSystemCallStart: c.onSystemCallStart,
+		OnSystemCallEnd:   c.onSystemCallEnd,
+		OnTxStart:         c.onTxStart,
+		OnEnter:           c.onEnter,
+		OnExit:            c.onExit,
+	}
+}
+
+func (c *traceCollector) onSystemCallStart() {
+	c.system = true
+}
+
+func (c *traceCollector) onSystemCallEnd() {
+	c.system = false
+}
+
+func (c *traceCollector) onTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	c.txs++
+	c.frames = c.frames[:0]
+}
+
+func (c *traceCollector) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if c.system || c.txs == 0 {
+		return
+	}
+	record := &rawdb.TraceRecord{
+		TxIndex: c.txs - 1,
+		Depth:   uint64(depth),
+		Type:    typ,
+		From:    from,
+		To:      to,
+		Value:   new(big.Int),
+	}
+	if value != nil {
+		record.Value.Set(value)
+	}
+	c.frames = append(c.frames, len(c.records))
+	c.records = append(c.records, record)
+}
+
+func (c *traceCollector) onExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if c.system || len(c.frames) == 0 {
+		return
+	}
+	index := c.frames[len(c.frames)-1]
+	c.frames = c.frames[:len(c.frames)-1]
+
+	// Mark the frame and all its descendants as reverted
+	if reverted {
+		for _, record := range c.records[index:] {
+			record.Reverted = true
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// waitTraceIndexed waits until the trace indexer catches up with the head.
+func waitTraceIndexed(t *testing.T, indexer *TraceIndexer, head uint64) (uint64, uint64) {
+	t.Helper()
+
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if first, last, ok := indexer.Range(); ok && last == head {
+			return first, last
+		}
+	}
+	t.Fatalf("trace indexer did not reach head #%d", head)
+	return 0, 0
+}
+
+// Tests that the trace indexer indexes the call frames of the imported blocks,
+// re-executes the blocks imported without the live hooks, honours the retention
+// limit and unwinds the blocks reorged out of the chain.
+func TestTraceIndexer(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		callee   = common.HexToAddress("0xca11ee")
+		caller   = common.HexToAddress("0xc0de")
+		reverter = common.HexToAddress("0xdead")
+
+		// Sends 1 wei to the callee
+		callCode = append(append([]byte{
+			byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 1,
+			byte(vm.PUSH20)}, callee.Bytes()...),
+			byte(vm.GAS), byte(vm.CALL), byte(vm.POP),
+		)
+		gspec = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				sender:   {Balance: big.NewInt(params.Ether)},
+				caller:   {Balance: big.NewInt(params.Ether), Code: append(callCode, byte(vm.STOP))},
+				reverter: {Balance: big.NewInt(params.Ether), Code: append(callCode, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT))},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		engine = ethash.NewFaker()
+		signer = types.LatestSigner(gspec.Config)
+	)
+	genDb, blocks, _ := GenerateChainWithGenesis(gspec, engine, 8, func(i int, gen *BlockGen) {
+		to := caller
+		if i%2 == 1 {
+			to = reverter
+		}
+		tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: gen.TxNonce(sender), To: &to, Gas: 100000, GasPrice: gen.BaseFee()})
+		gen.AddTx(tx)
+	})
+	// Import the chain with the live hooks installed
+	db := rawdb.NewMemoryDatabase()
+	indexer, err := NewTraceIndexer(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create trace indexer: %v", err)
+	}
+	chain, err := NewBlockChain(db, nil, gspec, nil, engine, vm.Config{Tracer: indexer.Hooks()}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+	defer indexer.Close()
+
+	indexer.Start(chain)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if first, _ := waitTraceIndexed(t, indexer, 8); first != 0 {
+		t.Fatalf("first indexed block mismatch: have %d, want 0", first)
+	}
+	traces, err := indexer.Traces(callee, 0, 8, 100)
+	if err != nil {
+		t.Fatalf("failed to retrieve traces: %v", err)
+	}
+	if len(traces) != 8 {
+		t.Fatalf("trace count mismatch: have %d, want 8", len(traces))
+	}
+	for i, trace := range traces {
+		reverted := i%2 == 1
+		if trace.BlockNumber != uint64(i+1) || trace.BlockHash != blocks[i].Hash() || trace.Depth != 1 || trace.Type != byte(vm.CALL) {
+			t.Errorf("trace %d: position mismatch: %+v", i, trace)
+		}
+		if trace.To != callee || trace.Value.Cmp(common.Big1) != 0 || trace.Reverted != reverted {
+			t.Errorf("trace %d: frame mismatch: %+v", i, trace.TraceRecord)
+		}
+	}
+	if numbers, err := indexer.Blocks([]common.Address{caller, sender}, 2, 5); err != nil || !slices.Equal(numbers, []uint64{2, 3, 4, 5}) {
+		t.Errorf("indexed blocks mismatch: have %v, err %v", numbers, err)
+	}
+	// Reorg the chain to a fork without any call to the callee
+	forks, _ := GenerateChain(gspec.Config, blocks[3], engine, genDb, 6, func(i int, gen *BlockGen) {
+		tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: gen.TxNonce(sender), To: &callee, Value: common.Big2, Gas: params.TxGas, GasPrice: gen.BaseFee()})
+		gen.AddTx(tx)
+	})
+	if _, err := chain.InsertChain(forks); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	waitTraceIndexed(t, indexer, 10)
+	if traces, err = indexer.Traces(callee, 0, 10, 100); err != nil {
+		t.Fatalf("failed to retrieve traces: %v", err)
+	}
+	if len(traces) != 10 {
+		t.Fatalf("trace count mismatch after reorg: have %d, want 10", len(traces))
+	}
+	for i, trace := range traces[4:] {
+		if trace.BlockHash != forks[i].Hash() || trace.From != sender || trace.Value.Cmp(common.Big2) != 0 {
+			t.Errorf("trace %d: reorged trace mismatch: %+v", i+4, trace)
+		}
+	}
+	// Index the chain from scratch without live hooks, keeping the last 3 blocks
+	limited, err := NewTraceIndexer(rawdb.NewMemoryDatabase(), 3)
+	if err != nil {
+		t.Fatalf("failed to create trace indexer: %v", err)
+	}
+	defer limited.Close()
+
+	limited.Start(chain)
+	if first, _ := waitTraceIndexed(t, limited, 10); first != 8 {
+		t.Fatalf("first indexed block mismatch: have %d, want 8", first)
+	}
+	if traces, err = limited.Traces(callee, 8, 10, 100); err != nil || len(traces) != 3 {
+		t.Fatalf("re-executed trace mismatch: %v, err %v", traces, err)
+	}
+	if _, err := limited.Traces(callee, 7, 10, 100); !errors.Is(err, ErrTraceRangeNotIndexed) {
+		t.Fatalf("unindexed range error mismatch: have %v, want %v", err, ErrTraceRangeNotIndexed)
+	}
+	if numbers := rawdb.ReadTraceIndexNumbers(limited.db, callee, 0, 10); !slices.Equal(numbers, []uint64{8, 9, 10}) {
+		t.Errorf("stale index entries: have %v, want [8 9 10]", numbers)
+	}
+	// Blocks failing to be traced are indexed as gaps, failing the queries
+	// covering them without dropping the rest of the index
+	if err := limited.truncateHead(2); err != nil {
+		t.Fatalf("failed to unindex head: %v", err)
+	}
+	if err := limited.append(10, chain.GetCanonicalHash(10), nil, true); err != nil {
+		t.Fatalf("failed to index gap: %v", err)
+	}
+	if _, err := limited.Traces(callee, 8, 10, 100); !errors.Is(err, ErrTraceRangeNotIndexed) {
+		t.Fatalf("gap error mismatch: have %v, want %v", err, ErrTraceRangeNotIndexed)
+	}
+	if traces, err = limited.Traces(callee, 8, 9, 100); err != nil || len(traces) != 2 {
+		t.Fatalf("traces around gap mismatch: %v, err %v", traces, err)
+	}
+	if err := limited.truncateHead(2); err != nil {
+		t.Fatalf("failed to unindex gap: %v", err)
+	}
+	if gaps := rawdb.ReadTraceIndexGaps(limited.db, 0, 10); len(gaps) != 0 {
+		t.Errorf("stale gaps: %v", gaps)
+	}
+}
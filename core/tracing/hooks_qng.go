@@ -0,0 +1,221 @@
+package tracing
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// BalanceIncreaseMeerImport is ether minted by importing a locked UTXO from
+	// the MeerDAG through the bridge precompile.
+	BalanceIncreaseMeerImport BalanceChangeReason = 100
+
+	// BalanceDecreaseMeerExport is ether burnt by exporting it to the MeerDAG
+	// UTXO layer through the bridge precompile.
+	BalanceDecreaseMeerExport BalanceChangeReason = 101
+)
+
+// Join combines multiple sets of hooks into a single one, invoking each of
+// them in the given order. Nil hook sets and unset hooks are skipped.
+func Join(hooks ...*Hooks) *Hooks {
+	var set []*Hooks
+	for _, h := range hooks {
+		if h != nil {
+			set = append(set, h)
+		}
+	}
+	switch len(set) {
+	case 0:
+		return nil
+	case 1:
+		return set[0]
+	}
+	joined := new(Hooks)
+	for _, h := range set {
+		h := h
+		if h.OnTxStart != nil {
+			prev := joined.OnTxStart
+			joined.OnTxStart = func(env *VMContext, tx *types.Transaction, from common.Address) {
+				if prev != nil {
+					prev(env, tx, from)
+				}
+				h.OnTxStart(env, tx, from)
+			}
+		}
+		if h.OnTxEnd != nil {
+			prev := joined.OnTxEnd
+			joined.OnTxEnd = func(receipt *types.Receipt, err error) {
+				if prev != nil {
+					prev(receipt, err)
+				}
+				h.OnTxEnd(receipt, err)
+			}
+		}
+		if h.OnEnter != nil {
+			prev := joined.OnEnter
+			joined.OnEnter = func(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+				if prev != nil {
+					prev(depth, typ, from, to, input, gas, value)
+				}
+				h.OnEnter(depth, typ, from, to, input, gas, value)
+			}
+		}
+		if h.OnExit != nil {
+			prev := joined.OnExit
+			joined.OnExit = func(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+				if prev != nil {
+					prev(depth, output, gasUsed, err, reverted)
+				}
+				h.OnExit(depth, output, gasUsed, err, reverted)
+			}
+		}
+		if h.OnOpcode != nil {
+			prev := joined.OnOpcode
+			joined.OnOpcode = func(pc uint64, op byte, gas, cost uint64, scope OpContext, rData []byte, depth int, err error) {
+				if prev != nil {
+					prev(pc, op, gas, cost, scope, rData, depth, err)
+				}
+				h.OnOpcode(pc, op, gas, cost, scope, rData, depth, err)
+			}
+		}
+		if h.OnFault != nil {
+			prev := joined.OnFault
+			joined.OnFault = func(pc uint64, op byte, gas, cost uint64, scope OpContext, depth int, err error) {
+				if prev != nil {
+					prev(pc, op, gas, cost, scope, depth, err)
+				}
+				h.OnFault(pc, op, gas, cost, scope, depth, err)
+			}
+		}
+		if h.OnGasChange != nil {
+			prev := joined.OnGasChange
+			joined.OnGasChange = func(old, cur uint64, reason GasChangeReason) {
+				if prev != nil {
+					prev(old, cur, reason)
+				}
+				h.OnGasChange(old, cur, reason)
+			}
+		}
+		if h.OnBlockchainInit != nil {
+			prev := joined.OnBlockchainInit
+			joined.OnBlockchainInit = func(chainConfig *params.ChainConfig) {
+				if prev != nil {
+					prev(chainConfig)
+				}
+				h.OnBlockchainInit(chainConfig)
+			}
+		}
+		if h.OnClose != nil {
+			prev := joined.OnClose
+			joined.OnClose = func() {
+				if prev != nil {
+					prev()
+				}
+				h.OnClose()
+			}
+		}
+		if h.OnBlockStart != nil {
+			prev := joined.OnBlockStart
+			joined.OnBlockStart = func(event BlockEvent) {
+				if prev != nil {
+					prev(event)
+				}
+				h.OnBlockStart(event)
+			}
+		}
+		if h.OnBlockEnd != nil {
+			prev := joined.OnBlockEnd
+			joined.OnBlockEnd = func(err error) {
+				if prev != nil {
+					prev(err)
+				}
+				h.OnBlockEnd(err)
+			}
+		}
+		if h.OnSkippedBlock != nil {
+			prev := joined.OnSkippedBlock
+			joined.OnSkippedBlock = func(event BlockEvent) {
+				if prev != nil {
+					prev(event)
+				}
+				h.OnSkippedBlock(event)
+			}
+		}
+		if h.OnGenesisBlock != nil {
+			prev := joined.OnGenesisBlock
+			joined.OnGenesisBlock = func(genesis *types.Block, alloc types.GenesisAlloc) {
+				if prev != nil {
+					prev(genesis, alloc)
+				}
+				h.OnGenesisBlock(genesis, alloc)
+			}
+		}
+		if h.OnSystemCallStart != nil {
+			prev := joined.OnSystemCallStart
+			joined.OnSystemCallStart = func() {
+				if prev != nil {
+					prev()
+				}
+				h.OnSystemCallStart()
+			}
+		}
+		if h.OnSystemCallEnd != nil {
+			prev := joined.OnSystemCallEnd
+			joined.OnSystemCallEnd = func() {
+				if prev != nil {
+					prev()
+				}
+				h.OnSystemCallEnd()
+			}
+		}
+		if h.OnBalanceChange != nil {
+			prev := joined.OnBalanceChange
+			joined.OnBalanceChange = func(addr common.Address, from, to *big.Int, reason BalanceChangeReason) {
+				if prev != nil {
+					prev(addr, from, to, reason)
+				}
+				h.OnBalanceChange(addr, from, to, reason)
+			}
+		}
+		if h.OnNonceChange != nil {
+			prev := joined.OnNonceChange
+			joined.OnNonceChange = func(addr common.Address, from, to uint64) {
+				if prev != nil {
+					prev(addr, from, to)
+				}
+				h.OnNonceChange(addr, from, to)
+			}
+		}
+		if h.OnCodeChange != nil {
+			prev := joined.OnCodeChange
+			joined.OnCodeChange = func(addr common.Address, prevCodeHash common.Hash, prevCode []byte, codeHash common.Hash, code []byte) {
+				if prev != nil {
+					prev(addr, prevCodeHash, prevCode, codeHash, code)
+				}
+				h.OnCodeChange(addr, prevCodeHash, prevCode, codeHash, code)
+			}
+		}
+		if h.OnStorageChange != nil {
+			prev := joined.OnStorageChange
+			joined.OnStorageChange = func(addr common.Address, slot common.Hash, from, to common.Hash) {
+				if prev != nil {
+					prev(addr, slot, from, to)
+				}
+				h.OnStorageChange(addr, slot, from, to)
+			}
+		}
+		if h.OnLog != nil {
+			prev := joined.OnLog
+			joined.OnLog = func(log *types.Log) {
+				if prev != nil {
+					prev(log)
+				}
+				h.OnLog(log)
+			}
+		}
+	}
+	return joined
+}
@@ -0,0 +1,147 @@
+package txpool
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"golang.org/x/time/rate"
+)
+
+// maxAdmissionOrigins is the number of RPC origins tracked for rate limiting.
+const maxAdmissionOrigins = 4096
+
+var admissionRejectMeter = metrics.NewRegisteredMeter("txpool/admission/rejected", nil)
+
+// admission enforces the admission rules of the pool, tracking the submission
+// rate of the RPC origins.
+type admission struct {
+	config  AdmissionConfig
+	origins lru.BasicLRU[string, *rate.Limiter] // Rate limiters of the recently seen origins
+	lock    sync.Mutex
+}
+
+func newAdmission(config AdmissionConfig) *admission {
+	return &admission{
+		config:  config,
+		origins: lru.NewBasicLRU[string, *rate.Limiter](maxAdmissionOrigins),
+	}
+}
+
+// senderRules returns whether any sender based admission rule is configured.
+func (a *admission) senderRules() bool {
+	return a.config.MaxPerSender > 0 || len(a.config.Deny) > 0 || len(a.config.Allow) > 0
+}
+
+// allow consumes a submission from the origin, returning whether it's within
+// the origin's rate limit. The caller must hold the lock.
+func (a *admission) allow(origin string) bool {
+	if origin == "" || a.config.OriginRate <= 0 {
+		return true
+	}
+	limiter, ok := a.origins.Get(origin)
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(a.config.OriginRate), max(a.config.OriginBurst, 1))
+		a.origins.Add(origin, limiter)
+	}
+	return limiter.Allow()
+}
+
+// SetAdmission replaces the admission rules of the pool. The new rules apply to
+// subsequent submissions, the pooled transactions are not reevaluated.
+func (p *TxPool) SetAdmission(config AdmissionConfig) {
+	p.admission.lock.Lock()
+	defer p.admission.lock.Unlock()
+
+	config.Deny = slices.Clone(config.Deny)
+	config.Allow = slices.Clone(config.Allow)
+	p.admission.config = config
+	p.admission.origins.Purge()
+
+	log.Debug("Updated transaction pool admission rules", "maxpersender", config.MaxPerSender,
+		"originrate", config.OriginRate, "originburst", config.OriginBurst, "deny", len(config.Deny), "allow", len(config.Allow))
+}
+
+// Admission returns the admission rules of the pool.
+func (p *TxPool) Admission() AdmissionConfig {
+	p.admission.lock.Lock()
+	defer p.admission.lock.Unlock()
+
+	config := p.admission.config
+	config.Deny = slices.Clone(config.Deny)
+	config.Allow = slices.Clone(config.Allow)
+	return config
+}
+
+// AddWithOrigin enqueues a batch of transactions submitted by the given RPC
+// origin into the pool, if they pass the admission rules and are valid. An empty
+// origin exempts the transactions from the origin rate limits.
+func (p *TxPool) AddWithOrigin(origin string, txs []*types.Transaction, local bool, sync bool) []error {
+	errs := make([]error, len(txs))
+
+	// Filter out the transactions rejected by the admission rules, tracking the
+	// position of the admitted ones to piece back the errors
+	var (
+		admitted []*types.Transaction
+		indices  []int
+	)
+	p.admission.lock.Lock()
+	var (
+		senders = p.admission.senderRules()
+		pooled  = make(map[common.Address]map[uint64]struct{})
+	)
+	for i, tx := range txs {
+		if !p.admission.allow(origin) {
+			errs[i] = &AdmissionError{Code: AdmissionRateLimited, Reason: "origin " + origin + " rate limited"}
+			continue
+		}
+		if senders {
+			// Leave transactions with invalid signatures to the subpools to reject
+			if from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+				nonces, ok := pooled[from]
+				if !ok {
+					nonces = make(map[uint64]struct{})
+					if p.admission.config.MaxPerSender > 0 {
+						nonces = p.pooledNonces(from)
+					}
+					pooled[from] = nonces
+				}
+				_, replace := nonces[tx.Nonce()]
+				if errs[i] = ValidateAdmission(&p.admission.config, from, uint64(len(nonces)), replace); errs[i] != nil {
+					continue
+				}
+				nonces[tx.Nonce()] = struct{}{}
+			}
+		}
+		admitted = append(admitted, tx)
+		indices = append(indices, i)
+	}
+	p.admission.lock.Unlock()
+
+	if rejected := len(txs) - len(admitted); rejected > 0 {
+		admissionRejectMeter.Mark(int64(rejected))
+	}
+	if len(admitted) > 0 {
+		for i, err := range p.add(admitted, local, sync) {
+			errs[indices[i]] = err
+		}
+	}
+	return errs
+}
+
+// pooledNonces returns the nonces of the transactions of the sender tracked by
+// the subpools, pending and queued alike.
+func (p *TxPool) pooledNonces(addr common.Address) map[uint64]struct{} {
+	nonces := make(map[uint64]struct{})
+	for _, subpool := range p.subpools {
+		pending, queued := subpool.ContentFrom(addr)
+		for _, tx := range append(pending, queued...) {
+			nonces[tx.Nonce()] = struct{}{}
+		}
+	}
+	return nonces
+}
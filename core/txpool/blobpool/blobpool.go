@@ -314,6 +314,11 @@ type BlobPool struct {
 	discoverFeed event.Feed // Event feed to send out new tx events on pool discovery (reorg excluded)
 	insertFeed   event.Feed // Event feed to send out new tx events on pool inclusion (reorg included)
 
+	eventFeed  event.Feed              // Feed of the transaction lifecycle events
+	eventScope event.SubscriptionScope // Subscription scope of the lifecycle event feed
+	events     []txpool.TxPoolEvent    // Lifecycle events waiting to be delivered
+	eventsMu   sync.Mutex              // Lock protecting the undelivered events
+
 	lock sync.RWMutex // Mutex protecting the pool during reorg handling
 }
 
@@ -435,6 +440,8 @@ func (p *BlobPool) Init(gasTip uint64, head *types.Header, reserve txpool.Addres
 
 // Close closes down the underlying persistent store.
 func (p *BlobPool) Close() error {
+	p.eventScope.Close()
+
 	var errs []error
 	if p.limbo != nil { // Close might be invoked due to error in constructor, before p,limbo is set
 		if err := p.limbo.Close(); err != nil {
@@ -548,9 +555,11 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 		if gapped {
 			log.Warn("Dropping dangling blob transactions", "from", addr, "missing", next, "drop", nonces, "ids", ids)
 			dropDanglingMeter.Mark(int64(len(ids)))
+			p.emitDrops(txs, txpool.DropNonceGap)
 		} else {
 			log.Trace("Dropping filled blob transactions", "from", addr, "filled", nonces, "ids", ids)
 			dropFilledMeter.Mark(int64(len(ids)))
+			p.emitStales(txs, inclusions)
 		}
 		for _, id := range ids {
 			if err := p.store.Delete(id); err != nil {
@@ -578,6 +587,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			if inclusions != nil {
 				p.offload(addr, txs[0].nonce, txs[0].id, inclusions)
 			}
+			p.emitStales(txs[:1], inclusions)
 			txs = txs[1:]
 		}
 		log.Trace("Dropping overlapped blob transactions", "from", addr, "overlapped", nonces, "ids", ids, "left", len(txs))
@@ -625,6 +635,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 
 			log.Error("Dropping repeat nonce blob transaction", "from", addr, "nonce", txs[i].nonce, "id", id)
 			dropRepeatedMeter.Mark(1)
+			p.emit(txpool.TxEventDropped, txs[i].hash, txpool.DropRemoved)
 
 			p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], txs[i].costCap)
 			p.stored -= uint64(txs[i].size)
@@ -652,6 +663,7 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			p.stored -= uint64(txs[j].size)
 			delete(p.lookup, txs[j].hash)
 		}
+		p.emitDrops(txs[i:], txpool.DropNonceGap)
 		txs = txs[:i]
 
 		log.Error("Dropping gapped blob transactions", "from", addr, "missing", txs[i-1].nonce+1, "drop", nonces, "ids", ids)
@@ -683,6 +695,8 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			txs[len(txs)-1] = nil
 			txs = txs[:len(txs)-1]
 
+			p.emit(txpool.TxEventDropped, last.hash, txpool.DropUnpayable)
+
 			ids = append(ids, last.id)
 			nonces = append(nonces, last.nonce)
 
@@ -723,6 +737,8 @@ func (p *BlobPool) recheck(addr common.Address, inclusions map[common.Hash]uint6
 			txs[len(txs)-1] = nil
 			txs = txs[:len(txs)-1]
 
+			p.emit(txpool.TxEventDropped, last.hash, txpool.DropPoolLimit)
+
 			ids = append(ids, last.id)
 			nonces = append(nonces, last.nonce)
 
@@ -780,6 +796,8 @@ func (p *BlobPool) offload(addr common.Address, nonce uint64, id uint64, inclusi
 // Reset implements txpool.SubPool, allowing the blob pool's internal state to be
 // kept in sync with the main transaction pool's internal state.
 func (p *BlobPool) Reset(oldHead, newHead *types.Header) {
+	defer p.flushEvents() // Deferred first to run after releasing the lock
+
 	waitStart := time.Now()
 	p.lock.Lock()
 	resetwaitHist.Update(time.Since(waitStart).Nanoseconds())
@@ -806,6 +824,9 @@ func (p *BlobPool) Reset(oldHead, newHead *types.Header) {
 			for _, tx := range txs {
 				if err := p.reinject(addr, tx.Hash()); err == nil {
 					adds = append(adds, tx.WithoutBlobTxSidecar())
+					p.emit(txpool.TxEventAdded, tx.Hash(), "")
+				} else {
+					p.emit(txpool.TxEventDropped, tx.Hash(), txpool.DropReorg)
 				}
 			}
 			// Recheck the account's pooled transactions to drop included and
@@ -1014,6 +1035,8 @@ func (p *BlobPool) reinject(addr common.Address, txhash common.Hash) error {
 // SetGasTip implements txpool.SubPool, allowing the blob pool's gas requirements
 // to be kept in sync with the main transaction pool's gas requirements.
 func (p *BlobPool) SetGasTip(tip *big.Int) {
+	defer p.flushEvents() // Deferred first to run after releasing the lock
+
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
@@ -1030,6 +1053,7 @@ func (p *BlobPool) SetGasTip(tip *big.Int) {
 					var (
 						ids    = []uint64{tx.id}
 						nonces = []uint64{tx.nonce}
+						hashes = []common.Hash{tx.hash}
 					)
 					p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], txs[i].costCap)
 					p.stored -= uint64(tx.size)
@@ -1040,6 +1064,7 @@ func (p *BlobPool) SetGasTip(tip *big.Int) {
 					for j, tx := range txs[i+1:] {
 						ids = append(ids, tx.id)
 						nonces = append(nonces, tx.nonce)
+						hashes = append(hashes, tx.hash)
 
 						p.spent[addr] = new(uint256.Int).Sub(p.spent[addr], tx.costCap)
 						p.stored -= uint64(tx.size)
@@ -1060,6 +1085,9 @@ func (p *BlobPool) SetGasTip(tip *big.Int) {
 					// Clear out the transactions from the data store
 					log.Warn("Dropping underpriced blob transaction", "from", addr, "rejected", tx.nonce, "tip", tx.execTipCap, "want", tip, "drop", nonces, "ids", ids)
 					dropUnderpricedMeter.Mark(int64(len(ids)))
+					for _, hash := range hashes {
+						p.emit(txpool.TxEventDropped, hash, txpool.DropUnderpriced)
+					}
 
 					for _, id := range ids {
 						if err := p.store.Delete(id); err != nil {
@@ -1136,30 +1164,10 @@ func (p *BlobPool) validateTx(tx *types.Transaction) error {
 		if prev.hash == tx.Hash() {
 			return txpool.ErrAlreadyKnown
 		}
-		// Account can support the replacement, but the price bump must also be met
-		switch {
-		case tx.GasFeeCapIntCmp(prev.execFeeCap.ToBig()) <= 0:
-			return fmt.Errorf("%w: new tx gas fee cap %v <= %v queued", txpool.ErrReplaceUnderpriced, tx.GasFeeCap(), prev.execFeeCap)
-		case tx.GasTipCapIntCmp(prev.execTipCap.ToBig()) <= 0:
-			return fmt.Errorf("%w: new tx gas tip cap %v <= %v queued", txpool.ErrReplaceUnderpriced, tx.GasTipCap(), prev.execTipCap)
-		case tx.BlobGasFeeCapIntCmp(prev.blobFeeCap.ToBig()) <= 0:
-			return fmt.Errorf("%w: new tx blob gas fee cap %v <= %v queued", txpool.ErrReplaceUnderpriced, tx.BlobGasFeeCap(), prev.blobFeeCap)
-		}
-		var (
-			multiplier = uint256.NewInt(100 + p.config.PriceBump)
-			onehundred = uint256.NewInt(100)
-
-			minGasFeeCap     = new(uint256.Int).Div(new(uint256.Int).Mul(multiplier, prev.execFeeCap), onehundred)
-			minGasTipCap     = new(uint256.Int).Div(new(uint256.Int).Mul(multiplier, prev.execTipCap), onehundred)
-			minBlobGasFeeCap = new(uint256.Int).Div(new(uint256.Int).Mul(multiplier, prev.blobFeeCap), onehundred)
-		)
-		switch {
-		case tx.GasFeeCapIntCmp(minGasFeeCap.ToBig()) < 0:
-			return fmt.Errorf("%w: new tx gas fee cap %v <= %v queued + %d%% replacement penalty", txpool.ErrReplaceUnderpriced, tx.GasFeeCap(), prev.execFeeCap, p.config.PriceBump)
-		case tx.GasTipCapIntCmp(minGasTipCap.ToBig()) < 0:
-			return fmt.Errorf("%w: new tx gas tip cap %v <= %v queued + %d%% replacement penalty", txpool.ErrReplaceUnderpriced, tx.GasTipCap(), prev.execTipCap, p.config.PriceBump)
-		case tx.BlobGasFeeCapIntCmp(minBlobGasFeeCap.ToBig()) < 0:
-			return fmt.Errorf("%w: new tx blob gas fee cap %v <= %v queued + %d%% replacement penalty", txpool.ErrReplaceUnderpriced, tx.BlobGasFeeCap(), prev.blobFeeCap, p.config.PriceBump)
+		// Account can support the replacement, but the replacement policy must
+		// also be met
+		if err := p.replacementFees(prev, tx).Check(tx); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -1223,6 +1231,7 @@ func (p *BlobPool) Add(txs []*types.Transaction, local bool, sync bool) []error
 		p.discoverFeed.Send(core.NewTxsEvent{Txs: adds})
 		p.insertFeed.Send(core.NewTxsEvent{Txs: adds})
 	}
+	p.flushEvents()
 	return errs
 }
 
@@ -1322,6 +1331,8 @@ func (p *BlobPool) add(tx *types.Transaction) (err error) {
 		delete(p.lookup, prev.hash)
 		p.lookup[meta.hash] = meta.id
 		p.stored += uint64(meta.size) - uint64(prev.size)
+
+		p.emitReplaced(prev.hash, meta.hash)
 	} else {
 		// Transaction extends previously scheduled ones
 		p.index[from] = append(p.index[from], meta)
@@ -1380,6 +1391,8 @@ func (p *BlobPool) add(tx *types.Transaction) (err error) {
 			heap.Fix(p.evict, p.evict.index[from])
 		}
 	}
+	p.emit(txpool.TxEventAdded, meta.hash, "")
+
 	// If the pool went over the allowed data limit, evict transactions until
 	// we're again below the threshold
 	for p.stored > p.config.Datacap {
@@ -1439,6 +1452,7 @@ func (p *BlobPool) drop() {
 	// Remove the transaction from the data store
 	log.Debug("Evicting overflown blob transaction", "from", from, "evicted", drop.nonce, "id", drop.id)
 	dropOverflownMeter.Mark(1)
+	p.emit(txpool.TxEventDropped, drop.hash, txpool.DropUnderpriced)
 
 	if err := p.store.Delete(drop.id); err != nil {
 		log.Error("Failed to drop evicted transaction", "id", drop.id, "err", err)
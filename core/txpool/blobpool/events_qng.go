@@ -0,0 +1,66 @@
+package blobpool
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// SubscribeEvents implements txpool.EventSubPool, subscribing to the lifecycle
+// events of the pooled blob transactions.
+func (p *BlobPool) SubscribeEvents(ch chan<- []txpool.TxPoolEvent) event.Subscription {
+	return p.eventScope.Track(p.eventFeed.Subscribe(ch))
+}
+
+// emit queues a lifecycle event for delivery on the next flush. Events are only
+// tracked while there are subscribers.
+func (p *BlobPool) emit(typ txpool.TxEventType, hash common.Hash, reason txpool.DropReason) {
+	if p.eventScope.Count() == 0 {
+		return
+	}
+	p.eventsMu.Lock()
+	p.events = append(p.events, txpool.TxPoolEvent{Type: typ, Hash: hash, Reason: reason})
+	p.eventsMu.Unlock()
+}
+
+// emitDrops queues a drop event with the same reason for all the transactions.
+func (p *BlobPool) emitDrops(txs []*blobTxMeta, reason txpool.DropReason) {
+	for _, tx := range txs {
+		p.emit(txpool.TxEventDropped, tx.hash, reason)
+	}
+}
+
+// emitReplaced queues the replacement of a transaction by a same-nonce one.
+func (p *BlobPool) emitReplaced(old common.Hash, replacement common.Hash) {
+	if p.eventScope.Count() == 0 {
+		return
+	}
+	p.eventsMu.Lock()
+	p.events = append(p.events, txpool.TxPoolEvent{Type: txpool.TxEventReplaced, Hash: old, Replacement: &replacement})
+	p.eventsMu.Unlock()
+}
+
+// emitStales queues the events of transactions whose nonce was consumed on
+// chain, either by their inclusion or by another transaction.
+func (p *BlobPool) emitStales(txs []*blobTxMeta, inclusions map[common.Hash]uint64) {
+	for _, tx := range txs {
+		if _, ok := inclusions[tx.hash]; ok {
+			p.emit(txpool.TxEventIncluded, tx.hash, "")
+		} else {
+			p.emit(txpool.TxEventDropped, tx.hash, txpool.DropNonceUsed)
+		}
+	}
+}
+
+// flushEvents delivers the queued lifecycle events to the subscribers. It must
+// not be called with the pool lock held, as delivery may block.
+func (p *BlobPool) flushEvents() {
+	p.eventsMu.Lock()
+	events := p.events
+	p.events = nil
+	p.eventsMu.Unlock()
+
+	if len(events) > 0 {
+		p.eventFeed.Send(events)
+	}
+}
@@ -0,0 +1,120 @@
+package blobpool
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/holiman/uint256"
+)
+
+// eventTestChain is a mock chain serving a fixed set of blocks, allowing to
+// reset the pool onto a block including pooled transactions.
+type eventTestChain struct {
+	*testBlockChain
+	blocks map[common.Hash]*types.Block
+}
+
+func (bc *eventTestChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	return bc.blocks[hash]
+}
+
+// Tests that the lifecycle events of the pooled blob transactions are reported
+// along with the replacements and drop reasons.
+func TestPoolEvents(t *testing.T) {
+	var (
+		key, _     = crypto.GenerateKey()
+		addr       = crypto.PubkeyToAddress(key.PublicKey)
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewDatabase(memorydb.New())), nil)
+	)
+	statedb.AddBalance(addr, uint256.NewInt(1_000_000_000), tracing.BalanceChangeUnspecified)
+	statedb.Commit(0, true)
+
+	chain := &eventTestChain{
+		testBlockChain: &testBlockChain{
+			config:  testChainConfig,
+			basefee: uint256.NewInt(1050),
+			blobfee: uint256.NewInt(105),
+			statedb: statedb,
+		},
+		blocks: make(map[common.Hash]*types.Block),
+	}
+	pool := New(Config{Datadir: t.TempDir()}, chain)
+	if err := pool.Init(1, chain.CurrentBlock(), makeAddressReserver()); err != nil {
+		t.Fatalf("failed to create blob pool: %v", err)
+	}
+	defer pool.Close()
+
+	events := make(chan []txpool.TxPoolEvent, 16)
+	sub := pool.SubscribeEvents(events)
+	defer sub.Unsubscribe()
+
+	var (
+		tx0  = makeTx(0, 10, 2000, 200, key)
+		repl = makeTx(0, 20, 4000, 400, key)
+		tx1  = makeTx(1, 5, 2000, 200, key)
+	)
+	expect := func(want ...txpool.TxPoolEvent) {
+		t.Helper()
+
+		var have []txpool.TxPoolEvent
+		for len(have) < len(want) {
+			select {
+			case batch := <-events:
+				have = append(have, batch...)
+			case <-time.After(time.Second):
+				t.Fatalf("event timeout: have %d, want %d", len(have), len(want))
+			}
+		}
+		if len(have) != len(want) {
+			t.Fatalf("event count mismatch: have %v, want %v", have, want)
+		}
+		for i := range want {
+			if have[i].Type != want[i].Type || have[i].Hash != want[i].Hash || have[i].Reason != want[i].Reason {
+				t.Fatalf("event %d mismatch: have %+v, want %+v", i, have[i], want[i])
+			}
+			if (have[i].Replacement == nil) != (want[i].Replacement == nil) || (have[i].Replacement != nil && *have[i].Replacement != *want[i].Replacement) {
+				t.Fatalf("event %d replacement mismatch: have %v, want %v", i, have[i].Replacement, want[i].Replacement)
+			}
+		}
+	}
+	if errs := pool.Add([]*types.Transaction{tx0, tx1}, false, true); errs[0] != nil || errs[1] != nil {
+		t.Fatalf("failed to add transactions: %v", errs)
+	}
+	expect(txpool.TxPoolEvent{Type: txpool.TxEventAdded, Hash: tx0.Hash()}, txpool.TxPoolEvent{Type: txpool.TxEventAdded, Hash: tx1.Hash()})
+
+	// Replacing a transaction must report the replacement
+	if errs := pool.Add([]*types.Transaction{repl}, false, true); errs[0] != nil {
+		t.Fatalf("failed to replace transaction: %v", errs[0])
+	}
+	replHash := repl.Hash()
+	expect(txpool.TxPoolEvent{Type: txpool.TxEventReplaced, Hash: tx0.Hash(), Replacement: &replHash}, txpool.TxPoolEvent{Type: txpool.TxEventAdded, Hash: replHash})
+
+	// Raising the tip threshold must evict the underpriced transactions
+	pool.SetGasTip(big.NewInt(8))
+	expect(txpool.TxPoolEvent{Type: txpool.TxEventDropped, Hash: tx1.Hash(), Reason: txpool.DropUnderpriced})
+
+	// Including a transaction in the chain must report its inclusion
+	var (
+		oldHead = chain.CurrentBlock()
+		newHead = types.CopyHeader(oldHead)
+	)
+	newHead.Number = new(big.Int).Add(oldHead.Number, common.Big1)
+	newHead.ParentHash = oldHead.Hash()
+	newHead.Time = oldHead.Time + 1
+
+	chain.blocks[oldHead.Hash()] = types.NewBlockWithHeader(oldHead)
+	chain.blocks[newHead.Hash()] = types.NewBlockWithHeader(newHead).WithBody(types.Body{Transactions: types.Transactions{repl}})
+
+	statedb.SetNonce(addr, 1)
+	pool.Reset(oldHead, newHead)
+	expect(txpool.TxPoolEvent{Type: txpool.TxEventIncluded, Hash: replHash})
+}
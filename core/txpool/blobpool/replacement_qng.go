@@ -0,0 +1,40 @@
+package blobpool
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// replacementFees returns the minimum fees to replace the pooled transaction.
+// The pooled transaction is only read from the store if the replacement policy
+// depends on its recipient. The caller must hold the pool lock.
+func (p *BlobPool) replacementFees(prev *blobTxMeta, tx *types.Transaction) *txpool.ReplacementFees {
+	var changed bool
+	if p.config.Replacement.RecipientBump > 0 {
+		// Assume the recipient changed if the pooled transaction can't be read
+		changed = true
+		if data, err := p.store.Get(prev.id); err == nil {
+			old := new(types.Transaction)
+			if err := rlp.DecodeBytes(data, old); err == nil {
+				changed = txpool.RecipientChanged(old, tx)
+			}
+		}
+	}
+	return p.config.Replacement.MinFees(prev.execTipCap.ToBig(), prev.execFeeCap.ToBig(), prev.blobFeeCap.ToBig(), changed)
+}
+
+// Replacement implements txpool.ReplacementSubPool, returning the pooled
+// transaction the given one would replace and the minimum fees to replace it.
+func (p *BlobPool) Replacement(from common.Address, tx *types.Transaction) (common.Hash, *txpool.ReplacementFees, bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	next := p.state.GetNonce(from)
+	if tx.Nonce() < next || uint64(len(p.index[from])) <= tx.Nonce()-next {
+		return common.Hash{}, nil, false
+	}
+	prev := p.index[from][int(tx.Nonce()-next)]
+	return prev.hash, p.replacementFees(prev, tx), true
+}
@@ -0,0 +1,250 @@
+package txpool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// maxConditionalCost is the maximum number of accounts and storage slots a
+	// transaction conditional may check.
+	maxConditionalCost = 1000
+
+	// maxConditionalTxs is the maximum number of conditional transactions
+	// tracked at once.
+	maxConditionalTxs = 4096
+)
+
+var (
+	// ErrConditionalLimit is returned if too many conditional transactions are
+	// pending.
+	ErrConditionalLimit = errors.New("conditional transaction limit reached")
+
+	conditionalDropMeter = metrics.NewRegisteredMeter("txpool/conditional/dropped", nil)
+)
+
+// KnownAccount is the expected storage of an account, either its storage root or
+// the values of some of its storage slots.
+type KnownAccount struct {
+	StorageRoot  *common.Hash
+	StorageSlots map[common.Hash]common.Hash
+}
+
+// UnmarshalJSON decodes either a storage root hash or a map of slot values.
+func (a *KnownAccount) UnmarshalJSON(input []byte) error {
+	var root common.Hash
+	if err := json.Unmarshal(input, &root); err == nil {
+		a.StorageRoot, a.StorageSlots = &root, nil
+		return nil
+	}
+	var slots map[common.Hash]common.Hash
+	if err := json.Unmarshal(input, &slots); err != nil {
+		return errors.New("known account must be a storage root or a map of storage slots")
+	}
+	a.StorageRoot, a.StorageSlots = nil, slots
+	return nil
+}
+
+// MarshalJSON encodes the storage root if set, the map of slot values otherwise.
+func (a KnownAccount) MarshalJSON() ([]byte, error) {
+	if a.StorageRoot != nil {
+		return json.Marshal(a.StorageRoot)
+	}
+	return json.Marshal(a.StorageSlots)
+}
+
+// TransactionConditional are the constraints a transaction may only be included
+// under, as accepted by eth_sendRawTransactionConditional.
+type TransactionConditional struct {
+	KnownAccounts  map[common.Address]KnownAccount `json:"knownAccounts,omitempty"`
+	BlockNumberMin *hexutil.Uint64                 `json:"blockNumberMin,omitempty"`
+	BlockNumberMax *hexutil.Uint64                 `json:"blockNumberMax,omitempty"`
+	TimestampMin   *hexutil.Uint64                 `json:"timestampMin,omitempty"`
+	TimestampMax   *hexutil.Uint64                 `json:"timestampMax,omitempty"`
+}
+
+// ConditionalError is returned if a transaction conditional doesn't hold.
+type ConditionalError struct {
+	Reason string
+}
+
+func (e *ConditionalError) Error() string { return "transaction conditional failed: " + e.Reason }
+
+// ErrorCode returns the JSON-RPC error code for conditional failures.
+func (e *ConditionalError) ErrorCode() int { return -32003 }
+
+// Cost returns the number of accounts and storage slots checked by the
+// conditional.
+func (c *TransactionConditional) Cost() int {
+	var cost int
+	for _, account := range c.KnownAccounts {
+		if account.StorageRoot != nil {
+			cost++
+		} else {
+			cost += len(account.StorageSlots)
+		}
+	}
+	return cost
+}
+
+// Validate checks the sanity of the conditional, regardless of the chain.
+func (c *TransactionConditional) Validate() error {
+	if cost := c.Cost(); cost > maxConditionalCost {
+		return fmt.Errorf("conditional cost %d exceeds limit %d", cost, maxConditionalCost)
+	}
+	if c.BlockNumberMin != nil && c.BlockNumberMax != nil && *c.BlockNumberMin > *c.BlockNumberMax {
+		return errors.New("conditional block number range empty")
+	}
+	if c.TimestampMin != nil && c.TimestampMax != nil && *c.TimestampMin > *c.TimestampMax {
+		return errors.New("conditional timestamp range empty")
+	}
+	return nil
+}
+
+// CheckHeader checks the block number and timestamp ranges against the block
+// the transaction would be included in.
+func (c *TransactionConditional) CheckHeader(header *types.Header) error {
+	number := header.Number.Uint64()
+	if c.BlockNumberMin != nil && number < uint64(*c.BlockNumberMin) {
+		return &ConditionalError{Reason: fmt.Sprintf("block number %d before minimum %d", number, *c.BlockNumberMin)}
+	}
+	if c.BlockNumberMax != nil && number > uint64(*c.BlockNumberMax) {
+		return &ConditionalError{Reason: fmt.Sprintf("block number %d after maximum %d", number, *c.BlockNumberMax)}
+	}
+	if c.TimestampMin != nil && header.Time < uint64(*c.TimestampMin) {
+		return &ConditionalError{Reason: fmt.Sprintf("timestamp %d before minimum %d", header.Time, *c.TimestampMin)}
+	}
+	if c.TimestampMax != nil && header.Time > uint64(*c.TimestampMax) {
+		return &ConditionalError{Reason: fmt.Sprintf("timestamp %d after maximum %d", header.Time, *c.TimestampMax)}
+	}
+	return nil
+}
+
+// CheckState checks the expected storage of the known accounts against the state.
+func (c *TransactionConditional) CheckState(statedb *state.StateDB) error {
+	for addr, account := range c.KnownAccounts {
+		if account.StorageRoot != nil {
+			if root := statedb.GetStorageRoot(addr); root != *account.StorageRoot {
+				return &ConditionalError{Reason: fmt.Sprintf("storage root of %v is %v, expected %v", addr, root, *account.StorageRoot)}
+			}
+			continue
+		}
+		for slot, want := range account.StorageSlots {
+			if have := statedb.GetState(addr, slot); have != want {
+				return &ConditionalError{Reason: fmt.Sprintf("storage slot %v of %v is %v, expected %v", slot, addr, have, want)}
+			}
+		}
+	}
+	return nil
+}
+
+// Check checks all the constraints of the conditional against the block the
+// transaction would be included in and the state it would be executed on.
+func (c *TransactionConditional) Check(header *types.Header, statedb *state.StateDB) error {
+	if err := c.CheckHeader(header); err != nil {
+		return err
+	}
+	return c.CheckState(statedb)
+}
+
+// Expired returns whether the conditional can't hold anymore for any block
+// after the given head.
+func (c *TransactionConditional) Expired(head *types.Header) bool {
+	if c.BlockNumberMax != nil && head.Number.Uint64() >= uint64(*c.BlockNumberMax) {
+		return true
+	}
+	return c.TimestampMax != nil && head.Time >= uint64(*c.TimestampMax)
+}
+
+// conditionalSet tracks the constraints of the pooled conditional transactions.
+type conditionalSet struct {
+	txs  map[common.Hash]*TransactionConditional
+	lock sync.RWMutex
+}
+
+func newConditionalSet() *conditionalSet {
+	return &conditionalSet{txs: make(map[common.Hash]*TransactionConditional)}
+}
+
+// AddConditional enqueues a transaction submitted by the given RPC origin into
+// the pool, to only be included in blocks satisfying its conditional. The pool
+// keeps the transaction until the conditional can't hold anymore. The caller is
+// responsible for checking the conditional against the current state.
+func (p *TxPool) AddConditional(origin string, tx *types.Transaction, cond *TransactionConditional) error {
+	if err := cond.Validate(); err != nil {
+		return err
+	}
+	// Track the conditional before pooling the transaction, to not race the
+	// miner picking it up
+	hash := tx.Hash()
+
+	p.conditionals.lock.Lock()
+	prev, known := p.conditionals.txs[hash]
+	if !known && len(p.conditionals.txs) >= maxConditionalTxs {
+		p.conditionals.lock.Unlock()
+		return ErrConditionalLimit
+	}
+	p.conditionals.txs[hash] = cond
+	p.conditionals.lock.Unlock()
+
+	// Pool the transaction as remote, as the journal of the local ones would
+	// restore it without its conditional on restart
+	if err := p.AddWithOrigin(origin, []*types.Transaction{tx}, false, false)[0]; err != nil {
+		// A resubmitted conditional transaction is still pooled, keep its
+		// original conditional
+		p.conditionals.lock.Lock()
+		if known && errors.Is(err, ErrAlreadyKnown) {
+			p.conditionals.txs[hash] = prev
+		} else {
+			delete(p.conditionals.txs, hash)
+		}
+		p.conditionals.lock.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Conditional returns the conditional of the transaction with the given hash,
+// nil if the transaction is unconditional.
+func (p *TxPool) Conditional(hash common.Hash) *TransactionConditional {
+	p.conditionals.lock.RLock()
+	defer p.conditionals.lock.RUnlock()
+
+	return p.conditionals.txs[hash]
+}
+
+// expireConditionals drops the conditional transactions whose constraints can't
+// hold anymore after the new head. Transactions no longer pooled, typically
+// since they were included, are simply forgotten.
+func (p *TxPool) expireConditionals(head *types.Header) {
+	var dropped []common.Hash
+
+	p.conditionals.lock.Lock()
+	for hash, cond := range p.conditionals.txs {
+		if !p.Has(hash) {
+			delete(p.conditionals.txs, hash)
+			continue
+		}
+		if cond.Expired(head) {
+			delete(p.conditionals.txs, hash)
+			dropped = append(dropped, hash)
+		}
+	}
+	p.conditionals.lock.Unlock()
+
+	if len(dropped) == 0 {
+		return
+	}
+	p.removeTxs(dropped)
+	conditionalDropMeter.Mark(int64(len(dropped)))
+	log.Debug("Dropped expired conditional transactions", "number", head.Number, "dropped", len(dropped))
+}
@@ -0,0 +1,60 @@
+package txpool
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// TxEventType is the kind of a transaction pool lifecycle event.
+type TxEventType string
+
+const (
+	TxEventAdded    TxEventType = "added"    // Transaction accepted into the pool
+	TxEventPromoted TxEventType = "promoted" // Transaction became executable
+	TxEventDemoted  TxEventType = "demoted"  // Transaction became non-executable, see the reason
+	TxEventReplaced TxEventType = "replaced" // Transaction replaced by a same-nonce one, see the replacement
+	TxEventDropped  TxEventType = "dropped"  // Transaction evicted from the pool, see the reason
+	TxEventIncluded TxEventType = "included" // Transaction included in the chain
+)
+
+// DropReason is the cause of a transaction being dropped or demoted.
+type DropReason string
+
+const (
+	DropUnderpriced DropReason = "underpriced" // Evicted by better priced transactions or a raised tip threshold
+	DropUnpayable   DropReason = "unpayable"   // Sender can't afford the cost or the gas exceeds the block limit
+	DropNonceGap    DropReason = "nonce-gap"   // A preceding transaction of the sender was removed
+	DropNonceUsed   DropReason = "nonce-used"  // Nonce consumed on chain by another transaction
+	DropPoolLimit   DropReason = "pool-limit"  // Account or global pool limits exceeded
+	DropExpired     DropReason = "expired"     // Queued for longer than the pool lifetime
+	DropReorg       DropReason = "reorg"       // Reorged out of the chain and not readmitted into the pool
+	DropRemoved     DropReason = "removed"     // Explicitly removed from the pool
+)
+
+// TxPoolEvent is a lifecycle event of a pooled transaction.
+type TxPoolEvent struct {
+	Type        TxEventType  `json:"type"`
+	Hash        common.Hash  `json:"hash"`
+	Replacement *common.Hash `json:"replacement,omitempty"` // Hash of the replacing transaction, if replaced
+	Reason      DropReason   `json:"reason,omitempty"`      // Reason of the drop or demotion
+}
+
+// EventSubPool is implemented by the subpools reporting the lifecycle events of
+// their transactions.
+type EventSubPool interface {
+	// SubscribeEvents subscribes to the lifecycle events of the transactions
+	// tracked by the subpool, delivered in batches.
+	SubscribeEvents(ch chan<- []TxPoolEvent) event.Subscription
+}
+
+// SubscribeEvents subscribes to the lifecycle events of the pooled transactions,
+// delivered in batches. Only the subpools implementing EventSubPool report them.
+func (p *TxPool) SubscribeEvents(ch chan<- []TxPoolEvent) event.Subscription {
+	var subs []event.Subscription
+	for _, subpool := range p.subpools {
+		if subpool, ok := subpool.(EventSubPool); ok {
+			subs = append(subs, subpool.SubscribeEvents(ch))
+		}
+	}
+	return p.subs.Track(event.JoinSubscriptions(subs...))
+}
@@ -0,0 +1,145 @@
+package txpool
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// exportImportBatch is the number of transactions injected into the pool at
+// once when importing an export.
+const exportImportBatch = 1024
+
+// ExportedTx is a pooled transaction in the export format, a stream of RLP
+// encoded entries.
+type ExportedTx struct {
+	Sender  common.Address     // Sender of the transaction
+	Time    uint64             // Time the transaction was first seen, in unix nanoseconds
+	Local   bool               // Whether the sender is local to the exporting pool
+	Pending bool               // Whether the transaction was executable in the exporting pool
+	Tx      *types.Transaction // Transaction, along with its blob sidecar if any
+}
+
+// Export streams the contents of all subpools, pending and queued transactions
+// alike, into the writer, ordered by sender and nonce. Private and conditional
+// transactions are not exported. It returns the number of transactions exported.
+func (p *TxPool) Export(w io.Writer) (int, error) {
+	locals := make(map[common.Address]struct{})
+	for _, addr := range p.Locals() {
+		locals[addr] = struct{}{}
+	}
+	pending, queued := p.Content()
+	for addr, txs := range p.blobContent() {
+		pending[addr] = append(pending[addr], txs...)
+	}
+	var count int
+	export := func(content map[common.Address][]*types.Transaction, executable bool) error {
+		addrs := make([]common.Address, 0, len(content))
+		for addr := range content {
+			addrs = append(addrs, addr)
+		}
+		sort.Slice(addrs, func(i, j int) bool { return addrs[i].Cmp(addrs[j]) < 0 })
+
+		for _, addr := range addrs {
+			_, local := locals[addr]
+			for _, tx := range content[addr] {
+				// Private and conditional transactions would be imported without
+				// their policies, leave them out
+				if p.IsPrivate(tx.Hash()) || p.Conditional(tx.Hash()) != nil {
+					continue
+				}
+				entry := &ExportedTx{
+					Sender:  addr,
+					Time:    uint64(tx.Time().UnixNano()),
+					Local:   local,
+					Pending: executable,
+					Tx:      tx,
+				}
+				if err := rlp.Encode(w, entry); err != nil {
+					return err
+				}
+				count++
+			}
+		}
+		return nil
+	}
+	if err := export(pending, true); err != nil {
+		return count, err
+	}
+	if err := export(queued, false); err != nil {
+		return count, err
+	}
+	return count, nil
+}
+
+// Import replays the transactions of an export through the regular validation
+// of the pool, retaining their original first seen times. It returns the number
+// of transactions read from the export and the number of them rejected.
+func (p *TxPool) Import(r io.Reader) (int, int, error) {
+	var (
+		stream  = rlp.NewStream(r, 0)
+		total   int
+		dropped int
+
+		locals, remotes []*types.Transaction
+	)
+	flush := func(txs []*types.Transaction, local bool) {
+		for i, err := range p.Add(txs, local, true) {
+			if err != nil {
+				log.Trace("Failed to import transaction", "hash", txs[i].Hash(), "err", err)
+				dropped++
+			}
+		}
+	}
+	for {
+		entry := new(ExportedTx)
+		if err := stream.Decode(entry); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return total, dropped, fmt.Errorf("transaction %d: failed to parse: %v", total, err)
+		}
+		total++
+		entry.Tx.SetTime(time.Unix(0, int64(entry.Time)))
+
+		if entry.Local {
+			if locals = append(locals, entry.Tx); len(locals) >= exportImportBatch {
+				flush(locals, true)
+				locals = locals[:0]
+			}
+		} else {
+			if remotes = append(remotes, entry.Tx); len(remotes) >= exportImportBatch {
+				flush(remotes, false)
+				remotes = remotes[:0]
+			}
+		}
+	}
+	if len(locals) > 0 {
+		flush(locals, true)
+	}
+	if len(remotes) > 0 {
+		flush(remotes, false)
+	}
+	return total, dropped, nil
+}
+
+// blobContent returns the pending blob transactions along with their sidecars,
+// as the blob pool doesn't report its contents.
+func (p *TxPool) blobContent() map[common.Address][]*types.Transaction {
+	content := make(map[common.Address][]*types.Transaction)
+	for addr, lazies := range p.Pending(PendingFilter{OnlyBlobTxs: true}) {
+		for _, lazy := range lazies {
+			if tx := lazy.Resolve(); tx != nil {
+				content[addr] = append(content[addr], tx)
+			}
+		}
+	}
+	return content
+}
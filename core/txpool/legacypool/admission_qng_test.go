@@ -0,0 +1,71 @@
+package legacypool
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tests that the admission rules of the pool are enforced and hot-reloadable.
+func TestPoolAdmission(t *testing.T) {
+	pool, _, keys := setupTxPool(t, 3)
+	defer pool.Close()
+
+	spammer, denied, other := keys[0], keys[1], keys[2]
+
+	pool.SetAdmission(txpool.AdmissionConfig{
+		MaxPerSender: 2,
+		OriginRate:   0.001,
+		OriginBurst:  2,
+		Deny:         []common.Address{crypto.PubkeyToAddress(denied.PublicKey)},
+	})
+	code := func(err error) string {
+		var aerr *txpool.AdmissionError
+		if errors.As(err, &aerr) {
+			return aerr.Code
+		}
+		return ""
+	}
+	// The sender limit must also account for the transactions of the same batch
+	errs := pool.Add([]*types.Transaction{transaction(0, 100000, spammer), transaction(1, 100000, spammer), transaction(2, 100000, spammer)}, false, true)
+	if errs[0] != nil || errs[1] != nil || code(errs[2]) != txpool.AdmissionSenderLimit {
+		t.Fatalf("sender limit not enforced: %v", errs)
+	}
+	if err := pool.Add([]*types.Transaction{transaction(2, 100000, spammer)}, false, true)[0]; code(err) != txpool.AdmissionSenderLimit {
+		t.Fatalf("sender limit not enforced across batches: %v", err)
+	}
+	// Fee bumps of the pooled nonces don't count toward the sender limit
+	if err := pool.Add([]*types.Transaction{pricedTransaction(1, 100000, big.NewInt(2), spammer)}, false, true)[0]; err != nil {
+		t.Fatalf("replacement of a pooled nonce rejected: %v", err)
+	}
+	if err := pool.Add([]*types.Transaction{transaction(0, 100000, denied)}, false, true)[0]; code(err) != txpool.AdmissionSenderDenied {
+		t.Fatalf("denied sender admitted: %v", err)
+	}
+	// Origins are rate limited independently, unknown origins not at all
+	errs = pool.AddWithOrigin("10.0.0.1", []*types.Transaction{transaction(0, 100000, other), transaction(1, 100000, other), transaction(2, 100000, other)}, true, true)
+	if code(errs[0]) != "" || code(errs[1]) != "" || code(errs[2]) != txpool.AdmissionRateLimited {
+		t.Fatalf("origin rate limit not enforced: %v", errs)
+	}
+	if err := pool.AddWithOrigin("10.0.0.2", []*types.Transaction{transaction(0, 100000, newTestKey())}, true, true)[0]; code(err) == txpool.AdmissionRateLimited {
+		t.Fatalf("independent origin rate limited: %v", err)
+	}
+	// Reloading the rules must take effect immediately
+	pool.SetAdmission(txpool.AdmissionConfig{Allow: []common.Address{crypto.PubkeyToAddress(denied.PublicKey)}})
+	if err := pool.Add([]*types.Transaction{transaction(0, 100000, denied)}, false, true)[0]; err != nil {
+		t.Fatalf("allowed sender rejected: %v", err)
+	}
+	if err := pool.Add([]*types.Transaction{transaction(2, 100000, spammer)}, false, true)[0]; code(err) != txpool.AdmissionSenderNotAllowed {
+		t.Fatalf("sender missing from allow list admitted: %v", err)
+	}
+	if err := pool.AddWithOrigin("10.0.0.1", []*types.Transaction{transaction(1, 100000, denied)}, true, true)[0]; err != nil {
+		t.Fatalf("rate limit not reset on reload: %v", err)
+	}
+	if have := pool.Admission(); len(have.Allow) != 1 || have.MaxPerSender != 0 {
+		t.Fatalf("admission rules mismatch: %+v", have)
+	}
+}
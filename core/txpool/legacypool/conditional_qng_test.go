@@ -0,0 +1,108 @@
+package legacypool
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Tests that transaction conditionals are decoded in both known account forms.
+func TestConditionalDecoding(t *testing.T) {
+	var cond txpool.TransactionConditional
+	input := `{
+		"knownAccounts": {
+			"0x0000000000000000000000000000000000000001": "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+			"0x0000000000000000000000000000000000000002": {"0x0000000000000000000000000000000000000000000000000000000000000000": "0x0000000000000000000000000000000000000000000000000000000000000001"}
+		},
+		"blockNumberMax": "0x10"
+	}`
+	if err := json.Unmarshal([]byte(input), &cond); err != nil {
+		t.Fatalf("failed to decode conditional: %v", err)
+	}
+	if root := cond.KnownAccounts[common.BytesToAddress([]byte{1})].StorageRoot; root == nil || *root != types.EmptyRootHash {
+		t.Fatalf("storage root mismatch: have %v, want %v", root, types.EmptyRootHash)
+	}
+	if slots := cond.KnownAccounts[common.BytesToAddress([]byte{2})].StorageSlots; len(slots) != 1 || slots[common.Hash{}] != common.BigToHash(common.Big1) {
+		t.Fatalf("storage slots mismatch: have %v", slots)
+	}
+	if cond.BlockNumberMax == nil || *cond.BlockNumberMax != 0x10 || cond.Cost() != 2 {
+		t.Fatalf("conditional mismatch: %+v", cond)
+	}
+	if err := json.Unmarshal([]byte(`{"knownAccounts": {"0x0000000000000000000000000000000000000001": 1}}`), &cond); err == nil {
+		t.Fatalf("invalid known account accepted")
+	}
+}
+
+// Tests that conditional transactions are dropped once their conditional can't
+// hold anymore.
+func TestConditionalExpiry(t *testing.T) {
+	pool, chain, keys := setupTxPool(t, 2)
+	defer pool.Close()
+
+	expiring, lasting := keys[0], keys[1]
+
+	var (
+		one, three = hexutil.Uint64(1), hexutil.Uint64(3)
+		txExpiring = transaction(0, 100000, expiring)
+		txLasting  = transaction(0, 100000, lasting)
+	)
+	if err := pool.AddConditional("", txExpiring, &txpool.TransactionConditional{BlockNumberMax: &one}); err != nil {
+		t.Fatalf("failed to add conditional transaction: %v", err)
+	}
+	if err := pool.AddConditional("", txLasting, &txpool.TransactionConditional{BlockNumberMax: &three}); err != nil {
+		t.Fatalf("failed to add conditional transaction: %v", err)
+	}
+	if err := pool.AddConditional("", transaction(1, 100000, lasting), &txpool.TransactionConditional{BlockNumberMin: &three, BlockNumberMax: &one}); err == nil {
+		t.Fatalf("empty block range accepted")
+	}
+	if cond := pool.Conditional(txExpiring.Hash()); cond == nil || *cond.BlockNumberMax != one {
+		t.Fatalf("conditional not tracked: %v", cond)
+	}
+	head := &types.Header{ParentHash: chain.CurrentBlock().Hash(), Number: big.NewInt(1), GasLimit: chain.CurrentBlock().GasLimit, BaseFee: common.Big1}
+	chain.chainHeadFeed.Send(core.ChainHeadEvent{Block: types.NewBlock(head, nil, nil, trie.NewStackTrie(nil))})
+
+	for deadline := time.Now().Add(time.Second); pool.Has(txExpiring.Hash()); {
+		if time.Now().After(deadline) {
+			t.Fatalf("expired conditional transaction not dropped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if pool.Conditional(txExpiring.Hash()) != nil {
+		t.Fatalf("expired conditional still tracked")
+	}
+	if !pool.Has(txLasting.Hash()) || pool.Conditional(txLasting.Hash()) == nil {
+		t.Fatalf("live conditional transaction dropped")
+	}
+}
+
+// Tests that resubmitting a pooled conditional transaction keeps its original
+// conditional.
+func TestConditionalResubmit(t *testing.T) {
+	pool, _, keys := setupTxPool(t, 1)
+	defer pool.Close()
+
+	key := keys[0]
+
+	var (
+		three, five = hexutil.Uint64(3), hexutil.Uint64(5)
+		tx          = transaction(0, 100000, key)
+	)
+	if err := pool.AddConditional("", tx, &txpool.TransactionConditional{BlockNumberMax: &three}); err != nil {
+		t.Fatalf("failed to add conditional transaction: %v", err)
+	}
+	if err := pool.AddConditional("", tx, &txpool.TransactionConditional{BlockNumberMax: &five}); !errors.Is(err, txpool.ErrAlreadyKnown) {
+		t.Fatalf("resubmission error mismatch: have %v, want %v", err, txpool.ErrAlreadyKnown)
+	}
+	if cond := pool.Conditional(tx.Hash()); cond == nil || *cond.BlockNumberMax != three {
+		t.Fatalf("conditional mismatch after resubmission: %v", cond)
+	}
+}
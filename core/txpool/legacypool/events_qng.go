@@ -0,0 +1,86 @@
+package legacypool
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// SubscribeEvents implements txpool.EventSubPool, subscribing to the lifecycle
+// events of the pooled transactions.
+func (pool *LegacyPool) SubscribeEvents(ch chan<- []txpool.TxPoolEvent) event.Subscription {
+	return pool.eventScope.Track(pool.eventFeed.Subscribe(ch))
+}
+
+// emit queues a lifecycle event for delivery on the next flush. Events are only
+// tracked while there are subscribers.
+func (pool *LegacyPool) emit(typ txpool.TxEventType, hash common.Hash, reason txpool.DropReason) {
+	if pool.eventScope.Count() == 0 {
+		return
+	}
+	pool.eventsMu.Lock()
+	pool.events = append(pool.events, txpool.TxPoolEvent{Type: typ, Hash: hash, Reason: reason})
+	pool.eventsMu.Unlock()
+}
+
+// emitDrops queues a drop event with the same reason for all the transactions.
+func (pool *LegacyPool) emitDrops(txs types.Transactions, reason txpool.DropReason) {
+	for _, tx := range txs {
+		pool.emit(txpool.TxEventDropped, tx.Hash(), reason)
+	}
+}
+
+// emitReplaced queues the replacement of a transaction by a same-nonce one.
+func (pool *LegacyPool) emitReplaced(old *types.Transaction, replacement common.Hash) {
+	if pool.eventScope.Count() == 0 {
+		return
+	}
+	pool.eventsMu.Lock()
+	pool.events = append(pool.events, txpool.TxPoolEvent{Type: txpool.TxEventReplaced, Hash: old.Hash(), Replacement: &replacement})
+	pool.eventsMu.Unlock()
+}
+
+// emitStales queues the events of transactions whose nonce was consumed on
+// chain, either by their inclusion or by another transaction. The caller must
+// hold the pool lock.
+func (pool *LegacyPool) emitStales(txs types.Transactions) {
+	for _, tx := range txs {
+		if _, ok := pool.included[tx.Hash()]; ok {
+			pool.emit(txpool.TxEventIncluded, tx.Hash(), "")
+		} else {
+			pool.emit(txpool.TxEventDropped, tx.Hash(), txpool.DropNonceUsed)
+		}
+	}
+}
+
+// flushEvents delivers the queued lifecycle events to the subscribers. It must
+// not be called with the pool lock held, as delivery may block.
+func (pool *LegacyPool) flushEvents() {
+	pool.eventsMu.Lock()
+	events := pool.events
+	pool.events = nil
+	pool.eventsMu.Unlock()
+
+	if len(events) > 0 {
+		pool.eventFeed.Send(events)
+	}
+}
+
+// trackIncluded records the transactions included by the chain segment the pool
+// is being reset to, for the stale transactions to be reported as included. The
+// caller must hold the pool lock.
+func (pool *LegacyPool) trackIncluded(oldHead, newHead *types.Header, included types.Transactions) {
+	pool.included = make(map[common.Hash]struct{})
+	if pool.eventScope.Count() == 0 || newHead == nil {
+		return
+	}
+	if oldHead != nil && oldHead.Hash() == newHead.ParentHash {
+		if block := pool.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64()); block != nil {
+			included = block.Transactions()
+		}
+	}
+	for _, tx := range included {
+		pool.included[tx.Hash()] = struct{}{}
+	}
+}
@@ -0,0 +1,82 @@
+package legacypool
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tests that the lifecycle events of the pooled transactions are reported along
+// with the replacements and drop reasons.
+func TestPoolEvents(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	events := make(chan []txpool.TxPoolEvent, 16)
+	sub := pool.SubscribeEvents(events)
+	defer sub.Unsubscribe()
+
+	var (
+		tx0  = pricedTransaction(0, 100000, big.NewInt(1), key)
+		tx2  = pricedTransaction(2, 100000, big.NewInt(1), key)
+		repl = pricedTransaction(0, 100000, big.NewInt(2), key)
+	)
+	expect := func(want ...txpool.TxPoolEvent) {
+		t.Helper()
+
+		var have []txpool.TxPoolEvent
+		for len(have) < len(want) {
+			select {
+			case batch := <-events:
+				have = append(have, batch...)
+			case <-time.After(time.Second):
+				t.Fatalf("event timeout: have %d, want %d", len(have), len(want))
+			}
+		}
+		if len(have) != len(want) {
+			t.Fatalf("event count mismatch: have %v, want %v", have, want)
+		}
+		for i := range want {
+			if have[i].Type != want[i].Type || have[i].Hash != want[i].Hash || have[i].Reason != want[i].Reason {
+				t.Fatalf("event %d mismatch: have %+v, want %+v", i, have[i], want[i])
+			}
+			if (have[i].Replacement == nil) != (want[i].Replacement == nil) || (have[i].Replacement != nil && *have[i].Replacement != *want[i].Replacement) {
+				t.Fatalf("event %d replacement mismatch: have %v, want %v", i, have[i].Replacement, want[i].Replacement)
+			}
+		}
+	}
+	if err := pool.addRemoteSync(tx0); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	expect(txpool.TxPoolEvent{Type: txpool.TxEventAdded, Hash: tx0.Hash()}, txpool.TxPoolEvent{Type: txpool.TxEventPromoted, Hash: tx0.Hash()})
+
+	if err := pool.addRemoteSync(tx2); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	expect(txpool.TxPoolEvent{Type: txpool.TxEventAdded, Hash: tx2.Hash()})
+
+	if err := pool.addRemoteSync(repl); err != nil {
+		t.Fatalf("failed to replace transaction: %v", err)
+	}
+	replacement := repl.Hash()
+	expect(txpool.TxPoolEvent{Type: txpool.TxEventReplaced, Hash: tx0.Hash(), Replacement: &replacement}, txpool.TxPoolEvent{Type: txpool.TxEventAdded, Hash: repl.Hash()})
+
+	pool.RemoveTx(repl.Hash(), true)
+	expect(txpool.TxPoolEvent{Type: txpool.TxEventDropped, Hash: repl.Hash(), Reason: txpool.DropRemoved})
+
+	pool.SetGasTip(big.NewInt(2))
+	expect(txpool.TxPoolEvent{Type: txpool.TxEventDropped, Hash: tx2.Hash(), Reason: txpool.DropUnderpriced})
+
+	select {
+	case batch := <-events:
+		t.Fatalf("unexpected events: %v", batch)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,84 @@
+package legacypool
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tests that the pool contents survive an export and import round trip along
+// with their first seen times.
+func TestPoolExportImport(t *testing.T) {
+	source, chain, keys := setupTxPool(t, 2)
+	defer source.Close()
+
+	local, remote := keys[0], keys[1]
+
+	seen := time.Unix(1_700_000_000, 0)
+	txs := []*types.Transaction{transaction(0, 100000, local), transaction(1, 100000, local), transaction(0, 100000, remote), transaction(5, 100000, remote)}
+	for i, tx := range txs {
+		tx.SetTime(seen.Add(time.Duration(i) * time.Second))
+	}
+	if errs := source.Add(txs[:2], true, true); errs[0] != nil || errs[1] != nil {
+		t.Fatalf("failed to add local transactions: %v", errs)
+	}
+	if errs := source.Add(txs[2:], false, true); errs[0] != nil || errs[1] != nil {
+		t.Fatalf("failed to add remote transactions: %v", errs)
+	}
+	var buf bytes.Buffer
+	count, err := source.Export(&buf)
+	if err != nil {
+		t.Fatalf("failed to export pool: %v", err)
+	}
+	if count != len(txs) {
+		t.Fatalf("exported transaction count mismatch: have %d, want %d", count, len(txs))
+	}
+	// Check the export entries, pending ones first
+	stream := rlp.NewStream(bytes.NewReader(buf.Bytes()), 0)
+	for i := 0; i < count; i++ {
+		entry := new(txpool.ExportedTx)
+		if err := stream.Decode(entry); err != nil {
+			t.Fatalf("entry %d: failed to decode: %v", i, err)
+		}
+		if want := entry.Tx.Nonce() != 5; entry.Pending != want {
+			t.Errorf("entry %d: pending mismatch: have %v, want %v", i, entry.Pending, want)
+		}
+		if want := entry.Sender == crypto.PubkeyToAddress(local.PublicKey); entry.Local != want {
+			t.Errorf("entry %d: local mismatch: have %v, want %v", i, entry.Local, want)
+		}
+	}
+	// Import into a fresh pool and ensure the contents match
+	target := newTestTxPool(t, newTestBlockChain(params.TestChainConfig, 10000000, chain.statedb.Copy(), new(event.Feed)))
+	defer target.Close()
+
+	total, dropped, err := target.Import(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to import pool: %v", err)
+	}
+	if total != len(txs) || dropped != 0 {
+		t.Fatalf("import mismatch: have %d/%d, want %d/0", total, dropped, len(txs))
+	}
+	pending, queued := target.Stats()
+	if pending != 3 || queued != 1 {
+		t.Fatalf("imported pool stats mismatch: have %d/%d, want 3/1", pending, queued)
+	}
+	for _, tx := range txs {
+		imported := target.Get(tx.Hash())
+		if imported == nil {
+			t.Fatalf("transaction %x not imported", tx.Hash())
+		}
+		if !imported.Time().Equal(tx.Time()) {
+			t.Errorf("transaction %x first seen time mismatch: have %v, want %v", tx.Hash(), imported.Time(), tx.Time())
+		}
+	}
+	if locals := target.Locals(); len(locals) != 1 || locals[0] != crypto.PubkeyToAddress(local.PublicKey) {
+		t.Fatalf("imported locals mismatch: %v", locals)
+	}
+}
@@ -2,13 +2,18 @@ package legacypool
 
 import (
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
 func (pool *LegacyPool) RemoveTx(hash common.Hash, outofbound bool) {
+	defer pool.flushEvents()
+
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
-	pool.removeTx(hash, outofbound, true)
+	if pool.removeTx(hash, outofbound, true) > 0 {
+		pool.emit(txpool.TxEventDropped, hash, txpool.DropRemoved)
+	}
 }
 
 func (pool *LegacyPool) All() *lookup {
@@ -173,6 +173,38 @@ func setupPoolWithConfig(config *params.ChainConfig) (*LegacyPool, *ecdsa.Privat
 	return pool, key
 }
 
+// setupTxPool creates a transaction pool wrapping a legacy pool, along with the
+// given number of keys whose accounts are funded with one ether each.
+func setupTxPool(t *testing.T, accounts int) (*txpool.TxPool, *testBlockChain, []*ecdsa.PrivateKey) {
+	t.Helper()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	keys := make([]*ecdsa.PrivateKey, accounts)
+	for i := range keys {
+		keys[i] = newTestKey()
+		statedb.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	}
+	chain := newTestBlockChain(params.TestChainConfig, 10000000, statedb, new(event.Feed))
+	return newTestTxPool(t, chain), chain, keys
+}
+
+// newTestTxPool creates a transaction pool wrapping a legacy pool on top of the
+// given chain.
+func newTestTxPool(t *testing.T, chain *testBlockChain) *txpool.TxPool {
+	t.Helper()
+
+	pool, err := txpool.New(testTxPoolConfig.PriceLimit, chain, []txpool.SubPool{New(testTxPoolConfig, chain)})
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	return pool
+}
+
+func newTestKey() *ecdsa.PrivateKey {
+	key, _ := crypto.GenerateKey()
+	return key
+}
+
 // validatePoolInternals checks various consistency invariants within the pool.
 func validatePoolInternals(pool *LegacyPool) error {
 	pool.mu.RLock()
@@ -40,7 +40,7 @@ func TestStrictListAdd(t *testing.T) {
 	// Insert the transactions in a random order
 	list := newList(true)
 	for _, v := range rand.Perm(len(txs)) {
-		list.Add(txs[v], DefaultConfig.PriceBump)
+		list.Add(txs[v], DefaultConfig.Replacement.WithDefaults(DefaultConfig.PriceBump))
 	}
 	// Verify internal state
 	if len(list.txs.items) != len(txs) {
@@ -64,7 +64,7 @@ func TestListAddVeryExpensive(t *testing.T) {
 		gaslimit := uint64(i)
 		tx, _ := types.SignTx(types.NewTransaction(uint64(i), common.Address{}, value, gaslimit, gasprice, nil), types.HomesteadSigner{}, key)
 		t.Logf("cost: %x bitlen: %d\n", tx.Cost(), tx.Cost().BitLen())
-		list.Add(tx, DefaultConfig.PriceBump)
+		list.Add(tx, DefaultConfig.Replacement.WithDefaults(DefaultConfig.PriceBump))
 	}
 }
 
@@ -82,7 +82,7 @@ func BenchmarkListAdd(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		list := newList(true)
 		for _, v := range rand.Perm(len(txs)) {
-			list.Add(txs[v], DefaultConfig.PriceBump)
+			list.Add(txs[v], DefaultConfig.Replacement.WithDefaults(DefaultConfig.PriceBump))
 			list.Filter(priceLimit, DefaultConfig.PriceBump)
 		}
 	}
@@ -102,7 +102,7 @@ func BenchmarkListCapOneTx(b *testing.B) {
 		list := newList(true)
 		// Insert the transactions in a random order
 		for _, v := range rand.Perm(len(txs)) {
-			list.Add(txs[v], DefaultConfig.PriceBump)
+			list.Add(txs[v], DefaultConfig.Replacement.WithDefaults(DefaultConfig.PriceBump))
 		}
 		b.StartTimer()
 		list.Cap(list.Len() - 1)
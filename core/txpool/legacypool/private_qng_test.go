@@ -0,0 +1,109 @@
+package legacypool
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Tests that private transactions are withheld from the new transaction events
+// fed to the network, and dropped or released once they expire.
+func TestPrivateTransactions(t *testing.T) {
+	pool, chain, keys := setupTxPool(t, 2)
+	defer pool.Close()
+
+	dropped, released := keys[0], keys[1]
+
+	txs := make(chan core.NewTxsEvent, 4)
+	sub := pool.SubscribeTransactions(txs, false)
+	defer sub.Unsubscribe()
+
+	var (
+		txDropped  = transaction(0, 100000, dropped)
+		txReleased = transaction(0, 100000, released)
+	)
+	if err := pool.AddPrivate("", txDropped, 2, false); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	if err := pool.AddPrivate("", txReleased, 2, true); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	if err := pool.AddPrivate("", transaction(1, 100000, dropped), 0, false); err != txpool.ErrPrivateExpired {
+		t.Fatalf("expired private transaction error mismatch: have %v, want %v", err, txpool.ErrPrivateExpired)
+	}
+	if !pool.IsPrivate(txDropped.Hash()) || !pool.IsPrivate(txReleased.Hash()) {
+		t.Fatalf("transactions not marked private")
+	}
+	if pool.IsPrivate(transaction(1, 100000, dropped).Hash()) {
+		t.Fatalf("rejected transaction marked private")
+	}
+	// Private transactions are promoted as usual, the network layer withholds them
+	for promoted := 0; promoted < 2; {
+		select {
+		case ev := <-txs:
+			promoted += len(ev.Txs)
+		case <-time.After(time.Second):
+			t.Fatalf("private transactions not promoted")
+		}
+	}
+	// Reach the max block, expecting the private transactions to be dropped or
+	// released to the network
+
+	head := &types.Header{ParentHash: chain.CurrentBlock().Hash(), Number: big.NewInt(2), GasLimit: chain.CurrentBlock().GasLimit, BaseFee: common.Big1}
+	chain.chainHeadFeed.Send(core.ChainHeadEvent{Block: types.NewBlock(head, nil, nil, trie.NewStackTrie(nil))})
+
+	select {
+	case ev := <-txs:
+		if len(ev.Txs) != 1 || ev.Txs[0].Hash() != txReleased.Hash() {
+			t.Fatalf("released transactions mismatch: have %d, want %x", len(ev.Txs), txReleased.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("private transaction not released")
+	}
+	if pool.IsPrivate(txDropped.Hash()) || pool.IsPrivate(txReleased.Hash()) {
+		t.Fatalf("expired transactions still private")
+	}
+	if pool.Has(txDropped.Hash()) {
+		t.Fatalf("expired private transaction not dropped")
+	}
+	if !pool.Has(txReleased.Hash()) {
+		t.Fatalf("released private transaction dropped")
+	}
+}
+
+// Tests that resubmitting a pooled private transaction keeps it private.
+func TestPrivateTransactionResubmit(t *testing.T) {
+	pool, _, keys := setupTxPool(t, 1)
+	defer pool.Close()
+
+	key := keys[0]
+
+	tx := transaction(0, 100000, key)
+	if err := pool.AddPrivate("", tx, 2, false); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	if err := pool.AddPrivate("", tx, 2, false); !errors.Is(err, txpool.ErrAlreadyKnown) {
+		t.Fatalf("resubmission error mismatch: have %v, want %v", err, txpool.ErrAlreadyKnown)
+	}
+	if !pool.IsPrivate(tx.Hash()) {
+		t.Fatalf("resubmitted transaction not private anymore")
+	}
+	// Submitting a public transaction privately doesn't hide it anymore
+	public := transaction(1, 100000, key)
+	if err := pool.Add([]*types.Transaction{public}, false, true)[0]; err != nil {
+		t.Fatalf("failed to add public transaction: %v", err)
+	}
+	if err := pool.AddPrivate("", public, 2, false); !errors.Is(err, txpool.ErrAlreadyKnown) {
+		t.Fatalf("resubmission error mismatch: have %v, want %v", err, txpool.ErrAlreadyKnown)
+	}
+	if pool.IsPrivate(public.Hash()) {
+		t.Fatalf("public transaction marked private")
+	}
+}
@@ -0,0 +1,26 @@
+package legacypool
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Replacement implements txpool.ReplacementSubPool, returning the pooled
+// transaction the given one would replace and the minimum fees to replace it.
+func (pool *LegacyPool) Replacement(from common.Address, tx *types.Transaction) (common.Hash, *txpool.ReplacementFees, bool) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	var old *types.Transaction
+	if list := pool.pending[from]; list != nil {
+		old = list.txs.Get(tx.Nonce())
+	}
+	if list := pool.queue[from]; old == nil && list != nil {
+		old = list.txs.Get(tx.Nonce())
+	}
+	if old == nil {
+		return common.Hash{}, nil, false
+	}
+	return old.Hash(), pool.config.Replacement.MinFees(old.GasTipCap(), old.GasFeeCap(), nil, txpool.RecipientChanged(old, tx)), true
+}
@@ -0,0 +1,99 @@
+package legacypool
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// Tests that the replacement policy bumps are enforced separately, and that the
+// replacement simulation reports the same outcome and minimum fees.
+func TestReplacementPolicy(t *testing.T) {
+	key := newTestKey()
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+
+	config := testTxPoolConfig
+	config.Replacement = txpool.ReplacementPolicy{
+		TipBump:       10,
+		FeeCapBump:    20,
+		MinBump:       big.NewInt(50),
+		RecipientBump: 100,
+	}
+	chain := newTestBlockChain(params.TestChainConfig, 10000000, statedb, new(event.Feed))
+	pool, err := txpool.New(config.PriceLimit, chain, []txpool.SubPool{New(config, chain)})
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	send := func(feeCap, tip int64, to common.Address) *types.Transaction {
+		tx, _ := types.SignNewTx(key, types.LatestSignerForChainID(params.TestChainConfig.ChainID), &types.DynamicFeeTx{
+			ChainID:   params.TestChainConfig.ChainID,
+			Nonce:     0,
+			GasTipCap: big.NewInt(tip),
+			GasFeeCap: big.NewInt(feeCap),
+			Gas:       100000,
+			To:        &to,
+		})
+		return tx
+	}
+	original := send(1000, 1000, common.Address{1})
+	if err := pool.Add([]*types.Transaction{original}, false, true)[0]; err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if res, err := pool.SimulateReplacement(original); err != nil || !res.Replaceable || res.Replaces {
+		t.Fatalf("duplicate simulation mismatch: %+v, %v", res, err)
+	}
+	tests := []struct {
+		feeCap, tip int64
+		to          common.Address
+		replaces    bool
+		minFeeCap   int64
+		minTip      int64
+	}{
+		{1199, 1100, common.Address{1}, false, 1200, 1100}, // fee cap bump not met
+		{1200, 1099, common.Address{1}, false, 1200, 1100}, // tip bump not met
+		{1999, 1999, common.Address{2}, false, 2000, 2000}, // recipient bump not met
+		{1200, 1100, common.Address{1}, true, 1200, 1100},  // regular bumps met
+	}
+	for i, tt := range tests {
+		tx := send(tt.feeCap, tt.tip, tt.to)
+		res, err := pool.SimulateReplacement(tx)
+		if err != nil {
+			t.Fatalf("test %d: failed to simulate replacement: %v", i, err)
+		}
+		if !res.Replaceable || *res.Current != original.Hash() || res.Replaces != tt.replaces {
+			t.Fatalf("test %d: simulation mismatch: %+v", i, res)
+		}
+		if res.MinGasFeeCap.ToInt().Int64() != tt.minFeeCap || res.MinGasTipCap.ToInt().Int64() != tt.minTip {
+			t.Fatalf("test %d: minimum fees mismatch: have %v/%v, want %v/%v", i, res.MinGasFeeCap, res.MinGasTipCap, tt.minFeeCap, tt.minTip)
+		}
+		err = pool.Add([]*types.Transaction{tx}, false, true)[0]
+		if tt.replaces && err != nil {
+			t.Fatalf("test %d: replacement rejected: %v", i, err)
+		}
+		if !tt.replaces && !errors.Is(err, txpool.ErrReplaceUnderpriced) {
+			t.Fatalf("test %d: replacement error mismatch: have %v, want %v", i, err, txpool.ErrReplaceUnderpriced)
+		}
+	}
+	// The absolute bump applies to low fees, where the percentages are negligible
+	policy := txpool.ReplacementPolicy{TipBump: 10, FeeCapBump: 10, MinBump: big.NewInt(50)}
+	if fees := policy.MinFees(big.NewInt(1), big.NewInt(10), big.NewInt(100), false); fees.GasTipCap.Int64() != 51 || fees.GasFeeCap.Int64() != 60 || fees.BlobGasFeeCap.Int64() != 150 {
+		t.Fatalf("absolute bump mismatch: %+v", fees)
+	}
+	if res, _ := pool.SimulateReplacement(dynamicFeeTx(1, 100000, big.NewInt(1), big.NewInt(1), key)); res.Replaceable {
+		t.Fatalf("replacement of a missing nonce reported: %+v", res)
+	}
+}
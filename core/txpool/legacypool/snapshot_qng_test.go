@@ -0,0 +1,102 @@
+package legacypool
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tests that the pool snapshot persists both local and remote transactions
+// across restarts, surviving torn writes and revalidating the restored
+// transactions against the new head.
+func TestPoolSnapshot(t *testing.T) {
+	var (
+		path              = filepath.Join(t.TempDir(), "txpool.rlp")
+		config            = txpool.SnapshotConfig{Path: path, Limit: 1024 * 1024}
+		pool, chain, keys = setupTxPool(t, 2)
+		localKey          = keys[0]
+		remoteKey         = keys[1]
+		local             = crypto.PubkeyToAddress(localKey.PublicKey)
+		remote            = crypto.PubkeyToAddress(remoteKey.PublicKey)
+	)
+	start := func(pool *txpool.TxPool) *txpool.TxPool {
+		if err := pool.EnableSnapshot(config); err != nil {
+			t.Fatalf("failed to enable snapshot: %v", err)
+		}
+		return pool
+	}
+	start(pool)
+	for _, err := range pool.Add([]*types.Transaction{transaction(0, 100000, localKey), transaction(1, 100000, localKey)}, true, true) {
+		if err != nil {
+			t.Fatalf("failed to add local transaction: %v", err)
+		}
+	}
+	for _, err := range pool.Add([]*types.Transaction{transaction(0, 100000, remoteKey), transaction(1, 100000, remoteKey), transaction(3, 100000, remoteKey)}, false, true) {
+		if err != nil {
+			t.Fatalf("failed to add remote transaction: %v", err)
+		}
+	}
+	pool.Close()
+
+	// Simulate a torn write at a crash and the chain progressing while the node
+	// was down, including the first remote transaction
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("failed to open snapshot: %v", err)
+	}
+	file.Write([]byte{0x00, 0x00, 0x01, 0x00, 0xde, 0xad})
+	file.Close()
+	chain.statedb.SetNonce(remote, 1)
+
+	pool = start(newTestTxPool(t, chain))
+	pending, queued := pool.Stats()
+	if pending != 3 || queued != 1 {
+		t.Fatalf("restored transactions mismatch: have %d pending, %d queued, want 3 pending, 1 queued", pending, queued)
+	}
+	if locals := pool.Locals(); len(locals) != 1 || locals[0] != local {
+		t.Fatalf("local accounts mismatch: have %v, want [%v]", locals, local)
+	}
+	// The torn record must have been dropped by the startup compaction
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat snapshot: %v", err)
+	}
+	pool.Close()
+	restarted := start(newTestTxPool(t, chain))
+	defer restarted.Close()
+	if pending, queued := restarted.Stats(); pending != 3 || queued != 1 {
+		t.Fatalf("compacted snapshot mismatch (%d bytes): have %d pending, %d queued", stat.Size(), pending, queued)
+	}
+}
+
+// Tests that the pool snapshot is bounded by its size limit.
+func TestPoolSnapshotLimit(t *testing.T) {
+	var (
+		path          = filepath.Join(t.TempDir(), "txpool.rlp")
+		config        = txpool.SnapshotConfig{Path: path, Limit: 1024}
+		pool, _, keys = setupTxPool(t, 1)
+		key           = keys[0]
+	)
+	if err := pool.EnableSnapshot(config); err != nil {
+		t.Fatalf("failed to enable snapshot: %v", err)
+	}
+	var txs []*types.Transaction
+	for i := 0; i < 64; i++ {
+		txs = append(txs, pricedTransaction(uint64(i), 100000, big.NewInt(1), key))
+	}
+	pool.Add(txs, false, true)
+	pool.Close()
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat snapshot: %v", err)
+	}
+	if uint64(stat.Size()) > config.Limit {
+		t.Fatalf("snapshot exceeds limit: have %d bytes, want at most %d", stat.Size(), config.Limit)
+	}
+}
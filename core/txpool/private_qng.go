@@ -0,0 +1,157 @@
+package txpool
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// maxPrivateTxs is the maximum number of private transactions tracked at once.
+const maxPrivateTxs = 4096
+
+var (
+	// ErrPrivateLimit is returned if too many private transactions are pending.
+	ErrPrivateLimit = errors.New("private transaction limit reached")
+
+	// ErrPrivateExpired is returned if a private transaction is submitted with a
+	// maximum block already reached by the chain.
+	ErrPrivateExpired = errors.New("private transaction max block already reached")
+
+	// ErrPrivateBlobTx is returned if a blob transaction is submitted privately.
+	ErrPrivateBlobTx = errors.New("private blob transactions not supported")
+
+	privateReleaseMeter = metrics.NewRegisteredMeter("txpool/private/released", nil)
+	privateDropMeter    = metrics.NewRegisteredMeter("txpool/private/dropped", nil)
+)
+
+// privateTx is the expiry policy of a private transaction.
+type privateTx struct {
+	maxBlock uint64 // Last block the transaction is kept private for
+	release  bool   // Whether to gossip the transaction on expiry instead of dropping it
+}
+
+// privateSet tracks the pooled transactions excluded from p2p propagation, only
+// available to the local block producer until their expiry.
+type privateSet struct {
+	txs  map[common.Hash]privateTx
+	head atomic.Pointer[types.Header] // Head the expiry policies were last applied at
+	lock sync.RWMutex
+	feed event.Feed // Feed of the transactions released to public gossip
+}
+
+func newPrivateSet(head *types.Header) *privateSet {
+	set := &privateSet{txs: make(map[common.Hash]privateTx)}
+	set.head.Store(head)
+	return set
+}
+
+// AddPrivate enqueues a transaction submitted by the given RPC origin into the
+// pool without propagating it to the network. The transaction remains private
+// until the chain reaches maxBlock, after which it's dropped from the pool, or
+// released to public gossip if requested.
+func (p *TxPool) AddPrivate(origin string, tx *types.Transaction, maxBlock uint64, release bool) error {
+	if tx.Type() == types.BlobTxType {
+		return ErrPrivateBlobTx
+	}
+	if p.private.head.Load().Number.Uint64() >= maxBlock {
+		return ErrPrivateExpired
+	}
+	// Mark the transaction private before pooling it, to not race the broadcast
+	// of the pool's new transaction event
+	hash := tx.Hash()
+
+	p.private.lock.Lock()
+	prev, known := p.private.txs[hash]
+	if !known && len(p.private.txs) >= maxPrivateTxs {
+		p.private.lock.Unlock()
+		return ErrPrivateLimit
+	}
+	p.private.txs[hash] = privateTx{maxBlock: maxBlock, release: release}
+	p.private.lock.Unlock()
+
+	// Pool the transaction as remote, as the journal of the local ones would leak
+	// it into the public pool on restart
+	if err := p.AddWithOrigin(origin, []*types.Transaction{tx}, false, false)[0]; err != nil {
+		// A resubmitted private transaction is still pooled, keep it private
+		p.private.lock.Lock()
+		if known && errors.Is(err, ErrAlreadyKnown) {
+			p.private.txs[hash] = prev
+		} else {
+			delete(p.private.txs, hash)
+		}
+		p.private.lock.Unlock()
+		return err
+	}
+	return nil
+}
+
+// IsPrivate returns whether the transaction with the given hash must not be
+// propagated to the network.
+func (p *TxPool) IsPrivate(hash common.Hash) bool {
+	p.private.lock.RLock()
+	defer p.private.lock.RUnlock()
+
+	_, ok := p.private.txs[hash]
+	return ok
+}
+
+// expirePrivate applies the expiry policy of the private transactions whose
+// maximum block was reached by the new head. Transactions no longer pooled,
+// typically since they were included, are simply forgotten.
+func (p *TxPool) expirePrivate(head *types.Header) {
+	p.private.head.Store(head)
+
+	var (
+		number   = head.Number.Uint64()
+		released types.Transactions
+		dropped  []common.Hash
+	)
+	p.private.lock.Lock()
+	for hash, ptx := range p.private.txs {
+		if !p.Has(hash) {
+			delete(p.private.txs, hash)
+			continue
+		}
+		if number < ptx.maxBlock {
+			continue
+		}
+		delete(p.private.txs, hash)
+		if ptx.release {
+			if tx := p.Get(hash); tx != nil {
+				released = append(released, tx)
+			}
+		} else {
+			dropped = append(dropped, hash)
+		}
+	}
+	p.private.lock.Unlock()
+
+	p.removeTxs(dropped)
+	if len(released) > 0 {
+		p.private.feed.Send(core.NewTxsEvent{Txs: released})
+	}
+	if len(released) > 0 || len(dropped) > 0 {
+		privateReleaseMeter.Mark(int64(len(released)))
+		privateDropMeter.Mark(int64(len(dropped)))
+		log.Debug("Expired private transactions", "number", number, "released", len(released), "dropped", len(dropped))
+	}
+}
+
+// removeTxs drops the transactions with the given hashes from the subpools
+// supporting explicit removal.
+func (p *TxPool) removeTxs(hashes []common.Hash) {
+	for _, hash := range hashes {
+		for _, subpool := range p.subpools {
+			if remover, ok := subpool.(interface{ RemoveTx(common.Hash, bool) }); ok && subpool.Has(hash) {
+				remover.RemoveTx(hash, true)
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package txpool
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ReplacementPolicy defines the fee bumps a transaction must pay to replace a
+// pooled transaction with the same sender and nonce.
+type ReplacementPolicy struct {
+	TipBump       uint64   // Minimum gas tip cap bump percentage
+	FeeCapBump    uint64   // Minimum gas fee cap bump percentage, also applied to the blob fee cap
+	MinBump       *big.Int // Minimum absolute bump of every fee component in wei, nil for none
+	RecipientBump uint64   // Minimum bump percentage of every fee component to change the recipient, 0 for the regular bumps
+}
+
+// WithDefaults returns the policy with the unset percentage bumps defaulting
+// to the given one.
+func (p ReplacementPolicy) WithDefaults(bump uint64) ReplacementPolicy {
+	if p.TipBump == 0 {
+		p.TipBump = bump
+	}
+	if p.FeeCapBump == 0 {
+		p.FeeCapBump = bump
+	}
+	return p
+}
+
+// ReplacementFees are the minimum fees a transaction must pay to replace a
+// pooled one.
+type ReplacementFees struct {
+	GasTipCap     *big.Int
+	GasFeeCap     *big.Int
+	BlobGasFeeCap *big.Int // Only set when replacing a blob transaction
+}
+
+// MinFees returns the minimum fees to replace a transaction paying the given
+// fees, nil blob fee cap for non-blob transactions. The replacement must strictly
+// exceed every fee of the replaced transaction, in addition to the bumps.
+func (p ReplacementPolicy) MinFees(tip, feeCap, blobFeeCap *big.Int, recipientChanged bool) *ReplacementFees {
+	tipBump, feeCapBump := p.TipBump, p.FeeCapBump
+	if recipientChanged && p.RecipientBump > 0 {
+		tipBump, feeCapBump = max(tipBump, p.RecipientBump), max(feeCapBump, p.RecipientBump)
+	}
+	fees := &ReplacementFees{
+		GasTipCap: p.bump(tip, tipBump),
+		GasFeeCap: p.bump(feeCap, feeCapBump),
+	}
+	if blobFeeCap != nil {
+		fees.BlobGasFeeCap = p.bump(blobFeeCap, feeCapBump)
+	}
+	return fees
+}
+
+// bump returns the minimum replacement value of a fee component.
+func (p ReplacementPolicy) bump(fee *big.Int, percent uint64) *big.Int {
+	// threshold = fee * (100 + percent) / 100
+	threshold := new(big.Int).Mul(fee, new(big.Int).SetUint64(100+percent))
+	threshold.Div(threshold, big.NewInt(100))
+
+	// We have to ensure that the new fee is higher than the old one as well, to
+	// be accurate for low (Wei-level) fees
+	if floor := new(big.Int).Add(fee, common.Big1); threshold.Cmp(floor) < 0 {
+		threshold = floor
+	}
+	if p.MinBump != nil {
+		if floor := new(big.Int).Add(fee, p.MinBump); threshold.Cmp(floor) < 0 {
+			threshold = floor
+		}
+	}
+	return threshold
+}
+
+// Check returns an error wrapping ErrReplaceUnderpriced if the transaction pays
+// less than the minimum replacement fees.
+func (f *ReplacementFees) Check(tx *types.Transaction) error {
+	switch {
+	case tx.GasFeeCapIntCmp(f.GasFeeCap) < 0:
+		return fmt.Errorf("%w: new tx gas fee cap %v < %v required", ErrReplaceUnderpriced, tx.GasFeeCap(), f.GasFeeCap)
+	case tx.GasTipCapIntCmp(f.GasTipCap) < 0:
+		return fmt.Errorf("%w: new tx gas tip cap %v < %v required", ErrReplaceUnderpriced, tx.GasTipCap(), f.GasTipCap)
+	case f.BlobGasFeeCap != nil && tx.BlobGasFeeCapIntCmp(f.BlobGasFeeCap) < 0:
+		return fmt.Errorf("%w: new tx blob gas fee cap %v < %v required", ErrReplaceUnderpriced, tx.BlobGasFeeCap(), f.BlobGasFeeCap)
+	}
+	return nil
+}
+
+// RecipientChanged returns whether the replacement transaction has a different
+// recipient than the replaced one, contract creations included.
+func RecipientChanged(old, tx *types.Transaction) bool {
+	if old.To() == nil || tx.To() == nil {
+		return old.To() != tx.To()
+	}
+	return *old.To() != *tx.To()
+}
+
+// ReplacementSubPool is implemented by the subpools able to report their
+// replacement requirements.
+type ReplacementSubPool interface {
+	// Replacement returns the hash of the pooled transaction with the same sender
+	// and nonce as the given one, along with the minimum fees to replace it. The
+	// returned flag is false if there's no such transaction.
+	Replacement(from common.Address, tx *types.Transaction) (common.Hash, *ReplacementFees, bool)
+}
+
+// ReplacementResult is the outcome of a replacement simulation.
+type ReplacementResult struct {
+	Replaceable      bool         `json:"replaceable"`       // Whether a pooled transaction has the same sender and nonce
+	Current          *common.Hash `json:"current,omitempty"` // Hash of the pooled transaction with the same sender and nonce
+	Replaces         bool         `json:"replaces"`          // Whether the candidate pays enough to replace the pooled transaction
+	Reason           string       `json:"reason,omitempty"`  // Why the candidate doesn't replace the pooled transaction
+	MinGasTipCap     *hexutil.Big `json:"minGasTipCap,omitempty"`
+	MinGasFeeCap     *hexutil.Big `json:"minGasFeeCap,omitempty"`
+	MinBlobGasFeeCap *hexutil.Big `json:"minBlobGasFeeCap,omitempty"`
+}
+
+// SimulateReplacement reports whether the transaction would replace the pooled
+// transaction with the same sender and nonce, and the minimum fees required to.
+// The transaction is not added to the pool, nor checked beyond its fees.
+func (p *TxPool) SimulateReplacement(tx *types.Transaction) (*ReplacementResult, error) {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSender, err)
+	}
+	for _, subpool := range p.subpools {
+		subpool, ok := subpool.(ReplacementSubPool)
+		if !ok {
+			continue
+		}
+		current, fees, ok := subpool.Replacement(from, tx)
+		if !ok {
+			continue
+		}
+		result := &ReplacementResult{
+			Replaceable:  true,
+			Current:      &current,
+			MinGasTipCap: (*hexutil.Big)(fees.GasTipCap),
+			MinGasFeeCap: (*hexutil.Big)(fees.GasFeeCap),
+		}
+		if fees.BlobGasFeeCap != nil {
+			result.MinBlobGasFeeCap = (*hexutil.Big)(fees.BlobGasFeeCap)
+		}
+		if current == tx.Hash() {
+			result.Reason = ErrAlreadyKnown.Error()
+		} else if err := fees.Check(tx); err != nil {
+			result.Reason = err.Error()
+		} else {
+			result.Replaces = true
+		}
+		return result, nil
+	}
+	return &ReplacementResult{Reason: "no pooled transaction with the same sender and nonce"}, nil
+}
@@ -0,0 +1,367 @@
+package txpool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// snapshotRecordHeader is the size of the length and checksum prefixing each
+	// record of the pool snapshot.
+	snapshotRecordHeader = 8
+
+	// snapshotLoadBatch is the number of transactions injected into the pool at
+	// once when restoring the snapshot.
+	snapshotLoadBatch = 1024
+)
+
+// errSnapshotCorrupted is returned if a record of the pool snapshot fails its
+// checksum, typically because the node crashed while writing it.
+var errSnapshotCorrupted = errors.New("corrupted snapshot record")
+
+// SnapshotConfig are the configuration parameters of the pool snapshot. Blob
+// transactions are never snapshotted, the blob pool persisting them itself.
+type SnapshotConfig struct {
+	Path  string // File to persist the pool contents into, empty to disable the snapshot
+	Limit uint64 // Maximum size of the snapshot file in bytes before it's compacted
+}
+
+// DefaultSnapshotConfig contains the default configurations for the pool snapshot.
+var DefaultSnapshotConfig = SnapshotConfig{
+	Limit: 256 * 1024 * 1024,
+}
+
+// snapshotEntry is a single transaction persisted into the pool snapshot.
+type snapshotEntry struct {
+	Local bool
+	Tx    *types.Transaction
+}
+
+// snapshot is an append-only, checksummed log of the transactions entering the
+// pool, local and remote ones alike, so that they survive a node restart. Blob
+// transactions are left out, the blob pool persisting them on its own. Every
+// record is prefixed by its length and CRC32 checksum, so a torn write at a
+// crash only loses the records after it. Once the log exceeds its size limit,
+// it's compacted into the live contents of the pool.
+type snapshot struct {
+	config SnapshotConfig
+	pool   *TxPool
+	writer *os.File // Output stream to append new transactions to
+	size   uint64   // Current size of the snapshot file
+
+	quit chan chan error // Quit channel to tear down the snapshot writer
+}
+
+// EnableSnapshot restores the transactions persisted by a previous run into the
+// pool, revalidating them against the current head, and starts persisting any
+// new transaction into the snapshot file.
+func (p *TxPool) EnableSnapshot(config SnapshotConfig) error {
+	if config.Path == "" {
+		return nil
+	}
+	if config.Limit == 0 {
+		config.Limit = DefaultSnapshotConfig.Limit
+	}
+	s := &snapshot{
+		config: config,
+		pool:   p,
+		quit:   make(chan chan error),
+	}
+	if err := s.load(); err != nil {
+		log.Warn("Failed to load transaction pool snapshot", "err", err)
+	}
+	// Subscribe before compacting the restored contents to not miss any
+	// transactions added in between
+	ch := make(chan core.NewTxsEvent, 16)
+	sub := p.SubscribeTransactions(ch, true)
+	if err := s.compact(); err != nil {
+		sub.Unsubscribe()
+		return err
+	}
+	p.snapshot = s
+	go s.loop(ch, sub)
+	return nil
+}
+
+// load parses the pool snapshot from disk, injecting its contents into the
+// pool. Parsing stops at the first corrupted record.
+func (s *snapshot) load() error {
+	input, err := os.Open(s.config.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	var (
+		reader  = bufio.NewReader(input)
+		total   int
+		dropped int
+		failure error
+
+		locals, remotes []*types.Transaction
+	)
+	flush := func(txs []*types.Transaction, local bool) {
+		for _, err := range s.pool.Add(txs, local, true) {
+			if err != nil {
+				log.Trace("Failed to add snapshotted transaction", "err", err)
+				dropped++
+			}
+		}
+	}
+	for {
+		entry, err := readSnapshotEntry(reader)
+		if err != nil {
+			if err != io.EOF {
+				failure = err
+			}
+			break
+		}
+		total++
+		if entry.Local {
+			if locals = append(locals, entry.Tx); len(locals) >= snapshotLoadBatch {
+				flush(locals, true)
+				locals = locals[:0]
+			}
+		} else {
+			if remotes = append(remotes, entry.Tx); len(remotes) >= snapshotLoadBatch {
+				flush(remotes, false)
+				remotes = remotes[:0]
+			}
+		}
+	}
+	if len(locals) > 0 {
+		flush(locals, true)
+	}
+	if len(remotes) > 0 {
+		flush(remotes, false)
+	}
+	log.Info("Loaded transaction pool snapshot", "transactions", total, "dropped", dropped)
+	return failure
+}
+
+// readSnapshotEntry parses the next record of the pool snapshot.
+func readSnapshotEntry(r io.Reader) (*snapshotEntry, error) {
+	var header [snapshotRecordHeader]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errSnapshotCorrupted
+		}
+		return nil, err
+	}
+	blob := make([]byte, binary.BigEndian.Uint32(header[:4]))
+	if _, err := io.ReadFull(r, blob); err != nil {
+		return nil, errSnapshotCorrupted
+	}
+	if crc32.ChecksumIEEE(blob) != binary.BigEndian.Uint32(header[4:]) {
+		return nil, errSnapshotCorrupted
+	}
+	entry := new(snapshotEntry)
+	if err := rlp.DecodeBytes(blob, entry); err != nil {
+		return nil, fmt.Errorf("%w: %v", errSnapshotCorrupted, err)
+	}
+	return entry, nil
+}
+
+// encodeSnapshotEntry appends a checksummed record of the transaction to buf.
+func encodeSnapshotEntry(buf []byte, tx *types.Transaction, local bool) ([]byte, error) {
+	blob, err := rlp.EncodeToBytes(&snapshotEntry{Local: local, Tx: tx})
+	if err != nil {
+		return buf, err
+	}
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(blob)))
+	buf = binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(blob))
+	return append(buf, blob...), nil
+}
+
+// loop appends the transactions entering the pool to the snapshot, compacting
+// it whenever it grows beyond its size limit.
+func (s *snapshot) loop(ch chan core.NewTxsEvent, sub event.Subscription) {
+	defer sub.Unsubscribe()
+
+	errc := sub.Err()
+	for {
+		select {
+		case ev := <-ch:
+			if err := s.append(ev.Txs); err != nil {
+				log.Warn("Failed to append to transaction pool snapshot", "err", err)
+			}
+			if s.size > s.config.Limit {
+				if err := s.compact(); err != nil {
+					log.Warn("Failed to compact transaction pool snapshot", "err", err)
+				}
+			}
+		case <-errc:
+			// Subscription torn down, wait for the closer
+			errc = nil
+
+		case quit := <-s.quit:
+			quit <- s.close()
+			return
+		}
+	}
+}
+
+// append writes the given transactions to the end of the snapshot.
+func (s *snapshot) append(txs []*types.Transaction) error {
+	if s.writer == nil {
+		return errors.New("no active snapshot")
+	}
+	var (
+		locals = s.localSet()
+		buf    []byte
+		err    error
+	)
+	for _, tx := range txs {
+		if !s.persistable(tx) {
+			continue
+		}
+		if buf, err = encodeSnapshotEntry(buf, tx, s.isLocal(locals, tx)); err != nil {
+			return err
+		}
+	}
+	n, err := s.writer.Write(buf)
+	s.size += uint64(n)
+	return err
+}
+
+// compact regenerates the snapshot based on the current contents of the pool,
+// local transactions first. The compacted snapshot is capped at half the size
+// limit to leave room for appends before the next compaction.
+func (s *snapshot) compact() error {
+	if s.writer != nil {
+		if err := s.writer.Close(); err != nil {
+			return err
+		}
+		s.writer = nil
+	}
+	replacement, err := os.OpenFile(s.config.Path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	var (
+		locals = s.localSet()
+		txs    = s.contents(locals)
+		buf    []byte
+		count  int
+	)
+	for _, tx := range txs {
+		if !s.persistable(tx) {
+			continue
+		}
+		next, err := encodeSnapshotEntry(buf, tx, s.isLocal(locals, tx))
+		if err != nil {
+			replacement.Close()
+			return err
+		}
+		if uint64(len(next)) > s.config.Limit/2 {
+			log.Warn("Transaction pool snapshot limit reached", "persisted", count, "dropped", len(txs)-count)
+			break
+		}
+		buf, count = next, count+1
+	}
+	if _, err := replacement.Write(buf); err != nil {
+		replacement.Close()
+		return err
+	}
+	if err := replacement.Sync(); err != nil {
+		replacement.Close()
+		return err
+	}
+	replacement.Close()
+
+	// Replace the live snapshot with the newly generated one
+	if err = os.Rename(s.config.Path+".new", s.config.Path); err != nil {
+		return err
+	}
+	sink, err := os.OpenFile(s.config.Path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	s.writer, s.size = sink, uint64(len(buf))
+
+	log.Debug("Compacted transaction pool snapshot", "transactions", count, "size", s.size)
+	return nil
+}
+
+// contents returns the non-blob transactions of the pool, local ones first.
+func (s *snapshot) contents(locals map[common.Address]struct{}) []*types.Transaction {
+	var local, remote []*types.Transaction
+
+	collect := func(addr common.Address, txs []*types.Transaction) {
+		if _, ok := locals[addr]; ok {
+			local = append(local, txs...)
+		} else {
+			remote = append(remote, txs...)
+		}
+	}
+	pending, queued := s.pool.Content()
+	for addr, txs := range pending {
+		collect(addr, txs)
+	}
+	for addr, txs := range queued {
+		collect(addr, txs)
+	}
+	return append(local, remote...)
+}
+
+// persistable returns whether the transaction may be persisted. Private and
+// conditional transactions are not, as they'd be restored without their policies,
+// neither are blob transactions, which the blob pool already persists.
+func (s *snapshot) persistable(tx *types.Transaction) bool {
+	if tx.Type() == types.BlobTxType {
+		return false
+	}
+	hash := tx.Hash()
+	return !s.pool.IsPrivate(hash) && s.pool.Conditional(hash) == nil
+}
+
+// localSet returns the accounts currently considered local by the pool.
+func (s *snapshot) localSet() map[common.Address]struct{} {
+	locals := make(map[common.Address]struct{})
+	for _, addr := range s.pool.Locals() {
+		locals[addr] = struct{}{}
+	}
+	return locals
+}
+
+// isLocal returns whether the transaction was sent by a local account.
+func (s *snapshot) isLocal(locals map[common.Address]struct{}, tx *types.Transaction) bool {
+	if len(locals) == 0 {
+		return false
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return false
+	}
+	_, ok := locals[from]
+	return ok
+}
+
+// close compacts the snapshot a final time and closes the file.
+func (s *snapshot) close() error {
+	if err := s.compact(); err != nil {
+		log.Warn("Failed to compact transaction pool snapshot", "err", err)
+	}
+	if s.writer == nil {
+		return nil
+	}
+	err := s.writer.Close()
+	s.writer = nil
+	return err
+}
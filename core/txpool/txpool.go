@@ -75,6 +75,12 @@ type TxPool struct {
 	term chan struct{}           // Termination channel to detect a closed pool
 
 	sync chan chan error // Testing / simulator channel to block until internal reset is done
+
+	snapshot  *snapshot   // Optional persistent snapshot of the pool contents
+	admission *admission  // Admission rules enforced on top of the subpool limits
+	private   *privateSet // Transactions excluded from p2p propagation
+
+	conditionals *conditionalSet // Constraints of the conditional transactions
 }
 
 // New creates a new transaction pool to gather, sort and filter inbound
@@ -91,6 +97,9 @@ func New(gasTip uint64, chain BlockChain, subpools []SubPool) (*TxPool, error) {
 		quit:         make(chan chan error),
 		term:         make(chan struct{}),
 		sync:         make(chan chan error),
+		admission:    newAdmission(AdmissionConfig{}),
+		private:      newPrivateSet(head),
+		conditionals: newConditionalSet(),
 	}
 	for i, subpool := range subpools {
 		if err := subpool.Init(gasTip, head, pool.reserver(i, subpool)); err != nil {
@@ -154,6 +163,14 @@ func (p *TxPool) reserver(id int, subpool SubPool) AddressReserver {
 func (p *TxPool) Close() error {
 	var errs []error
 
+	// Persist the pool contents while the subpools are still alive
+	if p.snapshot != nil {
+		errc := make(chan error)
+		p.snapshot.quit <- errc
+		if err := <-errc; err != nil {
+			errs = append(errs, err)
+		}
+	}
 	// Terminate the reset loop and wait for it to finish
 	errc := make(chan error)
 	p.quit <- errc
@@ -223,6 +240,8 @@ func (p *TxPool) loop(head *types.Header, chain BlockChain) {
 					for _, subpool := range p.subpools {
 						subpool.Reset(oldHead, newHead)
 					}
+					p.expirePrivate(newHead)
+					p.expireConditionals(newHead)
 					resetDone <- newHead
 				}(oldHead, newHead)
 
@@ -305,10 +324,15 @@ func (p *TxPool) Get(hash common.Hash) *types.Transaction {
 	return nil
 }
 
-// Add enqueues a batch of transactions into the pool if they are valid. Due
-// to the large transaction churn, add may postpone fully integrating the tx
-// to a later point to batch multiple ones together.
+// Add enqueues a batch of transactions into the pool if they pass the admission
+// rules and are valid. Due to the large transaction churn, add may postpone
+// fully integrating the tx to a later point to batch multiple ones together.
 func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
+	return p.AddWithOrigin("", txs, local, sync)
+}
+
+// add enqueues a batch of admitted transactions into the subpools.
+func (p *TxPool) add(txs []*types.Transaction, local bool, sync bool) []error {
 	// Split the input transactions between the subpools. It shouldn't really
 	// happen that we receive merged batches, but better graceful than strange
 	// errors.
@@ -369,10 +393,11 @@ func (p *TxPool) Pending(filter PendingFilter) map[common.Address][]*LazyTransac
 // SubscribeTransactions registers a subscription for new transaction events,
 // supporting feeding only newly seen or also resurrected transactions.
 func (p *TxPool) SubscribeTransactions(ch chan<- core.NewTxsEvent, reorgs bool) event.Subscription {
-	subs := make([]event.Subscription, len(p.subpools))
+	subs := make([]event.Subscription, len(p.subpools), len(p.subpools)+1)
 	for i, subpool := range p.subpools {
 		subs[i] = subpool.SubscribeTransactions(ch, reorgs)
 	}
+	subs = append(subs, p.private.feed.Subscribe(ch)) // Private transactions released to gossip
 	return p.subs.Track(event.JoinSubscriptions(subs...))
 }
 
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
@@ -248,3 +249,54 @@ func ValidateTransactionWithState(tx *types.Transaction, signer types.Signer, op
 	}
 	return nil
 }
+
+// Reject reason codes of the transaction admission rules.
+const (
+	AdmissionSenderDenied     = "sender-denied"       // Sender is on the deny list
+	AdmissionSenderNotAllowed = "sender-not-allowed"  // Sender is missing from a non-empty allow list
+	AdmissionSenderLimit      = "sender-limit"        // Sender exceeds its pooled transaction allowance
+	AdmissionRateLimited      = "origin-rate-limited" // Submitting origin exceeds its rate limit
+)
+
+// AdmissionConfig are the admission rules enforced on the transactions entering
+// the pool, on top of the pricing and slot limits of the subpools.
+type AdmissionConfig struct {
+	MaxPerSender uint64           // Maximum number of pooled nonces per sender, replacements exempted (0 = unlimited)
+	OriginRate   float64          // Transactions per second admitted from a single RPC origin (0 = unlimited)
+	OriginBurst  int              // Maximum burst of transactions admitted from a single RPC origin
+	Deny         []common.Address // Senders whose transactions are always rejected
+	Allow        []common.Address // Senders exclusively admitted, if non-empty
+}
+
+// AdmissionError is returned if a transaction is rejected by the admission rules.
+type AdmissionError struct {
+	Code   string // Reject reason code
+	Reason string // Human readable reject reason
+}
+
+func (e *AdmissionError) Error() string { return "transaction not admitted: " + e.Reason }
+
+// ErrorCode returns the JSON-RPC error code for admission failures.
+func (e *AdmissionError) ErrorCode() int { return -32005 }
+
+// ErrorData returns the reject reason code.
+func (e *AdmissionError) ErrorData() interface{} { return e.Code }
+
+// ValidateAdmission checks whether the admission rules accept a transaction of
+// the given sender, which already has the given number of pooled nonces. The
+// replacements of a pooled nonce are exempt from the sender limit, to allow fee
+// bumps. Origin rate limits are stateful and enforced by the pool itself.
+func ValidateAdmission(config *AdmissionConfig, from common.Address, pooled uint64, replace bool) error {
+	for _, denied := range config.Deny {
+		if denied == from {
+			return &AdmissionError{Code: AdmissionSenderDenied, Reason: fmt.Sprintf("sender %v denied", from)}
+		}
+	}
+	if len(config.Allow) > 0 && !slices.Contains(config.Allow, from) {
+		return &AdmissionError{Code: AdmissionSenderNotAllowed, Reason: fmt.Sprintf("sender %v not allowed", from)}
+	}
+	if config.MaxPerSender > 0 && !replace && pooled >= config.MaxPerSender {
+		return &AdmissionError{Code: AdmissionSenderLimit, Reason: fmt.Sprintf("sender %v has %d pooled transactions, limit %d", from, pooled, config.MaxPerSender)}
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package vm
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// StatefulPrecompiledContract is a precompiled contract which needs access to
+// the state and the call context. It's only run statefully if called directly,
+// delegated calls fall back to the stateless Run method.
+type StatefulPrecompiledContract interface {
+	PrecompiledContract
+
+	// RunStateful runs the precompiled contract on behalf of the caller, with
+	// the value already transferred to the contract.
+	RunStateful(evm *EVM, caller common.Address, input []byte, value *uint256.Int, readOnly bool) ([]byte, error)
+}
+
+// PrecompiledContractsMeer contains the pre-compiled contracts specific to the
+// Qitmeer networks, activated on top of the Ethereum ones.
+var PrecompiledContractsMeer = map[common.Address]PrecompiledContract{
+	params.MeerBridgeAddress: &meerBridge{},
+}
+
+// PrecompiledAddressesMeer contains the addresses of the Qitmeer specific
+// pre-compiled contracts.
+var PrecompiledAddressesMeer = []common.Address{params.MeerBridgeAddress}
+
+// runPrecompiledContract runs a precompiled contract, granting stateful ones the
+// access to the state and the call context.
+func (evm *EVM) runPrecompiledContract(p PrecompiledContract, caller common.Address, input []byte, suppliedGas uint64, value *uint256.Int, readOnly bool) ([]byte, uint64, error) {
+	stateful, ok := p.(StatefulPrecompiledContract)
+	if !ok {
+		return RunPrecompiledContract(p, input, suppliedGas, evm.Config.Tracer)
+	}
+	gasCost := p.RequiredGas(input)
+	if suppliedGas < gasCost {
+		return nil, 0, ErrOutOfGas
+	}
+	if logger := evm.Config.Tracer; logger != nil && logger.OnGasChange != nil {
+		logger.OnGasChange(suppliedGas, suppliedGas-gasCost, tracing.GasChangeCallPrecompiledContract)
+	}
+	suppliedGas -= gasCost
+	output, err := stateful.RunStateful(evm, caller, input, value, readOnly)
+	return output, suppliedGas, err
+}
+
+const (
+	meerBridgeImport   byte = 0x01 // Mints ether for a UTXO locked on the MeerDAG
+	meerBridgeExport   byte = 0x02 // Burns the called value for unlocking on the MeerDAG
+	meerBridgeImported byte = 0x03 // Checks whether a UTXO was already imported
+
+	meerBridgeImportLength   = 4*32 + crypto.SignatureLength // txid, index, recipient, amount, signature
+	meerBridgeImportedLength = 2 * 32                        // txid, index
+)
+
+var (
+	errMeerBridgeIndirect    = errors.New("bridge must be called directly")
+	errMeerBridgeInactive    = errors.New("bridge not active")
+	errMeerBridgeOperation   = errors.New("unknown bridge operation")
+	errMeerBridgeInput       = errors.New("invalid bridge input")
+	errMeerBridgeProof       = errors.New("invalid UTXO lock proof")
+	errMeerBridgeImported    = errors.New("UTXO already imported")
+	errMeerBridgeValue       = errors.New("bridge import doesn't accept value")
+	errMeerBridgeNoValue     = errors.New("bridge export without value")
+	errMeerBridgeDestination = errors.New("invalid bridge export destination")
+
+	// meerImportTopic is the topic of Import(bytes32 indexed txid, address indexed recipient, uint32 index, uint256 amount)
+	meerImportTopic = crypto.Keccak256Hash([]byte("Import(bytes32,address,uint32,uint256)"))
+
+	// meerExportTopic is the topic of Export(address indexed sender, uint256 indexed nonce, uint256 amount, bytes destination)
+	meerExportTopic = crypto.Keccak256Hash([]byte("Export(address,uint256,uint256,bytes)"))
+
+	// meerExportNonceSlot is the storage slot of the bridge tracking the number of exports.
+	meerExportNonceSlot = common.Hash{}
+)
+
+// meerBridge implements the UTXO <-> EVM bridge of Qitmeer networks.
+//
+// Imports mint ether for an UTXO locked on the MeerDAG, authenticated by a proof
+// signed by the bridge operator over keccak256(chainid, txid, index, recipient,
+// amount). Every UTXO can only be imported once. Exports burn the ether sent to
+// the bridge, emitting an event for the MeerDAG to unlock it to the destination.
+type meerBridge struct{}
+
+func (c *meerBridge) RequiredGas(input []byte) uint64 {
+	if len(input) == 0 {
+		return 0
+	}
+	switch input[0] {
+	case meerBridgeImport:
+		return params.MeerBridgeImportGas
+	case meerBridgeExport:
+		return params.MeerBridgeExportGas + uint64(len(input)-1)*params.MeerBridgeExportByteGas
+	case meerBridgeImported:
+		return params.MeerBridgeQueryGas
+	default:
+		return 0
+	}
+}
+
+func (c *meerBridge) Run(input []byte) ([]byte, error) {
+	return nil, errMeerBridgeIndirect
+}
+
+func (c *meerBridge) RunStateful(evm *EVM, caller common.Address, input []byte, value *uint256.Int, readOnly bool) ([]byte, error) {
+	config := evm.chainConfig.MeerBridge(evm.Context.BlockNumber)
+	if config == nil {
+		return nil, errMeerBridgeInactive
+	}
+	if len(input) == 0 {
+		return nil, errMeerBridgeOperation
+	}
+	switch input[0] {
+	case meerBridgeImport:
+		if readOnly {
+			return nil, ErrWriteProtection
+		}
+		return c.runImport(evm, config, input[1:], value)
+	case meerBridgeExport:
+		if readOnly {
+			return nil, ErrWriteProtection
+		}
+		return c.runExport(evm, caller, input[1:], value)
+	case meerBridgeImported:
+		if len(input[1:]) != meerBridgeImportedLength {
+			return nil, errMeerBridgeInput
+		}
+		if evm.StateDB.GetState(params.MeerBridgeAddress, meerImportSlot(input[1:])) == (common.Hash{}) {
+			return make([]byte, 32), nil
+		}
+		return common.LeftPadBytes([]byte{1}, 32), nil
+	default:
+		return nil, errMeerBridgeOperation
+	}
+}
+
+// runImport verifies a UTXO lock proof and mints the locked amount.
+func (c *meerBridge) runImport(evm *EVM, config *params.MeerBridgeConfig, input []byte, value *uint256.Int) ([]byte, error) {
+	if !value.IsZero() {
+		return nil, errMeerBridgeValue
+	}
+	if len(input) != meerBridgeImportLength {
+		return nil, errMeerBridgeInput
+	}
+	var (
+		txid      = common.BytesToHash(input[:32])
+		index     = input[32:64]
+		recipient = input[64:96]
+		amount    = new(uint256.Int).SetBytes(input[96:128])
+		sig       = common.CopyBytes(input[128:])
+	)
+	if !allZero(index[:28]) || !allZero(recipient[:12]) || amount.IsZero() {
+		return nil, errMeerBridgeInput
+	}
+	// Verify the proof was signed by the bridge operator for this chain
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	if !crypto.ValidateSignatureValues(sig[crypto.RecoveryIDOffset], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64]), true) {
+		return nil, errMeerBridgeProof
+	}
+	msg := crypto.Keccak256(common.LeftPadBytes(evm.chainConfig.ChainID.Bytes(), 32), input[:128])
+	pubkey, err := crypto.SigToPub(msg, sig)
+	if err != nil || crypto.PubkeyToAddress(*pubkey) != config.Operator {
+		return nil, errMeerBridgeProof
+	}
+	// Mark the UTXO imported and mint the locked amount
+	slot := meerImportSlot(input[:64])
+	if evm.StateDB.GetState(params.MeerBridgeAddress, slot) != (common.Hash{}) {
+		return nil, errMeerBridgeImported
+	}
+	meerBridgeTouch(evm)
+	evm.StateDB.SetState(params.MeerBridgeAddress, slot, common.BytesToHash([]byte{1}))
+	evm.StateDB.AddBalance(common.BytesToAddress(recipient), amount, tracing.BalanceIncreaseMeerImport)
+
+	evm.StateDB.AddLog(&types.Log{
+		Address:     params.MeerBridgeAddress,
+		Topics:      []common.Hash{meerImportTopic, txid, common.BytesToHash(recipient)},
+		Data:        append(common.CopyBytes(index), input[96:128]...),
+		BlockNumber: evm.Context.BlockNumber.Uint64(),
+	})
+	return nil, nil
+}
+
+// runExport burns the value sent to the bridge for unlocking on the MeerDAG.
+func (c *meerBridge) runExport(evm *EVM, caller common.Address, destination []byte, value *uint256.Int) ([]byte, error) {
+	if value.IsZero() {
+		return nil, errMeerBridgeNoValue
+	}
+	if len(destination) == 0 || len(destination) > params.MaxMeerBridgeDestination {
+		return nil, errMeerBridgeDestination
+	}
+	meerBridgeTouch(evm)
+	evm.StateDB.SubBalance(params.MeerBridgeAddress, value, tracing.BalanceDecreaseMeerExport)
+
+	nonce := evm.StateDB.GetState(params.MeerBridgeAddress, meerExportNonceSlot)
+	evm.StateDB.SetState(params.MeerBridgeAddress, meerExportNonceSlot, new(uint256.Int).AddUint64(new(uint256.Int).SetBytes(nonce[:]), 1).Bytes32())
+
+	// Encode the non-indexed (uint256 amount, bytes destination) ABI tuple
+	data := make([]byte, 0, 4*32+len(destination))
+	data = append(data, value.PaddedBytes(32)...)
+	data = append(data, common.LeftPadBytes([]byte{0x40}, 32)...)
+	data = append(data, common.LeftPadBytes(binary.BigEndian.AppendUint64(nil, uint64(len(destination))), 32)...)
+	data = append(data, common.RightPadBytes(destination, (len(destination)+31)/32*32)...)
+
+	evm.StateDB.AddLog(&types.Log{
+		Address:     params.MeerBridgeAddress,
+		Topics:      []common.Hash{meerExportTopic, common.BytesToHash(caller.Bytes()), nonce},
+		Data:        data,
+		BlockNumber: evm.Context.BlockNumber.Uint64(),
+	})
+	return nonce[:], nil
+}
+
+// meerImportSlot returns the storage slot of the bridge tracking whether the
+// UTXO identified by the (txid, index) pair was imported.
+func meerImportSlot(outpoint []byte) common.Hash {
+	return crypto.Keccak256Hash(outpoint)
+}
+
+// meerBridgeTouch ensures the bridge account is not empty, so its storage isn't
+// wiped by the state clearing of EIP-158.
+func meerBridgeTouch(evm *EVM) {
+	if evm.StateDB.GetNonce(params.MeerBridgeAddress) == 0 {
+		evm.StateDB.SetNonce(params.MeerBridgeAddress, 1)
+	}
+}
@@ -0,0 +1,145 @@
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// meerBridgeImportInput assembles the calldata importing a UTXO, signed by key.
+func meerBridgeImportInput(key []byte, chainID *big.Int, txid common.Hash, index uint32, recipient common.Address, amount uint64) []byte {
+	proof := append(txid.Bytes(), common.LeftPadBytes(big.NewInt(int64(index)).Bytes(), 32)...)
+	proof = append(proof, common.LeftPadBytes(recipient.Bytes(), 32)...)
+	proof = append(proof, common.LeftPadBytes(new(big.Int).SetUint64(amount).Bytes(), 32)...)
+
+	priv, _ := crypto.ToECDSA(key)
+	sig, _ := crypto.Sign(crypto.Keccak256(common.LeftPadBytes(chainID.Bytes(), 32), proof), priv)
+	return append(append([]byte{meerBridgeImport}, proof...), sig...)
+}
+
+func TestMeerBridge(t *testing.T) {
+	var (
+		operatorKey = common.FromHex("0x45a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8")
+		operator    = common.HexToAddress("0xa94f5374fce5edbc8e2a8697c15331677e6ebf0b")
+		sender      = common.HexToAddress("0x1000")
+		recipient   = common.HexToAddress("0x2000")
+		txid        = common.HexToHash("0x4242")
+	)
+	config := *params.AllEthashProtocolChanges
+	config.ChainID = big.NewInt(990200)
+	config.SetMeerBridge(&params.MeerBridgeConfig{Block: 1, Operator: operator})
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddBalance(sender, uint256.NewInt(1000), tracing.BalanceChangeUnspecified)
+
+	newEVM := func(number int64) *EVM {
+		vmctx := BlockContext{
+			CanTransfer: func(db StateDB, addr common.Address, amount *uint256.Int) bool {
+				return db.GetBalance(addr).Cmp(amount) >= 0
+			},
+			Transfer: func(db StateDB, from, to common.Address, amount *uint256.Int) {
+				db.SubBalance(from, amount, tracing.BalanceChangeTransfer)
+				db.AddBalance(to, amount, tracing.BalanceChangeTransfer)
+			},
+			BlockNumber: big.NewInt(number),
+		}
+		return NewEVM(vmctx, TxContext{}, statedb, &config, Config{})
+	}
+	input := meerBridgeImportInput(operatorKey, config.ChainID, txid, 1, recipient, 500)
+
+	// The bridge must not exist before its activation
+	if _, _, err := newEVM(0).Call(AccountRef(sender), params.MeerBridgeAddress, input, 100000, new(uint256.Int)); err != nil {
+		t.Fatalf("inactive bridge call failed: %v", err)
+	}
+	if statedb.GetBalance(recipient).Uint64() != 0 {
+		t.Fatalf("inactive bridge minted")
+	}
+	evm := newEVM(1)
+	if addrs := ActivePrecompiles(evm.chainRules); addrs[len(addrs)-1] != params.MeerBridgeAddress {
+		t.Fatalf("bridge not active: %v", addrs)
+	}
+	// Imports must be signed by the operator and can't be replayed
+	forged := meerBridgeImportInput(common.FromHex("0x0202020202020202020202020202020202020202020202020202020202020202"), config.ChainID, txid, 1, recipient, 500)
+	if _, _, err := evm.Call(AccountRef(sender), params.MeerBridgeAddress, forged, 100000, new(uint256.Int)); err != errMeerBridgeProof {
+		t.Fatalf("forged proof: have %v, want %v", err, errMeerBridgeProof)
+	}
+	if _, _, err := evm.StaticCall(AccountRef(sender), params.MeerBridgeAddress, input, 100000); err != ErrWriteProtection {
+		t.Fatalf("static import: have %v, want %v", err, ErrWriteProtection)
+	}
+	// Nor may an import be reached through a plain call within a static frame
+	proxy := common.HexToAddress("0x3000")
+	statedb.SetCode(proxy, append(append([]byte{
+		byte(CALLDATASIZE), byte(PUSH1), 0, byte(PUSH1), 0, byte(CALLDATACOPY),
+		byte(PUSH1), 0, byte(PUSH1), 0, byte(CALLDATASIZE), byte(PUSH1), 0, byte(PUSH1), 0,
+		byte(PUSH20)}, params.MeerBridgeAddress.Bytes()...),
+		byte(GAS), byte(CALL), byte(STOP),
+	))
+	var nested error
+	evm.Config.Tracer = &tracing.Hooks{
+		OnExit: func(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+			if depth == 1 {
+				nested = err
+			}
+		},
+	}
+	if _, _, err := evm.StaticCall(AccountRef(sender), proxy, input, 100000); err != nil {
+		t.Fatalf("static proxy call failed: %v", err)
+	}
+	evm.Config.Tracer = nil
+	if !errors.Is(nested, ErrWriteProtection) {
+		t.Fatalf("nested static import: have %v, want %v", nested, ErrWriteProtection)
+	}
+	if _, _, err := evm.DelegateCall(NewContract(AccountRef(sender), AccountRef(sender), nil, 0), params.MeerBridgeAddress, input, 100000); err != errMeerBridgeIndirect {
+		t.Fatalf("delegated import: have %v, want %v", err, errMeerBridgeIndirect)
+	}
+	_, gas, err := evm.Call(AccountRef(sender), params.MeerBridgeAddress, input, 100000, new(uint256.Int))
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if used := 100000 - gas; used != params.MeerBridgeImportGas {
+		t.Fatalf("import gas mismatch: have %d, want %d", used, params.MeerBridgeImportGas)
+	}
+	if statedb.GetBalance(recipient).Uint64() != 500 {
+		t.Fatalf("import balance mismatch: have %v, want 500", statedb.GetBalance(recipient))
+	}
+	if _, _, err := evm.Call(AccountRef(sender), params.MeerBridgeAddress, input, 100000, new(uint256.Int)); err != errMeerBridgeImported {
+		t.Fatalf("replayed import: have %v, want %v", err, errMeerBridgeImported)
+	}
+	query := append([]byte{meerBridgeImported}, input[1:65]...)
+	if ret, _, err := evm.StaticCall(AccountRef(sender), params.MeerBridgeAddress, query, 100000); err != nil || ret[31] != 1 {
+		t.Fatalf("import query mismatch: have %x, %v", ret, err)
+	}
+	// Exports must burn the value sent to the bridge
+	export := append([]byte{meerBridgeExport}, []byte("MmQitmeerDestination")...)
+	if _, _, err := evm.Call(AccountRef(sender), params.MeerBridgeAddress, export, 100000, new(uint256.Int)); err != errMeerBridgeNoValue {
+		t.Fatalf("valueless export: have %v, want %v", err, errMeerBridgeNoValue)
+	}
+	ret, _, err := evm.Call(AccountRef(sender), params.MeerBridgeAddress, export, 100000, uint256.NewInt(300))
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if new(big.Int).SetBytes(ret).Sign() != 0 {
+		t.Fatalf("first export nonce mismatch: have %x", ret)
+	}
+	if statedb.GetBalance(sender).Uint64() != 700 || !statedb.GetBalance(params.MeerBridgeAddress).IsZero() {
+		t.Fatalf("export not burnt: sender %v, bridge %v", statedb.GetBalance(sender), statedb.GetBalance(params.MeerBridgeAddress))
+	}
+	logs := statedb.Logs()
+	if len(logs) != 2 || logs[0].Topics[0] != meerImportTopic || logs[1].Topics[0] != meerExportTopic {
+		t.Fatalf("unexpected bridge logs: %v", logs)
+	}
+	// The bridge state must survive the clearing of empty accounts
+	statedb.Finalise(true)
+	if _, _, err := evm.Call(AccountRef(sender), params.MeerBridgeAddress, input, 100000, new(uint256.Int)); err != errMeerBridgeImported {
+		t.Fatalf("replayed import after finalisation: have %v, want %v", err, errMeerBridgeImported)
+	}
+}
@@ -0,0 +1,100 @@
+package eth
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// TxPoolAdmission returns the admission rules of the transaction pool.
+func (api *AdminAPI) TxPoolAdmission() txpool.AdmissionConfig {
+	return api.eth.TxPool().Admission()
+}
+
+// SetTxPoolAdmission replaces the admission rules of the transaction pool
+// without restarting the node.
+func (api *AdminAPI) SetTxPoolAdmission(config txpool.AdmissionConfig) bool {
+	api.eth.TxPool().SetAdmission(config)
+	return true
+}
+
+// TxPoolImportResult is the outcome of a transaction pool import.
+type TxPoolImportResult struct {
+	Total   int `json:"total"`   // Number of transactions read from the export
+	Dropped int `json:"dropped"` // Number of transactions rejected by the pool
+}
+
+// ExportTxPool writes the contents of the transaction pool into a file, in a
+// streaming RLP format, gzipped if the file name ends with .gz. It returns the
+// number of transactions exported.
+func (api *AdminAPI) ExportTxPool(file string) (int, error) {
+	if _, err := os.Stat(file); err == nil {
+		// File already exists. Allowing overwrite could be a DoS vector,
+		// since the 'file' may point to arbitrary paths on the drive.
+		return 0, errors.New("location would overwrite an existing file")
+	}
+	out, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+
+	if !strings.HasSuffix(file, ".gz") {
+		return api.eth.TxPool().Export(out)
+	}
+	writer := gzip.NewWriter(out)
+	count, err := api.eth.TxPool().Export(writer)
+	if err != nil {
+		writer.Close()
+		return 0, err
+	}
+	// Flush the compressed stream, a truncated export must not report success
+	if err := writer.Close(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ImportTxPool replays the transactions of an export file through the
+// validation of the transaction pool.
+func (api *AdminAPI) ImportTxPool(file string) (*TxPoolImportResult, error) {
+	in, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	var reader io.Reader = in
+	if strings.HasSuffix(file, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return nil, err
+		}
+	}
+	total, dropped, err := api.eth.TxPool().Import(reader)
+	if err != nil {
+		return nil, err
+	}
+	return &TxPoolImportResult{Total: total, Dropped: dropped}, nil
+}
+
+// submissionOrigin returns the key to rate limit the transactions submitted by
+// an RPC client with, the client IP for network transports. IPC and in-process
+// clients are trusted and exempt from the origin rate limits.
+func submissionOrigin(ctx context.Context) string {
+	info := rpc.PeerInfoFromContext(ctx)
+	if info.Transport == "" || info.Transport == "ipc" {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(info.RemoteAddr)
+	if err != nil {
+		return info.RemoteAddr
+	}
+	return host
+}
@@ -284,7 +284,19 @@ func (b *EthAPIBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscri
 }
 
 func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
-	return b.eth.txPool.Add([]*types.Transaction{signedTx}, true, false)[0]
+	return b.eth.txPool.AddWithOrigin(submissionOrigin(ctx), []*types.Transaction{signedTx}, true, false)[0]
+}
+
+func (b *EthAPIBackend) SendConditionalTx(ctx context.Context, signedTx *types.Transaction, cond *txpool.TransactionConditional) error {
+	return b.eth.txPool.AddConditional(submissionOrigin(ctx), signedTx, cond)
+}
+
+func (b *EthAPIBackend) SimulateReplacement(tx *types.Transaction) (*txpool.ReplacementResult, error) {
+	return b.eth.txPool.SimulateReplacement(tx)
+}
+
+func (b *EthAPIBackend) SendPrivateTx(ctx context.Context, signedTx *types.Transaction, maxBlock uint64, release bool) error {
+	return b.eth.txPool.AddPrivate(submissionOrigin(ctx), signedTx, maxBlock, release)
 }
 
 func (b *EthAPIBackend) GetPoolTransactions() (types.Transactions, error) {
@@ -349,6 +361,10 @@ func (b *EthAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.S
 	return b.eth.txPool.SubscribeTransactions(ch, true)
 }
 
+func (b *EthAPIBackend) SubscribeTxPoolEvents(ch chan<- []txpool.TxPoolEvent) event.Subscription {
+	return b.eth.txPool.SubscribeEvents(ch)
+}
+
 func (b *EthAPIBackend) SyncProgress() ethereum.SyncProgress {
 	prog := b.eth.Downloader().Progress()
 	if txProg, err := b.eth.blockchain.TxIndexProgress(); err == nil {
@@ -0,0 +1,21 @@
+package eth
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// errTraceIndexDisabled is returned if the trace index is queried while the
+// node runs without it.
+var errTraceIndexDisabled = errors.New("trace index disabled, run with --traceindex")
+
+// TraceIndexedBlocks returns the numbers of the blocks within [from, to] in
+// which any of the given addresses sends or receives a call, as per the trace
+// index. An error is returned if the range is not fully indexed.
+func (b *EthAPIBackend) TraceIndexedBlocks(addrs []common.Address, from, to uint64) ([]uint64, error) {
+	if b.eth.traceIndexer == nil {
+		return nil, errTraceIndexDisabled
+	}
+	return b.eth.traceIndexer.Blocks(addrs, from, to)
+}
@@ -0,0 +1,61 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner"
+)
+
+// SendBundleArgs represents the arguments of bundle_sendBundle.
+type SendBundleArgs struct {
+	Txs               []hexutil.Bytes `json:"txs"`
+	BlockNumber       hexutil.Uint64  `json:"blockNumber"`
+	RevertingTxHashes []common.Hash   `json:"revertingTxHashes,omitempty"`
+}
+
+// BundleAPI provides an API to submit atomic transaction bundles to the miner.
+// It is served on its own namespace, only exposed over HTTP and WebSocket if
+// explicitly enabled, as the bundles are simulated on every payload built.
+type BundleAPI struct {
+	e *Ethereum
+}
+
+// NewBundleAPI creates a new BundleAPI instance.
+func NewBundleAPI(e *Ethereum) *BundleAPI {
+	return &BundleAPI{e}
+}
+
+// SendBundle queues a bundle of transactions to be included all together, in
+// order, at the top of the target block, or not at all. It returns the bundle
+// hash to query its outcome with.
+func (api *BundleAPI) SendBundle(args SendBundleArgs) (common.Hash, error) {
+	if args.BlockNumber == 0 {
+		return common.Hash{}, errors.New("missing bundle block number")
+	}
+	bundle := &miner.Bundle{
+		Txs:               make(types.Transactions, len(args.Txs)),
+		BlockNumber:       uint64(args.BlockNumber),
+		RevertingTxHashes: args.RevertingTxHashes,
+	}
+	for i, encoded := range args.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(encoded); err != nil {
+			return common.Hash{}, fmt.Errorf("invalid transaction %d: %v", i, err)
+		}
+		bundle.Txs[i] = tx
+	}
+	return api.e.Miner().AddBundle(bundle)
+}
+
+// GetBundleStatus returns the outcome of a submitted bundle.
+func (api *BundleAPI) GetBundleStatus(hash common.Hash) (*miner.BundleResult, error) {
+	result := api.e.Miner().BundleStatus(hash)
+	if result == nil {
+		return nil, fmt.Errorf("bundle %x not found", hash)
+	}
+	return result, nil
+}
@@ -0,0 +1,116 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers/live"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// GetSupply returns the native coin issuance and burn of the given block, as
+// well as the running total supply after it. It requires the node to run with
+// the supply live tracer (--vmtrace supply).
+func (api *DebugAPI) GetSupply(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*live.SupplyInfo, error) {
+	header, err := api.eth.APIBackend.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.New("block not found")
+	}
+	return live.SupplyAt(header.Number.Uint64(), header.Hash())
+}
+
+// maxAddressTraces is the maximum number of call frames returned by a single
+// debug_getAddressTraces request.
+const maxAddressTraces = 10000
+
+// AddressTrace is a call frame involving an address, as served by
+// debug_getAddressTraces.
+type AddressTrace struct {
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	BlockHash        common.Hash    `json:"blockHash"`
+	TransactionHash  common.Hash    `json:"transactionHash"`
+	TransactionIndex hexutil.Uint64 `json:"transactionIndex"`
+	Type             string         `json:"type"`
+	Depth            hexutil.Uint64 `json:"depth"`
+	From             common.Address `json:"from"`
+	To               common.Address `json:"to"`
+	Value            *hexutil.Big   `json:"value"`
+	Reverted         bool           `json:"reverted"`
+}
+
+// GetAddressTraces returns the internal calls and value transfers within the
+// given block range in which the address is the sender or the recipient. It
+// requires the node to run with the trace index (--traceindex), and the range
+// defaults to all the indexed blocks.
+func (api *DebugAPI) GetAddressTraces(ctx context.Context, address common.Address, fromBlock, toBlock *rpc.BlockNumber) ([]*AddressTrace, error) {
+	indexer := api.eth.TraceIndexer()
+	if indexer == nil {
+		return nil, errTraceIndexDisabled
+	}
+	from, to, ok := indexer.Range()
+	if !ok {
+		return nil, core.ErrTraceRangeNotIndexed
+	}
+	for _, bound := range []struct {
+		number *rpc.BlockNumber
+		target *uint64
+	}{{fromBlock, &from}, {toBlock, &to}} {
+		if bound.number == nil {
+			continue
+		}
+		header, err := api.eth.APIBackend.HeaderByNumber(ctx, *bound.number)
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, fmt.Errorf("block #%d not found", *bound.number)
+		}
+		*bound.target = header.Number.Uint64()
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid block range: %d > %d", from, to)
+	}
+	traces, err := indexer.Traces(address, from, to, maxAddressTraces)
+	if err != nil {
+		return nil, err
+	}
+	if len(traces) > maxAddressTraces {
+		return nil, fmt.Errorf("too many results, max %d: narrow the block range", maxAddressTraces)
+	}
+	var (
+		results = make([]*AddressTrace, 0, len(traces))
+		block   *types.Block
+	)
+	for _, trace := range traces {
+		if block == nil || block.Hash() != trace.BlockHash {
+			if block = api.eth.blockchain.GetBlock(trace.BlockHash, trace.BlockNumber); block == nil {
+				return nil, fmt.Errorf("block #%d not found", trace.BlockNumber)
+			}
+		}
+		result := &AddressTrace{
+			BlockNumber:      hexutil.Uint64(trace.BlockNumber),
+			BlockHash:        trace.BlockHash,
+			TransactionIndex: hexutil.Uint64(trace.TxIndex),
+			Type:             vm.OpCode(trace.Type).String(),
+			Depth:            hexutil.Uint64(trace.Depth),
+			From:             trace.From,
+			To:               trace.To,
+			Value:            (*hexutil.Big)(trace.Value),
+			Reverted:         trace.Reverted,
+		}
+		if txs := block.Transactions(); trace.TxIndex < uint64(len(txs)) {
+			result.TransactionHash = txs[trace.TxIndex].Hash()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
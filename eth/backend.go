@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/pruner"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
@@ -85,6 +86,7 @@ type Ethereum struct {
 	bloomRequests     chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
 	closeBloomHandler chan struct{}
+	traceIndexer      *core.TraceIndexer // Call frame indexer operating during block imports, nil if disabled
 
 	APIBackend *EthAPIBackend
 
@@ -215,6 +217,12 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		}
 		vmConfig.Tracer = t
 	}
+	if config.TraceIndex {
+		if eth.traceIndexer, err = core.NewTraceIndexer(chainDb, config.TraceIndexHistory); err != nil {
+			return nil, fmt.Errorf("failed to create trace indexer: %v", err)
+		}
+		vmConfig.Tracer = tracing.Join(vmConfig.Tracer, eth.traceIndexer.Hooks())
+	}
 	// Override the chain config with provided settings.
 	var overrides core.ChainOverrides
 	if config.OverrideCancun != nil {
@@ -232,6 +240,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		return nil, err
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
+	if eth.traceIndexer != nil {
+		eth.traceIndexer.Start(eth.blockchain)
+	}
 
 	if config.BlobPool.Datadir != "" {
 		config.BlobPool.Datadir = stack.ResolvePath(config.BlobPool.Datadir)
@@ -247,6 +258,13 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
+	eth.txPool.SetAdmission(config.TxPoolAdmission)
+	if config.TxPoolSnapshot.Path != "" {
+		config.TxPoolSnapshot.Path = stack.ResolvePath(config.TxPoolSnapshot.Path)
+		if err := eth.txPool.EnableSnapshot(config.TxPoolSnapshot); err != nil {
+			return nil, err
+		}
+	}
 	// Permit the downloader to use the trie cache allowance during fast sync
 	cacheLimit := cacheConfig.TrieCleanLimit + cacheConfig.TrieDirtyLimit + cacheConfig.SnapshotLimit
 	if eth.handler, err = newHandler(&handlerConfig{
@@ -335,6 +353,9 @@ func (s *Ethereum) APIs() []rpc.API {
 		}, {
 			Namespace: "eth",
 			Service:   downloader.NewDownloaderAPI(s.handler.downloader, s.blockchain, s.eventMux),
+		}, {
+			Namespace: "bundle",
+			Service:   NewBundleAPI(s),
 		}, {
 			Namespace: "admin",
 			Service:   NewAdminAPI(s),
@@ -365,7 +386,9 @@ func (s *Ethereum) Downloader() *downloader.Downloader { return s.handler.downlo
 func (s *Ethereum) Synced() bool                       { return s.handler.synced.Load() }
 func (s *Ethereum) SetSynced()                         { s.handler.enableSyncedFeatures() }
 func (s *Ethereum) ArchiveMode() bool                  { return s.config.NoPruning }
+func (s *Ethereum) Config() *ethconfig.Config          { return s.config }
 func (s *Ethereum) BloomIndexer() *core.ChainIndexer   { return s.bloomIndexer }
+func (s *Ethereum) TraceIndexer() *core.TraceIndexer   { return s.traceIndexer }
 
 // Protocols returns all the currently configured
 // network protocols to start.
@@ -413,6 +436,9 @@ func (s *Ethereum) Stop() error {
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
 	s.txPool.Close()
+	if s.traceIndexer != nil {
+		s.traceIndexer.Close()
+	}
 	s.blockchain.Stop()
 	s.engine.Close()
 
@@ -123,6 +123,11 @@ type ConsensusAPI struct {
 	invalidTipsets    map[common.Hash]*types.Header // Ephemeral cache to track invalid tipsets and their bad ancestor
 	invalidLock       sync.Mutex                    // Protects the invalid maps from concurrent access
 
+	// The QNG engine API tracks bad blocks in a bounded store instead, which can
+	// optionally be persisted to survive restarts. If set, it supersedes the
+	// ephemeral maps above.
+	invalidQng *invalidAncestors
+
 	// Geth can appear to be stuck or do strange things if the beacon client is
 	// offline or is sending us strange data. Stash some update stats away so
 	// that we can warn the user and not have them open issues on our tracker.
@@ -359,6 +364,9 @@ func (api *ConsensusAPI) forkchoiceUpdated(update engine.ForkchoiceStateV1, payl
 	// sealed by the beacon client. The payload will be requested later, and we
 	// will replace it arbitrarily many times in between.
 	if payloadAttributes != nil {
+		if len(payloadAttributes.Transactions) > 0 {
+			return valid(nil), engine.InvalidPayloadAttributes.With(errors.New("forced transactions are only supported by the QNG engine API"))
+		}
 		args := &miner.BuildPayloadArgs{
 			Parent:       update.HeadBlockHash,
 			Timestamp:    payloadAttributes.Timestamp,
@@ -685,6 +693,10 @@ func (api *ConsensusAPI) delayPayloadImport(block *types.Block) engine.PayloadSt
 // setInvalidAncestor is a callback for the downloader to notify us if a bad block
 // is encountered during the async sync.
 func (api *ConsensusAPI) setInvalidAncestor(invalid *types.Header, origin *types.Header) {
+	if api.invalidQng != nil {
+		api.invalidQng.add(invalid, origin, "")
+		return
+	}
 	api.invalidLock.Lock()
 	defer api.invalidLock.Unlock()
 
@@ -695,6 +707,9 @@ func (api *ConsensusAPI) setInvalidAncestor(invalid *types.Header, origin *types
 // checkInvalidAncestor checks whether the specified chain end links to a known
 // bad ancestor. If yes, it constructs the payload failure response to return.
 func (api *ConsensusAPI) checkInvalidAncestor(check common.Hash, head common.Hash) *engine.PayloadStatusV1 {
+	if api.invalidQng != nil {
+		return api.checkInvalidAncestorQng(check, head)
+	}
 	api.invalidLock.Lock()
 	defer api.invalidLock.Unlock()
 
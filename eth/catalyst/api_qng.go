@@ -2,24 +2,266 @@ package catalyst
 
 import (
 	"errors"
+	"fmt"
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params/forks"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"slices"
 	"strconv"
 	"time"
 )
 
+// maxPayloadBatch is the maximum number of payloads accepted by a single
+// NewPayloadsQng call.
+const maxPayloadBatch = 1024
+
+// All methods provided over the qngengine endpoint.
+var qngCaps = []string{
+	"qngengine_forkchoiceUpdated",
+	"qngengine_forkchoiceUpdatedV2",
+	"qngengine_forkchoiceUpdatedV3",
+	"qngengine_getPayload",
+	"qngengine_getPayloadV2",
+	"qngengine_getPayloadV3",
+	"qngengine_newPayload",
+	"qngengine_newPayloadV2",
+	"qngengine_newPayloadV3",
+	"qngengine_newPayloads",
+	"qngengine_handshake",
+	"qngengine_clearInvalidPayload",
+}
+
+// RegisterQng adds the engine and QNG engine APIs to the full node, in place of
+// Register. The QNG methods are served under the qngengine namespace on the
+// authenticated RPC endpoint, so that an out-of-process MeerDAG driver can steer
+// the chain. Both namespaces share a single consensus API, so the bad blocks hit
+// by the downloader are tracked once for either of them.
+func RegisterQng(stack *node.Node, backend *eth.Ethereum) error {
+	log.Warn("QNG engine API enabled", "protocol", "eth")
+	api := NewConsensusAPIQng(backend)
+	go api.heartbeat()
+	stack.RegisterAPIs([]rpc.API{
+		{
+			Namespace:     "engine",
+			Service:       api,
+			Authenticated: true,
+		},
+		{
+			Namespace:     "qngengine",
+			Service:       &QngEngineAPI{api: api},
+			Authenticated: true,
+		},
+		{
+			Namespace: "debug",
+			Service:   &QngDebugAPI{api: api},
+		},
+	})
+	return nil
+}
+
+// QngEngineAPI exposes the QNG variants of the consensus API over RPC. It only
+// forwards to the Qng methods of the wrapped ConsensusAPI, leaving the standard
+// engine methods to the engine namespace.
+type QngEngineAPI struct {
+	api *ConsensusAPI
+}
+
+// ForkchoiceUpdated forwards to ConsensusAPI.ForkchoiceUpdatedQng.
+func (q *QngEngineAPI) ForkchoiceUpdated(update engine.ForkchoiceStateV1, payloadAttributes *engine.PayloadAttributes) (engine.ForkChoiceResponse, error) {
+	return q.api.ForkchoiceUpdatedQng(update, payloadAttributes)
+}
+
+// ForkchoiceUpdatedV2 forwards to ConsensusAPI.ForkchoiceUpdatedQngV2.
+func (q *QngEngineAPI) ForkchoiceUpdatedV2(update engine.ForkchoiceStateV1, payloadAttributes *engine.PayloadAttributes) (engine.ForkChoiceResponse, error) {
+	return q.api.ForkchoiceUpdatedQngV2(update, payloadAttributes)
+}
+
+// ForkchoiceUpdatedV3 forwards to ConsensusAPI.ForkchoiceUpdatedQngV3.
+func (q *QngEngineAPI) ForkchoiceUpdatedV3(update engine.ForkchoiceStateV1, payloadAttributes *engine.PayloadAttributes) (engine.ForkChoiceResponse, error) {
+	return q.api.ForkchoiceUpdatedQngV3(update, payloadAttributes)
+}
+
+// GetPayload forwards to ConsensusAPI.GetPayloadQng.
+func (q *QngEngineAPI) GetPayload(payloadID engine.PayloadID, full bool) (*engine.ExecutionPayloadEnvelope, error) {
+	return q.api.GetPayloadQng(payloadID, full)
+}
+
+// GetPayloadV2 forwards to ConsensusAPI.GetPayloadQngV2.
+func (q *QngEngineAPI) GetPayloadV2(payloadID engine.PayloadID, full bool) (*engine.ExecutionPayloadEnvelope, error) {
+	return q.api.GetPayloadQngV2(payloadID, full)
+}
+
+// GetPayloadV3 forwards to ConsensusAPI.GetPayloadQngV3.
+func (q *QngEngineAPI) GetPayloadV3(payloadID engine.PayloadID, full bool) (*engine.ExecutionPayloadEnvelope, error) {
+	return q.api.GetPayloadQngV3(payloadID, full)
+}
+
+// NewPayload forwards to ConsensusAPI.NewPayloadQng.
+func (q *QngEngineAPI) NewPayload(params engine.ExecutableData, versionedHashes []common.Hash, beaconRoot *common.Hash) (engine.PayloadStatusV1, error) {
+	return q.api.NewPayloadQng(params, versionedHashes, beaconRoot)
+}
+
+// NewPayloadV2 forwards to ConsensusAPI.NewPayloadQngV2.
+func (q *QngEngineAPI) NewPayloadV2(params engine.ExecutableData) (engine.PayloadStatusV1, error) {
+	return q.api.NewPayloadQngV2(params)
+}
+
+// NewPayloadV3 forwards to ConsensusAPI.NewPayloadQngV3.
+func (q *QngEngineAPI) NewPayloadV3(params engine.ExecutableData, versionedHashes []common.Hash, beaconRoot *common.Hash) (engine.PayloadStatusV1, error) {
+	return q.api.NewPayloadQngV3(params, versionedHashes, beaconRoot)
+}
+
+// NewPayloads forwards to ConsensusAPI.NewPayloadsQng.
+func (q *QngEngineAPI) NewPayloads(params []engine.ExecutableData, versionedHashes [][]common.Hash, beaconRoots []*common.Hash) ([]engine.PayloadStatusV1, error) {
+	return q.api.NewPayloadsQng(params, versionedHashes, beaconRoots)
+}
+
+// ExchangeCapabilities returns the QNG methods provided by this node.
+func (q *QngEngineAPI) ExchangeCapabilities([]string) []string {
+	return q.api.ExchangeCapabilitiesQng()
+}
+
+// Handshake forwards to ConsensusAPI.HandshakeQng.
+func (q *QngEngineAPI) Handshake(remote engine.QngCapabilities) (*engine.QngCapabilities, error) {
+	return q.api.HandshakeQng(remote)
+}
+
+// ClearInvalidPayload forgets a rejected payload, allowing it and all the chain
+// heads built on it to be imported again. It returns whether the payload was
+// tracked at all.
+func (q *QngEngineAPI) ClearInvalidPayload(hash common.Hash) bool {
+	if q.api.invalidQng == nil {
+		return false
+	}
+	return q.api.invalidQng.clear(hash)
+}
+
+// ExchangeCapabilitiesQng returns the QNG methods provided by this node.
+func (api *ConsensusAPI) ExchangeCapabilitiesQng() []string {
+	return qngCaps
+}
+
+// HandshakeQng checks the capabilities required by a MeerDAG driver against the
+// ones supported locally. An error is returned if the engine versions differ or
+// if the driver relies on a method or payload version this node can't serve, so
+// that the driver can refuse to start instead of failing at the first payload.
+// On success the local capabilities are returned.
+func (api *ConsensusAPI) HandshakeQng(remote engine.QngCapabilities) (*engine.QngCapabilities, error) {
+	log.Trace("Engine API request received", "method", "HandshakeQng", "version", remote.Version)
+	local := api.qngCapabilities()
+	if remote.Version != local.Version {
+		return nil, engine.IncompatibleQngEngine.With(fmt.Errorf("engine version mismatch: have %d, want %d", remote.Version, local.Version))
+	}
+	for _, method := range remote.Methods {
+		if !slices.Contains(local.Methods, method) {
+			return nil, engine.IncompatibleQngEngine.With(fmt.Errorf("unsupported method %s", method))
+		}
+	}
+	for _, version := range remote.PayloadVersions {
+		if !slices.Contains(local.PayloadVersions, version) {
+			return nil, engine.IncompatibleQngEngine.With(fmt.Errorf("unsupported payload version %d", version))
+		}
+	}
+	return local, nil
+}
+
+// qngCapabilities assembles the local QNG capabilities. The payload versions
+// only include the ones for which the corresponding fork is scheduled in the
+// chain config.
+func (api *ConsensusAPI) qngCapabilities() *engine.QngCapabilities {
+	config := api.eth.BlockChain().Config()
+	versions := []hexutil.Uint64{hexutil.Uint64(engine.PayloadV1)}
+	if config.ShanghaiTime != nil {
+		versions = append(versions, hexutil.Uint64(engine.PayloadV2))
+	}
+	if config.CancunTime != nil {
+		versions = append(versions, hexutil.Uint64(engine.PayloadV3))
+	}
+	return &engine.QngCapabilities{
+		Version:         engine.QngEngineVersion,
+		PayloadVersions: versions,
+		Methods:         qngCaps,
+	}
+}
+
+// ForkchoiceUpdatedQng is the unversioned QNG forkchoice update. The payload
+// version is derived from the fork active at the requested timestamp.
 func (api *ConsensusAPI) ForkchoiceUpdatedQng(update engine.ForkchoiceStateV1, payloadAttributes *engine.PayloadAttributes) (engine.ForkChoiceResponse, error) {
+	payloadVersion := engine.PayloadV1
+	if payloadAttributes != nil {
+		payloadVersion = api.qngPayloadVersion(payloadAttributes.Timestamp)
+	}
+	return api.forkchoiceUpdatedQng(update, payloadAttributes, payloadVersion)
+}
+
+// ForkchoiceUpdatedQngV2 is the QNG equivalent of ForkchoiceUpdatedV2. It accepts
+// payload attributes for paris and shanghai payloads only.
+func (api *ConsensusAPI) ForkchoiceUpdatedQngV2(update engine.ForkchoiceStateV1, params *engine.PayloadAttributes) (engine.ForkChoiceResponse, error) {
+	if params != nil {
+		if params.BeaconRoot != nil {
+			return engine.STATUS_INVALID, engine.InvalidPayloadAttributes.With(errors.New("unexpected beacon root"))
+		}
+		switch api.eth.BlockChain().Config().LatestFork(params.Timestamp) {
+		case forks.Paris:
+			if params.Withdrawals != nil {
+				return engine.STATUS_INVALID, engine.InvalidPayloadAttributes.With(errors.New("withdrawals before shanghai"))
+			}
+		case forks.Shanghai:
+			if params.Withdrawals == nil {
+				return engine.STATUS_INVALID, engine.InvalidPayloadAttributes.With(errors.New("missing withdrawals"))
+			}
+		default:
+			return engine.STATUS_INVALID, engine.UnsupportedFork.With(errors.New("forkchoiceUpdatedQngV2 must only be called with paris and shanghai payloads"))
+		}
+	}
+	return api.forkchoiceUpdatedQng(update, params, engine.PayloadV2)
+}
+
+// ForkchoiceUpdatedQngV3 is the QNG equivalent of ForkchoiceUpdatedV3. It accepts
+// payload attributes for cancun payloads only.
+func (api *ConsensusAPI) ForkchoiceUpdatedQngV3(update engine.ForkchoiceStateV1, params *engine.PayloadAttributes) (engine.ForkChoiceResponse, error) {
+	if params != nil {
+		if params.Withdrawals == nil {
+			return engine.STATUS_INVALID, engine.InvalidPayloadAttributes.With(errors.New("missing withdrawals"))
+		}
+		if params.BeaconRoot == nil {
+			return engine.STATUS_INVALID, engine.InvalidPayloadAttributes.With(errors.New("missing beacon root"))
+		}
+		if api.eth.BlockChain().Config().LatestFork(params.Timestamp) != forks.Cancun {
+			return engine.STATUS_INVALID, engine.UnsupportedFork.With(errors.New("forkchoiceUpdatedQngV3 must only be called for cancun payloads"))
+		}
+	}
+	return api.forkchoiceUpdatedQng(update, params, engine.PayloadV3)
+}
+
+// qngPayloadVersion returns the payload version matching the fork active at
+// the given timestamp.
+func (api *ConsensusAPI) qngPayloadVersion(timestamp uint64) engine.PayloadVersion {
+	switch api.eth.BlockChain().Config().LatestFork(timestamp) {
+	case forks.Paris:
+		return engine.PayloadV1
+	case forks.Shanghai:
+		return engine.PayloadV2
+	default:
+		return engine.PayloadV3
+	}
+}
+
+func (api *ConsensusAPI) forkchoiceUpdatedQng(update engine.ForkchoiceStateV1, payloadAttributes *engine.PayloadAttributes, payloadVersion engine.PayloadVersion) (engine.ForkChoiceResponse, error) {
 	api.forkchoiceLock.Lock()
 	defer api.forkchoiceLock.Unlock()
 
-	payloadVersion := engine.PayloadV1
-
 	log.Trace("Engine API request received", "method", "ForkchoiceUpdated", "head", update.HeadBlockHash, "finalized", update.FinalizedBlockHash, "safe", update.SafeBlockHash)
 	if update.HeadBlockHash == (common.Hash{}) {
 		log.Warn("Forkchoice requested update to zero hash")
@@ -123,6 +365,12 @@ func (api *ConsensusAPI) ForkchoiceUpdatedQng(update engine.ForkchoiceStateV1, p
 	// sealed by the beacon client. The payload will be requested later, and we
 	// will replace it arbitrarily many times in between.
 	if payloadAttributes != nil {
+		forced, err := payloadAttributes.ForcedTransactions()
+		if err != nil {
+			return valid(nil), engine.InvalidPayloadAttributes.With(err)
+		}
+		// Build towards the gas limit targeted by the network policy, if any
+		number := new(big.Int).Add(block.Number(), common.Big1)
 		args := &miner.BuildPayloadArgs{
 			Parent:       update.HeadBlockHash,
 			Timestamp:    payloadAttributes.Timestamp,
@@ -130,6 +378,8 @@ func (api *ConsensusAPI) ForkchoiceUpdatedQng(update engine.ForkchoiceStateV1, p
 			Random:       payloadAttributes.Random,
 			Withdrawals:  payloadAttributes.Withdrawals,
 			BeaconRoot:   payloadAttributes.BeaconRoot,
+			GasLimit:     api.eth.BlockChain().Config().TargetGasLimit(number),
+			Transactions: forced,
 			Version:      payloadVersion,
 		}
 		id := args.Id()
@@ -149,7 +399,36 @@ func (api *ConsensusAPI) ForkchoiceUpdatedQng(update engine.ForkchoiceStateV1, p
 	return valid(nil), nil
 }
 
+// GetPayloadQng returns a cached payload by id, regardless of its version.
 func (api *ConsensusAPI) GetPayloadQng(payloadID engine.PayloadID, full bool) (*engine.ExecutionPayloadEnvelope, error) {
+	return api.getPayloadQng(payloadID, full)
+}
+
+// GetPayloadQngV2 returns a cached paris or shanghai payload by id. The blobs
+// bundle is stripped, as it is not part of the pre-cancun envelope.
+func (api *ConsensusAPI) GetPayloadQngV2(payloadID engine.PayloadID, full bool) (*engine.ExecutionPayloadEnvelope, error) {
+	if !payloadID.Is(engine.PayloadV1, engine.PayloadV2) {
+		return nil, engine.UnsupportedFork
+	}
+	data, err := api.getPayloadQng(payloadID, full)
+	if err != nil {
+		return nil, err
+	}
+	return &engine.ExecutionPayloadEnvelope{
+		ExecutionPayload: data.ExecutionPayload,
+		BlockValue:       data.BlockValue,
+	}, nil
+}
+
+// GetPayloadQngV3 returns a cached cancun payload by id.
+func (api *ConsensusAPI) GetPayloadQngV3(payloadID engine.PayloadID, full bool) (*engine.ExecutionPayloadEnvelope, error) {
+	if !payloadID.Is(engine.PayloadV3) {
+		return nil, engine.UnsupportedFork
+	}
+	return api.getPayloadQng(payloadID, full)
+}
+
+func (api *ConsensusAPI) getPayloadQng(payloadID engine.PayloadID, full bool) (*engine.ExecutionPayloadEnvelope, error) {
 	log.Trace("Engine API request received", "method", "GetPayload", "id", payloadID)
 	data := api.localBlocks.get(payloadID, full)
 	if data == nil {
@@ -158,7 +437,220 @@ func (api *ConsensusAPI) GetPayloadQng(payloadID engine.PayloadID, full bool) (*
 	return data, nil
 }
 
+// NewPayloadQng inserts a QNG payload into the chain without any fork specific
+// parameter validation.
 func (api *ConsensusAPI) NewPayloadQng(params engine.ExecutableData, versionedHashes []common.Hash, beaconRoot *common.Hash) (engine.PayloadStatusV1, error) {
+	return api.newPayloadQng(params, versionedHashes, beaconRoot)
+}
+
+// NewPayloadQngV2 is the QNG equivalent of NewPayloadV2, accepting paris and
+// shanghai payloads only.
+func (api *ConsensusAPI) NewPayloadQngV2(params engine.ExecutableData) (engine.PayloadStatusV1, error) {
+	if api.eth.BlockChain().Config().IsCancun(api.eth.BlockChain().Config().LondonBlock, params.Timestamp) {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("can't use newPayloadQngV2 post-cancun"))
+	}
+	if api.eth.BlockChain().Config().LatestFork(params.Timestamp) == forks.Shanghai {
+		if params.Withdrawals == nil {
+			return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("nil withdrawals post-shanghai"))
+		}
+	} else {
+		if params.Withdrawals != nil {
+			return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("non-nil withdrawals pre-shanghai"))
+		}
+	}
+	if params.ExcessBlobGas != nil {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("non-nil excessBlobGas pre-cancun"))
+	}
+	if params.BlobGasUsed != nil {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("non-nil blobGasUsed pre-cancun"))
+	}
+	return api.newPayloadQng(params, nil, nil)
+}
+
+// NewPayloadQngV3 is the QNG equivalent of NewPayloadV3, accepting cancun
+// payloads only.
+func (api *ConsensusAPI) NewPayloadQngV3(params engine.ExecutableData, versionedHashes []common.Hash, beaconRoot *common.Hash) (engine.PayloadStatusV1, error) {
+	if params.Withdrawals == nil {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("nil withdrawals post-shanghai"))
+	}
+	if params.ExcessBlobGas == nil {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("nil excessBlobGas post-cancun"))
+	}
+	if params.BlobGasUsed == nil {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("nil blobGasUsed post-cancun"))
+	}
+	if versionedHashes == nil {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("nil versionedHashes post-cancun"))
+	}
+	if beaconRoot == nil {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.InvalidParams.With(errors.New("nil beaconRoot post-cancun"))
+	}
+	if api.eth.BlockChain().Config().LatestFork(params.Timestamp) != forks.Cancun {
+		return engine.PayloadStatusV1{Status: engine.INVALID}, engine.UnsupportedFork.With(errors.New("newPayloadQngV3 must only be called for cancun payloads"))
+	}
+	return api.newPayloadQng(params, versionedHashes, beaconRoot)
+}
+
+// NewPayloadsQng imports an ordered, contiguous batch of QNG payloads in one go,
+// running them through the regular chain import pipeline instead of inserting
+// them one by one. It's meant for a MeerDAG driver catching up after downtime.
+// The versioned hashes and beacon roots are optional, but if given, they must
+// match the payloads one-to-one. A status is returned for every payload; once
+// a payload fails, all its descendants in the batch are rejected too.
+func (api *ConsensusAPI) NewPayloadsQng(params []engine.ExecutableData, versionedHashes [][]common.Hash, beaconRoots []*common.Hash) ([]engine.PayloadStatusV1, error) {
+	if len(params) > maxPayloadBatch {
+		return nil, engine.TooLargeRequest.With(fmt.Errorf("requested batch too large: %v", len(params)))
+	}
+	if versionedHashes != nil && len(versionedHashes) != len(params) {
+		return nil, engine.InvalidParams.With(fmt.Errorf("versionedHashes length mismatch: have %d, want %d", len(versionedHashes), len(params)))
+	}
+	if beaconRoots != nil && len(beaconRoots) != len(params) {
+		return nil, engine.InvalidParams.With(fmt.Errorf("beaconRoots length mismatch: have %d, want %d", len(beaconRoots), len(params)))
+	}
+	api.newPayloadLock.Lock()
+	defer api.newPayloadLock.Unlock()
+
+	log.Trace("Engine API request received", "method", "NewPayloadsQng", "count", len(params))
+	var (
+		statuses = make([]engine.PayloadStatusV1, len(params))
+		blocks   = make(types.Blocks, 0, len(params))
+		rejected *engine.PayloadStatusV1 // Status of the first payload rejected upfront, if any
+	)
+	// rejectFrom marks the payload at index i with the given status and all its
+	// descendants as built on an invalid ancestor.
+	rejectFrom := func(i int, status engine.PayloadStatusV1) []engine.PayloadStatusV1 {
+		statuses[i] = status
+		for j := i + 1; j < len(params); j++ {
+			failure := "links to previously rejected block"
+			statuses[j] = engine.PayloadStatusV1{Status: engine.INVALID, LatestValidHash: status.LatestValidHash, ValidationError: &failure}
+		}
+		return statuses
+	}
+	// reject cuts the batch before the payload at index i, which is rejected
+	// once the payloads before it are imported.
+	reject := func(i int, status engine.PayloadStatusV1) {
+		blocks, rejected = blocks[:i], &status
+	}
+	// finish rejects the payloads cut from the batch, if any.
+	finish := func() []engine.PayloadStatusV1 {
+		if rejected != nil {
+			return rejectFrom(len(blocks), *rejected)
+		}
+		return statuses
+	}
+	for i, data := range params {
+		var (
+			hashes []common.Hash
+			root   *common.Hash
+		)
+		if versionedHashes != nil {
+			hashes = versionedHashes[i]
+		}
+		if beaconRoots != nil {
+			root = beaconRoots[i]
+		}
+		block, err := engine.ExecutableDataToBlockQng(data, hashes, root)
+		if err != nil {
+			log.Warn("Invalid NewPayloadsQng params", "index", i, "number", data.Number, "hash", data.BlockHash, "error", err)
+			reject(i, api.invalid(err, nil))
+			break
+		}
+		if i > 0 && (block.NumberU64() != blocks[i-1].NumberU64()+1 || block.ParentHash() != blocks[i-1].Hash()) {
+			return nil, engine.InvalidParams.With(fmt.Errorf("non contiguous payload %d: #%d [%x..] parent [%x..]", i, block.NumberU64(), block.Hash().Bytes()[:4], block.ParentHash().Bytes()[:4]))
+		}
+		blocks = append(blocks, block)
+	}
+	// Stash away the last update to warn the user if the beacon client goes offline
+	api.lastNewPayloadLock.Lock()
+	api.lastNewPayloadUpdate = time.Now()
+	api.lastNewPayloadLock.Unlock()
+
+	// Skip all the payloads we already have locally, reporting them as valid
+	first := 0
+	for ; first < len(blocks); first++ {
+		if api.eth.BlockChain().GetBlockByHash(blocks[first].Hash()) == nil {
+			break
+		}
+		hash := blocks[first].Hash()
+		statuses[first] = engine.PayloadStatusV1{Status: engine.VALID, LatestValidHash: &hash}
+	}
+	// If any of the remaining payloads was rejected previously, keep rejecting it
+	// along with everything built on top
+	for i := first; i < len(blocks); i++ {
+		if res := api.checkInvalidAncestor(blocks[i].Hash(), blocks[i].Hash()); res != nil {
+			reject(i, *res)
+			break
+		}
+	}
+	if first == len(blocks) {
+		return finish(), nil
+	}
+	// If the parent of the batch is missing or we can't import directly into the
+	// database, stash the payloads away the same way NewPayloadQng would.
+	parent := api.eth.BlockChain().GetBlock(blocks[first].ParentHash(), blocks[first].NumberU64()-1)
+	if parent == nil || api.eth.SyncMode() != downloader.FullSync {
+		for i := first; i < len(blocks); i++ {
+			statuses[i] = api.delayPayloadImport(blocks[i])
+		}
+		return finish(), nil
+	}
+	prev := parent.Header()
+	for i := first; i < len(blocks); i++ {
+		if blocks[i].Time() <= prev.Time {
+			log.Warn("Invalid timestamp", "parent", prev.Time, "block", blocks[i].Time())
+			reject(i, api.invalid(errors.New("invalid timestamp"), prev))
+			break
+		}
+		prev = blocks[i].Header()
+	}
+	if first == len(blocks) {
+		return finish(), nil
+	}
+	if !api.eth.BlockChain().HasBlockAndState(parent.Hash(), parent.NumberU64()) {
+		for i := first; i < len(blocks); i++ {
+			api.remoteBlocks.put(blocks[i].Hash(), blocks[i].Header())
+			statuses[i] = engine.PayloadStatusV1{Status: engine.ACCEPTED}
+		}
+		log.Warn("State not available, ignoring new payloads")
+		return finish(), nil
+	}
+	log.Trace("Inserting blocks without sethead", "count", len(blocks)-first, "number", blocks[first].Number())
+	n, err := api.eth.BlockChain().InsertChainWithoutSetHead(blocks[first:])
+	for i := first; i < first+n; i++ {
+		hash := blocks[i].Hash()
+		statuses[i] = engine.PayloadStatusV1{Status: engine.VALID, LatestValidHash: &hash}
+	}
+	if err != nil {
+		bad := blocks[first+n]
+		log.Warn("NewPayloadsQng: inserting block failed", "number", bad.Number(), "hash", bad.Hash(), "error", err)
+		api.markInvalidQng(bad, err)
+
+		latestValid := parent.Header()
+		if n > 0 {
+			latestValid = blocks[first+n-1].Header()
+		}
+		return rejectFrom(first+n, api.invalid(err, latestValid)), nil
+	}
+	for i := first + n; i < len(blocks); i++ {
+		hash := blocks[i].Hash()
+		statuses[i] = engine.PayloadStatusV1{Status: engine.VALID, LatestValidHash: &hash}
+	}
+	return finish(), nil
+}
+
+// markInvalidQng records a block that failed to import as a bad block.
+func (api *ConsensusAPI) markInvalidQng(block *types.Block, err error) {
+	if api.invalidQng != nil {
+		api.invalidQng.add(block.Header(), block.Header(), err.Error())
+		return
+	}
+	api.invalidLock.Lock()
+	api.invalidBlocksHits[block.Hash()] = 1
+	api.invalidTipsets[block.Hash()] = block.Header()
+	api.invalidLock.Unlock()
+}
+
+func (api *ConsensusAPI) newPayloadQng(params engine.ExecutableData, versionedHashes []common.Hash, beaconRoot *common.Hash) (engine.PayloadStatusV1, error) {
 	// The locking here is, strictly, not required. Without these locks, this can happen:
 	//
 	// 1. NewPayload( execdata-N ) is invoked from the CL. It goes all the way down to
@@ -251,27 +743,67 @@ func (api *ConsensusAPI) NewPayloadQng(params engine.ExecutableData, versionedHa
 	}
 	log.Trace("Inserting block without sethead", "hash", block.Hash(), "number", block.Number())
 	if err := api.eth.BlockChain().InsertBlockWithoutSetHead(block); err != nil {
-		log.Warn("NewPayloadV1: inserting block failed", "error", err)
-
-		api.invalidLock.Lock()
-		api.invalidBlocksHits[block.Hash()] = 1
-		api.invalidTipsets[block.Hash()] = block.Header()
-		api.invalidLock.Unlock()
-
+		log.Warn("NewPayloadQng: inserting block failed", "error", err)
+		api.markInvalidQng(block, err)
 		return api.invalid(err, parent.Header()), nil
 	}
 	hash := block.Hash()
 	return engine.PayloadStatusV1{Status: engine.VALID, LatestValidHash: &hash}, nil
 }
 
+// NewConsensusAPIQng creates a new consensus api for a QNG driven backend. Bad
+// blocks are tracked in a bounded store, persisted to the chain database if the
+// backend is configured to do so.
 func NewConsensusAPIQng(eth *eth.Ethereum) *ConsensusAPI {
-	api := &ConsensusAPI{
-		eth:               eth,
-		remoteBlocks:      newHeaderQueue(),
-		localBlocks:       newPayloadQueue(),
-		invalidBlocksHits: make(map[common.Hash]int),
-		invalidTipsets:    make(map[common.Hash]*types.Header),
-	}
-	eth.Downloader().SetBadBlockCallback(api.setInvalidAncestor)
+	var db ethdb.KeyValueStore
+	if eth.Config().QngPersistInvalidPayloads {
+		db = eth.ChainDb()
+	}
+	api := newConsensusAPIWithoutHeartbeat(eth)
+	api.invalidQng = newInvalidAncestors(db)
 	return api
 }
+
+// checkInvalidAncestorQng is the counterpart of checkInvalidAncestor operating
+// on the bounded invalid ancestor store.
+func (api *ConsensusAPI) checkInvalidAncestorQng(check common.Hash, head common.Hash) *engine.PayloadStatusV1 {
+	invalid := api.invalidQng.check(check, head)
+	if invalid == nil {
+		return nil
+	}
+	// If the last valid hash is the terminal pow block, return 0x0 for latest valid hash
+	lastValid := &invalid.ParentHash
+	if header := api.eth.BlockChain().GetHeader(invalid.ParentHash, invalid.Number.Uint64()-1); header != nil && header.Difficulty.Sign() != 0 {
+		lastValid = &common.Hash{}
+	}
+	failure := "links to previously rejected block"
+	return &engine.PayloadStatusV1{
+		Status:          engine.INVALID,
+		LatestValidHash: lastValid,
+		ValidationError: &failure,
+	}
+}
+
+// QngDebugAPI exposes debugging endpoints of the QNG engine API.
+type QngDebugAPI struct {
+	api *ConsensusAPI
+}
+
+// GetInvalidPayloads returns the payloads rejected by the QNG engine API.
+func (d *QngDebugAPI) GetInvalidPayloads() []*InvalidPayload {
+	if d.api.invalidQng == nil {
+		return nil
+	}
+	return d.api.invalidQng.list()
+}
+
+// PayloadBuildStats returns the building progress of a payload requested through
+// the QNG engine API: the rebuild iterations, the transactions they skipped and
+// the revenue gained over the empty payload. The payload is not resolved.
+func (d *QngDebugAPI) PayloadBuildStats(id engine.PayloadID) (*miner.PayloadBuildStats, error) {
+	stats := d.api.localBlocks.stats(id)
+	if stats == nil {
+		return nil, engine.UnknownPayload
+	}
+	return stats, nil
+}
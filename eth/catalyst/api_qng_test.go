@@ -0,0 +1,339 @@
+package catalyst
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestQngVersionedPayloads(t *testing.T) {
+	genesis, blocks := generateMergeChain(10, true)
+	// Set shanghai time to the first post-merge block
+	time := blocks[len(blocks)-1].Time() + 5
+	genesis.Config.ShanghaiTime = &time
+
+	n, ethservice := startEthService(t, genesis, blocks)
+	defer n.Close()
+
+	api := NewConsensusAPIQng(ethservice)
+	parent := ethservice.BlockChain().CurrentHeader()
+	fcState := engine.ForkchoiceStateV1{HeadBlockHash: parent.Hash()}
+
+	// Cancun attributes must be rejected on a shanghai chain
+	beaconRoot := common.Hash{0x42}
+	_, err := api.ForkchoiceUpdatedQngV3(fcState, &engine.PayloadAttributes{
+		Timestamp:   parent.Time + 5,
+		Withdrawals: make([]*types.Withdrawal, 0),
+		BeaconRoot:  &beaconRoot,
+	})
+	if err == nil {
+		t.Fatalf("expected cancun attributes to be rejected")
+	}
+	// The unversioned entry point must pick the shanghai payload version
+	blockParams := &engine.PayloadAttributes{
+		Timestamp:   parent.Time + 5,
+		Withdrawals: make([]*types.Withdrawal, 0),
+	}
+	resp, err := api.ForkchoiceUpdatedQng(fcState, blockParams)
+	if err != nil {
+		t.Fatalf("error preparing payload, err=%v", err)
+	}
+	if resp.PayloadStatus.Status != engine.VALID {
+		t.Fatalf("unexpected status (got: %s, want: %s)", resp.PayloadStatus.Status, engine.VALID)
+	}
+	if resp.PayloadID == nil || resp.PayloadID.Version() != engine.PayloadV2 {
+		t.Fatalf("unexpected payload id: %v", resp.PayloadID)
+	}
+	if _, err := api.GetPayloadQngV3(*resp.PayloadID, false); err == nil {
+		t.Fatalf("expected v3 retrieval of a v2 payload to fail")
+	}
+	envelope, err := api.GetPayloadQngV2(*resp.PayloadID, false)
+	if err != nil {
+		t.Fatalf("error getting payload, err=%v", err)
+	}
+	if envelope.BlobsBundle != nil {
+		t.Fatalf("unexpected blobs bundle in v2 envelope")
+	}
+	if _, err := api.NewPayloadQngV3(*envelope.ExecutionPayload, []common.Hash{}, &beaconRoot); err == nil {
+		t.Fatalf("expected v3 import of a shanghai payload to fail")
+	}
+	status, err := api.NewPayloadQngV2(*envelope.ExecutionPayload)
+	if err != nil {
+		t.Fatalf("error validating payload: %v", err)
+	}
+	if status.Status != engine.VALID {
+		t.Fatalf("invalid payload: %v", status.Status)
+	}
+}
+
+func TestRegisterQng(t *testing.T) {
+	genesis, blocks := generateMergeChain(10, true)
+
+	n, err := node.New(&node.Config{
+		P2P: p2p.Config{
+			ListenAddr:  "0.0.0.0:0",
+			NoDiscovery: true,
+		}})
+	if err != nil {
+		t.Fatal("can't create node:", err)
+	}
+	defer n.Close()
+
+	ethcfg := &ethconfig.Config{Genesis: genesis, SyncMode: downloader.FullSync, TrieTimeout: time.Minute, TrieDirtyCache: 256, TrieCleanCache: 256}
+	ethservice, err := eth.New(n, ethcfg)
+	if err != nil {
+		t.Fatal("can't create eth service:", err)
+	}
+	if err := RegisterQng(n, ethservice); err != nil {
+		t.Fatal("can't register QNG engine API:", err)
+	}
+	if err := n.Start(); err != nil {
+		t.Fatal("can't start node:", err)
+	}
+	if _, err := ethservice.BlockChain().InsertChain(blocks); err != nil {
+		t.Fatal("can't import test blocks:", err)
+	}
+	client := n.Attach()
+	defer client.Close()
+
+	var resp engine.ForkChoiceResponse
+	head := engine.ForkchoiceStateV1{HeadBlockHash: blocks[len(blocks)-1].Hash()}
+	if err := client.Call(&resp, "qngengine_forkchoiceUpdated", head, nil); err != nil {
+		t.Fatalf("forkchoice update failed: %v", err)
+	}
+	if resp.PayloadStatus.Status != engine.VALID {
+		t.Fatalf("unexpected status (got: %s, want: %s)", resp.PayloadStatus.Status, engine.VALID)
+	}
+	// The standard engine methods must not leak into the QNG namespace
+	if err := client.Call(&resp, "qngengine_forkchoiceUpdatedV1", head, nil); err == nil {
+		t.Fatalf("expected standard engine method to be unavailable")
+	}
+	// The standard engine API must still be served alongside
+	if err := client.Call(&resp, "engine_forkchoiceUpdatedV1", head, nil); err != nil {
+		t.Fatalf("standard forkchoice update failed: %v", err)
+	}
+}
+
+func TestQngCapabilities(t *testing.T) {
+	// Every advertised capability must map to a method of the RPC service
+	service := reflect.TypeOf(&QngEngineAPI{})
+	for _, method := range qngCaps {
+		name, ok := strings.CutPrefix(method, "qngengine_")
+		if !ok {
+			t.Fatalf("capability %s outside of the qngengine namespace", method)
+		}
+		if _, ok := service.MethodByName(strings.ToUpper(name[:1]) + name[1:]); !ok {
+			t.Fatalf("capability %s not served", method)
+		}
+	}
+	genesis, blocks := generateMergeChain(10, true)
+	time := blocks[len(blocks)-1].Time() + 5
+	genesis.Config.ShanghaiTime = &time
+
+	n, ethservice := startEthService(t, genesis, blocks)
+	defer n.Close()
+
+	api := NewConsensusAPIQng(ethservice)
+	tests := []struct {
+		remote engine.QngCapabilities
+		fail   bool
+	}{
+		{engine.QngCapabilities{Version: engine.QngEngineVersion}, false},
+		{engine.QngCapabilities{Version: engine.QngEngineVersion, PayloadVersions: []hexutil.Uint64{1, 2}, Methods: []string{"qngengine_newPayloadV2"}}, false},
+		{engine.QngCapabilities{Version: engine.QngEngineVersion + 1}, true},
+		{engine.QngCapabilities{Version: engine.QngEngineVersion, PayloadVersions: []hexutil.Uint64{3}}, true},
+		{engine.QngCapabilities{Version: engine.QngEngineVersion, Methods: []string{"qngengine_newPayloadV4"}}, true},
+	}
+	for i, tt := range tests {
+		local, err := api.HandshakeQng(tt.remote)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: expected handshake failure", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: handshake failed: %v", i, err)
+			continue
+		}
+		if want := []hexutil.Uint64{1, 2}; !reflect.DeepEqual(local.PayloadVersions, want) {
+			t.Errorf("test %d: payload versions mismatch: have %v, want %v", i, local.PayloadVersions, want)
+		}
+	}
+}
+
+func TestNewPayloadsQng(t *testing.T) {
+	genesis, blocks := generateMergeChain(10, true)
+	n, ethservice := startEthService(t, genesis, blocks[:4])
+	defer n.Close()
+
+	api := NewConsensusAPIQng(ethservice)
+
+	// Corrupt the last payload of the batch to check the failure reporting
+	var params []engine.ExecutableData
+	for _, block := range blocks[2:] {
+		params = append(params, *engine.BlockToExecutableData(block, nil, nil).ExecutionPayload)
+	}
+	params[len(params)-1].StateRoot = common.Hash{0x01}
+	setBlockhash(&params[len(params)-1])
+
+	statuses, err := api.NewPayloadsQng(params, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to import payloads: %v", err)
+	}
+	if len(statuses) != len(params) {
+		t.Fatalf("status count mismatch: have %d, want %d", len(statuses), len(params))
+	}
+	for i, status := range statuses[:len(statuses)-1] {
+		if status.Status != engine.VALID || *status.LatestValidHash != params[i].BlockHash {
+			t.Fatalf("payload %d: unexpected status %v", i, status.Status)
+		}
+	}
+	if status := statuses[len(statuses)-1]; status.Status != engine.INVALID || *status.LatestValidHash != params[len(params)-2].BlockHash {
+		t.Fatalf("corrupted payload: unexpected status %v", status.Status)
+	}
+	// The payloads must be imported, but not set as head
+	if head := ethservice.BlockChain().CurrentBlock(); head.Hash() != blocks[3].Hash() {
+		t.Fatalf("chain head changed: have #%d, want #%d", head.Number, blocks[3].Number())
+	}
+	if !ethservice.BlockChain().HasBlockAndState(blocks[8].Hash(), blocks[8].NumberU64()) {
+		t.Fatalf("batch not imported")
+	}
+	// Non contiguous batches must be rejected as a whole
+	if _, err := api.NewPayloadsQng([]engine.ExecutableData{params[0], params[2]}, nil, nil); err == nil {
+		t.Fatalf("expected non contiguous batch to be rejected")
+	}
+}
+
+func TestNewPayloadsQngInvalidMiddle(t *testing.T) {
+	genesis, blocks := generateMergeChain(10, true)
+	n, ethservice := startEthService(t, genesis, blocks[:4])
+	defer n.Close()
+
+	api := NewConsensusAPIQng(ethservice)
+
+	// Corrupt a payload in the middle of the batch, the ones before must still
+	// be imported and the ones after rejected
+	var params []engine.ExecutableData
+	for _, block := range blocks[2:] {
+		params = append(params, *engine.BlockToExecutableData(block, nil, nil).ExecutionPayload)
+	}
+	params[3].BlockHash = common.Hash{0x01}
+
+	statuses, err := api.NewPayloadsQng(params, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to import payloads: %v", err)
+	}
+	for i, status := range statuses[:3] {
+		if status.Status != engine.VALID || *status.LatestValidHash != params[i].BlockHash {
+			t.Fatalf("payload %d: unexpected status %v", i, status.Status)
+		}
+	}
+	for i, status := range statuses[3:] {
+		if status.Status != engine.INVALID {
+			t.Fatalf("payload %d: unexpected status %v", i+3, status.Status)
+		}
+	}
+	if !ethservice.BlockChain().HasBlockAndState(blocks[4].Hash(), blocks[4].NumberU64()) {
+		t.Fatalf("valid prefix not imported")
+	}
+	if ethservice.BlockChain().HasBlock(blocks[5].Hash(), blocks[5].NumberU64()) {
+		t.Fatalf("rejected payload imported")
+	}
+}
+
+func TestQngPayloadBuildStats(t *testing.T) {
+	genesis, blocks := generateMergeChain(10, true)
+	n, ethservice := startEthService(t, genesis, blocks)
+	defer n.Close()
+
+	api := NewConsensusAPIQng(ethservice)
+	debug := &QngDebugAPI{api: api}
+
+	if _, err := debug.PayloadBuildStats(engine.PayloadID{0x1}); err == nil {
+		t.Fatalf("expected unknown payload to be rejected")
+	}
+	parent := ethservice.BlockChain().CurrentHeader()
+	resp, err := api.ForkchoiceUpdatedQng(engine.ForkchoiceStateV1{HeadBlockHash: parent.Hash()}, &engine.PayloadAttributes{Timestamp: parent.Time + 5})
+	if err != nil {
+		t.Fatalf("error preparing payload, err=%v", err)
+	}
+	stats, err := debug.PayloadBuildStats(*resp.PayloadID)
+	if err != nil {
+		t.Fatalf("failed to retrieve build stats: %v", err)
+	}
+	if stats.ID != *resp.PayloadID || stats.Resolved {
+		t.Fatalf("unexpected build stats: %+v", stats)
+	}
+	// Retrieving the stats must not resolve the payload
+	if _, err := api.GetPayloadQng(*resp.PayloadID, true); err != nil {
+		t.Fatalf("error getting payload, err=%v", err)
+	}
+	if stats, _ := debug.PayloadBuildStats(*resp.PayloadID); !stats.Resolved || stats.Iterations == 0 {
+		t.Fatalf("unexpected build stats after resolution: %+v", stats)
+	}
+}
+
+func TestQngForcedTransactions(t *testing.T) {
+	genesis, blocks := generateMergeChain(10, true)
+	n, ethservice := startEthService(t, genesis, blocks)
+	defer n.Close()
+
+	var (
+		api    = NewConsensusAPIQng(ethservice)
+		parent = ethservice.BlockChain().CurrentHeader()
+		signer = types.LatestSigner(ethservice.BlockChain().Config())
+		state  = engine.ForkchoiceStateV1{HeadBlockHash: parent.Hash()}
+	)
+	statedb, _ := ethservice.BlockChain().StateAt(parent.Root)
+	nonce := statedb.GetNonce(testAddr)
+
+	var raw [][]byte
+	for i := 0; i < 2; i++ {
+		tx := types.MustSignNewTx(testKey, signer, &types.LegacyTx{
+			Nonce:    nonce + uint64(i),
+			To:       &common.Address{0x42},
+			Value:    big.NewInt(1),
+			Gas:      params.TxGas,
+			GasPrice: big.NewInt(2 * params.InitialBaseFee),
+		})
+		blob, _ := tx.MarshalBinary()
+		raw = append(raw, blob)
+	}
+	// Forced transactions must be placed at the top of even the empty payload
+	resp, err := api.ForkchoiceUpdatedQng(state, &engine.PayloadAttributes{Timestamp: parent.Time + 5, Transactions: raw})
+	if err != nil {
+		t.Fatalf("error preparing payload, err=%v", err)
+	}
+	envelope, err := api.GetPayloadQng(*resp.PayloadID, false)
+	if err != nil {
+		t.Fatalf("error getting payload, err=%v", err)
+	}
+	if !reflect.DeepEqual(envelope.ExecutionPayload.Transactions, raw) {
+		t.Fatalf("forced transactions mismatch: have %d txs, want %d", len(envelope.ExecutionPayload.Transactions), len(raw))
+	}
+	// Failing forced transactions must fail the build
+	if _, err := api.ForkchoiceUpdatedQng(state, &engine.PayloadAttributes{Timestamp: parent.Time + 6, Transactions: raw[1:]}); err == nil {
+		t.Fatalf("expected out of order forced transaction to fail the build")
+	}
+	if _, err := api.ForkchoiceUpdatedQng(state, &engine.PayloadAttributes{Timestamp: parent.Time + 6, Transactions: [][]byte{{0x01}}}); err == nil {
+		t.Fatalf("expected malformed forced transaction to be rejected")
+	}
+	// The standard engine API must not silently drop forced transactions
+	if _, err := api.ForkchoiceUpdatedV1(state, &engine.PayloadAttributes{Timestamp: parent.Time + 6, Transactions: raw}); err == nil {
+		t.Fatalf("expected forced transactions to be rejected by the standard engine API")
+	}
+}
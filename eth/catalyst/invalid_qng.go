@@ -0,0 +1,192 @@
+package catalyst
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// InvalidPayload describes a payload rejected by the QNG engine API.
+type InvalidPayload struct {
+	Hash       common.Hash    `json:"hash"`
+	Number     hexutil.Uint64 `json:"number"`
+	ParentHash common.Hash    `json:"parentHash"`
+	Hits       int            `json:"hits"`
+	Reason     string         `json:"reason"`
+	Time       hexutil.Uint64 `json:"time"`
+}
+
+// invalidPayload is a bad block tracked by the invalid ancestor store.
+type invalidPayload struct {
+	header *types.Header
+	hits   int    // Number of times the block was referenced since it was rejected
+	reason string // Error the block was rejected with, if known
+	time   uint64 // Unix time of the first rejection
+}
+
+// invalidAncestors is a bounded store of the bad blocks seen by the QNG engine
+// API, along with the chain heads that were built on top of them. Contrary to
+// the ephemeral maps of the standard engine API, both sets are capped by LRU
+// eviction and the bad blocks can optionally be persisted, so that a restart
+// doesn't make the node re-accept a DAG branch it already rejected. Persisted
+// bad blocks are only forgotten when evicted by newer ones or cleared manually.
+type invalidAncestors struct {
+	db ethdb.KeyValueStore // Database to persist bad blocks into, nil if disabled
+
+	payloads lru.BasicLRU[common.Hash, *invalidPayload] // Bad blocks by hash
+	tipsets  lru.BasicLRU[common.Hash, common.Hash]     // Bad ancestor hashes by chain head
+	lock     sync.Mutex
+}
+
+// newInvalidAncestors creates an invalid ancestor store, loading any previously
+// persisted bad blocks if a database is provided.
+func newInvalidAncestors(db ethdb.KeyValueStore) *invalidAncestors {
+	store := &invalidAncestors{
+		db:       db,
+		payloads: lru.NewBasicLRU[common.Hash, *invalidPayload](invalidTipsetsCap),
+		tipsets:  lru.NewBasicLRU[common.Hash, common.Hash](invalidTipsetsCap),
+	}
+	if db != nil {
+		// Load the oldest rejections first for the cap to keep the latest ones
+		stored := rawdb.ReadInvalidPayloads(db)
+		slices.SortStableFunc(stored, func(a, b *rawdb.InvalidPayload) int {
+			return cmp.Compare(a.Time, b.Time)
+		})
+		for _, payload := range stored {
+			hash := payload.Header.Hash()
+			store.track(hash, &invalidPayload{header: payload.Header, reason: payload.Reason, time: payload.Time})
+			store.tipsets.Add(hash, hash)
+		}
+		if n := store.payloads.Len(); n > 0 {
+			log.Info("Loaded invalid QNG payloads", "count", n)
+		}
+	}
+	return store
+}
+
+// add marks the invalid block as bad and the origin as a chain head built on it.
+func (s *invalidAncestors) add(invalid *types.Header, origin *types.Header, reason string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	hash := invalid.Hash()
+	if payload, ok := s.payloads.Get(hash); ok {
+		payload.hits++
+		if payload.reason == "" && reason != "" {
+			payload.reason = reason
+			s.persist(payload)
+		}
+	} else {
+		payload = &invalidPayload{header: invalid, hits: 1, reason: reason, time: uint64(time.Now().Unix())}
+		s.track(hash, payload)
+		s.persist(payload)
+	}
+	s.tipsets.Add(hash, hash)
+	s.tipsets.Add(origin.Hash(), hash)
+}
+
+// check returns the bad ancestor of the specified chain end, if any. If the bad
+// ancestor was hit too many times, it's evicted to allow reprocessing it. The
+// head is marked as built on the bad ancestor if it differs from check.
+func (s *invalidAncestors) check(check common.Hash, head common.Hash) *types.Header {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	badHash, ok := s.tipsets.Get(check)
+	if !ok {
+		return nil
+	}
+	payload, ok := s.payloads.Get(badHash)
+	if !ok {
+		// The bad block was already evicted, drop the dangling tipset
+		s.tipsets.Remove(check)
+		return nil
+	}
+	payload.hits++
+	if s.db == nil && payload.hits >= invalidBlockHitEviction {
+		log.Warn("Too many bad block import attempt, trying", "number", payload.header.Number, "hash", badHash)
+		s.remove(badHash)
+		return nil
+	}
+	if check != head {
+		log.Warn("Marked new chain head as invalid", "hash", head, "badnumber", payload.header.Number, "badhash", badHash)
+		s.tipsets.Add(head, badHash)
+	}
+	return payload.header
+}
+
+// list returns all the tracked bad blocks.
+func (s *invalidAncestors) list() []*InvalidPayload {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var payloads []*InvalidPayload
+	for _, hash := range s.payloads.Keys() {
+		payload, _ := s.payloads.Peek(hash)
+		payloads = append(payloads, &InvalidPayload{
+			Hash:       hash,
+			Number:     hexutil.Uint64(payload.header.Number.Uint64()),
+			ParentHash: payload.header.ParentHash,
+			Hits:       payload.hits,
+			Reason:     payload.reason,
+			Time:       hexutil.Uint64(payload.time),
+		})
+	}
+	return payloads
+}
+
+// clear forgets the specified bad block and all chain heads built on it,
+// returning whether it was tracked at all.
+func (s *invalidAncestors) clear(hash common.Hash) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.payloads.Contains(hash) {
+		return false
+	}
+	s.remove(hash)
+	return true
+}
+
+// track adds a bad block to the store, dropping the least recently used one
+// from disk too if the store is full. The caller must hold the lock.
+func (s *invalidAncestors) track(hash common.Hash, payload *invalidPayload) {
+	if s.payloads.Len() >= invalidTipsetsCap {
+		if oldest, _, ok := s.payloads.GetOldest(); ok {
+			s.remove(oldest)
+		}
+	}
+	s.payloads.Add(hash, payload)
+}
+
+// remove drops a bad block and all the tipsets referencing it, deleting it from
+// disk if persistence is enabled. The caller must hold the lock.
+func (s *invalidAncestors) remove(badHash common.Hash) {
+	s.payloads.Remove(badHash)
+	for _, head := range s.tipsets.Keys() {
+		if bad, _ := s.tipsets.Peek(head); bad == badHash {
+			s.tipsets.Remove(head)
+		}
+	}
+	if s.db != nil {
+		rawdb.DeleteInvalidPayload(s.db, badHash)
+	}
+}
+
+// persist writes a tracked bad block to disk, if persistence is enabled. The
+// caller must hold the lock.
+func (s *invalidAncestors) persist(payload *invalidPayload) {
+	if s.db == nil {
+		return
+	}
+	rawdb.WriteInvalidPayload(s.db, &rawdb.InvalidPayload{Header: payload.header, Reason: payload.reason, Time: payload.time})
+}
@@ -0,0 +1,108 @@
+package catalyst
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestInvalidAncestors(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	store := newInvalidAncestors(db)
+
+	bad := &types.Header{Number: big.NewInt(10), ParentHash: common.Hash{0x01}}
+	child := &types.Header{Number: big.NewInt(11), ParentHash: bad.Hash()}
+	store.add(bad, bad, "bad state root")
+
+	// The bad block and any chain built on it must be rejected
+	if store.check(bad.Hash(), bad.Hash()) == nil {
+		t.Fatalf("bad block not rejected")
+	}
+	if store.check(bad.Hash(), child.Hash()) == nil {
+		t.Fatalf("descendant of bad block not rejected")
+	}
+	if store.check(child.Hash(), child.Hash()) == nil {
+		t.Fatalf("tipset of bad block not tracked")
+	}
+	payloads := store.list()
+	if len(payloads) != 1 || payloads[0].Hash != bad.Hash() || payloads[0].Reason != "bad state root" || payloads[0].Hits != 4 {
+		t.Fatalf("unexpected invalid payloads: %+v", payloads)
+	}
+	// The bad blocks must survive a restart, but the tipsets are not persisted
+	store = newInvalidAncestors(db)
+	if store.check(bad.Hash(), bad.Hash()) == nil {
+		t.Fatalf("bad block not rejected after restart")
+	}
+	if store.check(child.Hash(), child.Hash()) != nil {
+		t.Fatalf("unexpected tipset after restart")
+	}
+	// Clearing the bad block must allow reprocessing it, also after a restart
+	if !store.clear(bad.Hash()) {
+		t.Fatalf("failed to clear bad block")
+	}
+	if store.clear(bad.Hash()) {
+		t.Fatalf("cleared bad block twice")
+	}
+	if len(newInvalidAncestors(db).list()) != 0 {
+		t.Fatalf("cleared bad block resurrected after restart")
+	}
+}
+
+func TestInvalidAncestorsPersisted(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	store := newInvalidAncestors(db)
+
+	// Persisted bad blocks must not be evicted by hits
+	bad := &types.Header{Number: big.NewInt(1)}
+	store.add(bad, bad, "")
+	for i := 0; i < 2*invalidBlockHitEviction; i++ {
+		if store.check(bad.Hash(), bad.Hash()) == nil {
+			t.Fatalf("persisted bad block evicted after %d hits", i+2)
+		}
+	}
+	// Every bad block is stored under its own key, capped along with the store
+	for i := 2; i < invalidTipsetsCap+2; i++ {
+		store.add(&types.Header{Number: big.NewInt(int64(i))}, &types.Header{Number: big.NewInt(int64(i))}, "")
+	}
+	stored := rawdb.ReadInvalidPayloads(db)
+	if len(stored) != invalidTipsetsCap {
+		t.Fatalf("persisted store not capped: have %d, want %d", len(stored), invalidTipsetsCap)
+	}
+	for _, payload := range stored {
+		if payload.Header.Hash() == bad.Hash() {
+			t.Fatalf("evicted bad block not deleted from disk")
+		}
+	}
+	if n := len(newInvalidAncestors(db).list()); n != invalidTipsetsCap {
+		t.Fatalf("reloaded store size mismatch: have %d, want %d", n, invalidTipsetsCap)
+	}
+}
+
+func TestInvalidAncestorsEviction(t *testing.T) {
+	store := newInvalidAncestors(nil)
+
+	// Bad blocks referenced too often are evicted to allow reprocessing them
+	bad := &types.Header{Number: big.NewInt(1)}
+	store.add(bad, bad, "")
+	for i := 1; i < invalidBlockHitEviction-1; i++ {
+		if store.check(bad.Hash(), bad.Hash()) == nil {
+			t.Fatalf("bad block evicted after %d hits", i+1)
+		}
+	}
+	if store.check(bad.Hash(), bad.Hash()) != nil {
+		t.Fatalf("bad block not evicted")
+	}
+	// The store is capped, evicting the least recently used bad blocks
+	for i := 0; i < invalidTipsetsCap+1; i++ {
+		store.add(&types.Header{Number: big.NewInt(int64(i))}, &types.Header{Number: big.NewInt(int64(i))}, "")
+	}
+	if n := len(store.list()); n != invalidTipsetsCap {
+		t.Fatalf("store not capped: have %d, want %d", n, invalidTipsetsCap)
+	}
+	if store.check((&types.Header{Number: big.NewInt(0)}).Hash(), common.Hash{}) != nil {
+		t.Fatalf("least recently used bad block not evicted")
+	}
+}
@@ -91,6 +91,23 @@ func (q *payloadQueue) get(id engine.PayloadID, full bool) *engine.ExecutionPayl
 	return nil
 }
 
+// stats retrieves the building progress of a previously stored payload without
+// resolving it, or nil if it does not exist.
+func (q *payloadQueue) stats(id engine.PayloadID) *miner.PayloadBuildStats {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	for _, item := range q.payloads {
+		if item == nil {
+			return nil // no more items
+		}
+		if item.id == id {
+			return item.payload.Stats()
+		}
+	}
+	return nil
+}
+
 // has checks if a particular payload is already tracked.
 func (q *payloadQueue) has(id engine.PayloadID) bool {
 	q.lock.RLock()
@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/eth/downloader"
@@ -53,6 +54,7 @@ var Defaults = Config{
 	TxLookupLimit:      2350000,
 	TransactionHistory: 2350000,
 	StateHistory:       params.FullImmutabilityThreshold,
+	TraceIndexHistory:  params.FullImmutabilityThreshold,
 	LightPeers:         100,
 	DatabaseCache:      512,
 	TrieCleanCache:     154,
@@ -63,6 +65,7 @@ var Defaults = Config{
 	Miner:              miner.DefaultConfig,
 	TxPool:             legacypool.DefaultConfig,
 	BlobPool:           blobpool.DefaultConfig,
+	TxPoolSnapshot:     txpool.DefaultSnapshotConfig,
 	RPCGasCap:          50000000,
 	RPCEVMTimeout:      5 * time.Second,
 	GPO:                FullNodeGPO,
@@ -135,8 +138,10 @@ type Config struct {
 	Miner miner.Config
 
 	// Transaction pool options
-	TxPool   legacypool.Config
-	BlobPool blobpool.Config
+	TxPool          legacypool.Config
+	BlobPool        blobpool.Config
+	TxPoolSnapshot  txpool.SnapshotConfig
+	TxPoolAdmission txpool.AdmissionConfig
 
 	// Gas Price Oracle options
 	GPO gasprice.Config
@@ -148,6 +153,10 @@ type Config struct {
 	VMTrace           string
 	VMTraceJsonConfig string
 
+	// Enables the persistent index of the call frames of the recent blocks
+	TraceIndex        bool   `toml:",omitempty"`
+	TraceIndexHistory uint64 `toml:",omitempty"` // The maximum number of blocks from head whose call frames are indexed.
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 
@@ -161,6 +170,10 @@ type Config struct {
 	// send-transaction variants. The unit is ether.
 	RPCTxFeeCap float64
 
+	// QngPersistInvalidPayloads enables persisting the payloads rejected by the
+	// QNG engine API, so that they stay rejected across restarts.
+	QngPersistInvalidPayloads bool `toml:",omitempty"`
+
 	// OverrideCancun (TODO: remove after the fork)
 	OverrideCancun *uint64 `toml:",omitempty"`
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/eth/downloader"
@@ -17,47 +18,52 @@ import (
 // MarshalTOML marshals as TOML.
 func (c Config) MarshalTOML() (interface{}, error) {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               uint64
-		SyncMode                downloader.SyncMode
-		EthDiscoveryURLs        []string
-		SnapDiscoveryURLs       []string
-		NoPruning               bool
-		NoPrefetch              bool
-		TxLookupLimit           uint64                 `toml:",omitempty"`
-		TransactionHistory      uint64                 `toml:",omitempty"`
-		StateHistory            uint64                 `toml:",omitempty"`
-		StateScheme             string                 `toml:",omitempty"`
-		RequiredBlocks          map[uint64]common.Hash `toml:"-"`
-		LightServ               int                    `toml:",omitempty"`
-		LightIngress            int                    `toml:",omitempty"`
-		LightEgress             int                    `toml:",omitempty"`
-		LightPeers              int                    `toml:",omitempty"`
-		LightNoPrune            bool                   `toml:",omitempty"`
-		LightNoSyncServe        bool                   `toml:",omitempty"`
-		SkipBcVersionCheck      bool                   `toml:"-"`
-		DatabaseHandles         int                    `toml:"-"`
-		DatabaseCache           int
-		DatabaseFreezer         string
-		TrieCleanCache          int
-		TrieDirtyCache          int
-		TrieTimeout             time.Duration
-		SnapshotCache           int
-		Preimages               bool
-		FilterLogCacheSize      int
-		Miner                   miner.Config
-		TxPool                  legacypool.Config
-		BlobPool                blobpool.Config
-		GPO                     gasprice.Config
-		EnablePreimageRecording bool
-		VMTrace                 string
-		VMTraceJsonConfig       string
-		DocRoot                 string `toml:"-"`
-		RPCGasCap               uint64
-		RPCEVMTimeout           time.Duration
-		RPCTxFeeCap             float64
-		OverrideCancun          *uint64 `toml:",omitempty"`
-		OverrideVerkle          *uint64 `toml:",omitempty"`
+		Genesis                   *core.Genesis `toml:",omitempty"`
+		NetworkId                 uint64
+		SyncMode                  downloader.SyncMode
+		EthDiscoveryURLs          []string
+		SnapDiscoveryURLs         []string
+		NoPruning                 bool
+		NoPrefetch                bool
+		TxLookupLimit             uint64                 `toml:",omitempty"`
+		TransactionHistory        uint64                 `toml:",omitempty"`
+		StateHistory              uint64                 `toml:",omitempty"`
+		StateScheme               string                 `toml:",omitempty"`
+		RequiredBlocks            map[uint64]common.Hash `toml:"-"`
+		LightServ                 int                    `toml:",omitempty"`
+		LightIngress              int                    `toml:",omitempty"`
+		LightEgress               int                    `toml:",omitempty"`
+		LightPeers                int                    `toml:",omitempty"`
+		LightNoPrune              bool                   `toml:",omitempty"`
+		LightNoSyncServe          bool                   `toml:",omitempty"`
+		SkipBcVersionCheck        bool                   `toml:"-"`
+		DatabaseHandles           int                    `toml:"-"`
+		DatabaseCache             int
+		DatabaseFreezer           string
+		TrieCleanCache            int
+		TrieDirtyCache            int
+		TrieTimeout               time.Duration
+		SnapshotCache             int
+		Preimages                 bool
+		FilterLogCacheSize        int
+		Miner                     miner.Config
+		TxPool                    legacypool.Config
+		BlobPool                  blobpool.Config
+		TxPoolSnapshot            txpool.SnapshotConfig
+		TxPoolAdmission           txpool.AdmissionConfig
+		GPO                       gasprice.Config
+		EnablePreimageRecording   bool
+		VMTrace                   string
+		VMTraceJsonConfig         string
+		TraceIndex                bool   `toml:",omitempty"`
+		TraceIndexHistory         uint64 `toml:",omitempty"`
+		DocRoot                   string `toml:"-"`
+		RPCGasCap                 uint64
+		RPCEVMTimeout             time.Duration
+		RPCTxFeeCap               float64
+		QngPersistInvalidPayloads bool    `toml:",omitempty"`
+		OverrideCancun            *uint64 `toml:",omitempty"`
+		OverrideVerkle            *uint64 `toml:",omitempty"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -91,14 +97,19 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Miner = c.Miner
 	enc.TxPool = c.TxPool
 	enc.BlobPool = c.BlobPool
+	enc.TxPoolSnapshot = c.TxPoolSnapshot
+	enc.TxPoolAdmission = c.TxPoolAdmission
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.VMTrace = c.VMTrace
 	enc.VMTraceJsonConfig = c.VMTraceJsonConfig
+	enc.TraceIndex = c.TraceIndex
+	enc.TraceIndexHistory = c.TraceIndexHistory
 	enc.DocRoot = c.DocRoot
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCEVMTimeout = c.RPCEVMTimeout
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
+	enc.QngPersistInvalidPayloads = c.QngPersistInvalidPayloads
 	enc.OverrideCancun = c.OverrideCancun
 	enc.OverrideVerkle = c.OverrideVerkle
 	return &enc, nil
@@ -107,47 +118,52 @@ func (c Config) MarshalTOML() (interface{}, error) {
 // UnmarshalTOML unmarshals from TOML.
 func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               *uint64
-		SyncMode                *downloader.SyncMode
-		EthDiscoveryURLs        []string
-		SnapDiscoveryURLs       []string
-		NoPruning               *bool
-		NoPrefetch              *bool
-		TxLookupLimit           *uint64                `toml:",omitempty"`
-		TransactionHistory      *uint64                `toml:",omitempty"`
-		StateHistory            *uint64                `toml:",omitempty"`
-		StateScheme             *string                `toml:",omitempty"`
-		RequiredBlocks          map[uint64]common.Hash `toml:"-"`
-		LightServ               *int                   `toml:",omitempty"`
-		LightIngress            *int                   `toml:",omitempty"`
-		LightEgress             *int                   `toml:",omitempty"`
-		LightPeers              *int                   `toml:",omitempty"`
-		LightNoPrune            *bool                  `toml:",omitempty"`
-		LightNoSyncServe        *bool                  `toml:",omitempty"`
-		SkipBcVersionCheck      *bool                  `toml:"-"`
-		DatabaseHandles         *int                   `toml:"-"`
-		DatabaseCache           *int
-		DatabaseFreezer         *string
-		TrieCleanCache          *int
-		TrieDirtyCache          *int
-		TrieTimeout             *time.Duration
-		SnapshotCache           *int
-		Preimages               *bool
-		FilterLogCacheSize      *int
-		Miner                   *miner.Config
-		TxPool                  *legacypool.Config
-		BlobPool                *blobpool.Config
-		GPO                     *gasprice.Config
-		EnablePreimageRecording *bool
-		VMTrace                 *string
-		VMTraceJsonConfig       *string
-		DocRoot                 *string `toml:"-"`
-		RPCGasCap               *uint64
-		RPCEVMTimeout           *time.Duration
-		RPCTxFeeCap             *float64
-		OverrideCancun          *uint64 `toml:",omitempty"`
-		OverrideVerkle          *uint64 `toml:",omitempty"`
+		Genesis                   *core.Genesis `toml:",omitempty"`
+		NetworkId                 *uint64
+		SyncMode                  *downloader.SyncMode
+		EthDiscoveryURLs          []string
+		SnapDiscoveryURLs         []string
+		NoPruning                 *bool
+		NoPrefetch                *bool
+		TxLookupLimit             *uint64                `toml:",omitempty"`
+		TransactionHistory        *uint64                `toml:",omitempty"`
+		StateHistory              *uint64                `toml:",omitempty"`
+		StateScheme               *string                `toml:",omitempty"`
+		RequiredBlocks            map[uint64]common.Hash `toml:"-"`
+		LightServ                 *int                   `toml:",omitempty"`
+		LightIngress              *int                   `toml:",omitempty"`
+		LightEgress               *int                   `toml:",omitempty"`
+		LightPeers                *int                   `toml:",omitempty"`
+		LightNoPrune              *bool                  `toml:",omitempty"`
+		LightNoSyncServe          *bool                  `toml:",omitempty"`
+		SkipBcVersionCheck        *bool                  `toml:"-"`
+		DatabaseHandles           *int                   `toml:"-"`
+		DatabaseCache             *int
+		DatabaseFreezer           *string
+		TrieCleanCache            *int
+		TrieDirtyCache            *int
+		TrieTimeout               *time.Duration
+		SnapshotCache             *int
+		Preimages                 *bool
+		FilterLogCacheSize        *int
+		Miner                     *miner.Config
+		TxPool                    *legacypool.Config
+		BlobPool                  *blobpool.Config
+		TxPoolSnapshot            *txpool.SnapshotConfig
+		TxPoolAdmission           *txpool.AdmissionConfig
+		GPO                       *gasprice.Config
+		EnablePreimageRecording   *bool
+		VMTrace                   *string
+		VMTraceJsonConfig         *string
+		TraceIndex                *bool   `toml:",omitempty"`
+		TraceIndexHistory         *uint64 `toml:",omitempty"`
+		DocRoot                   *string `toml:"-"`
+		RPCGasCap                 *uint64
+		RPCEVMTimeout             *time.Duration
+		RPCTxFeeCap               *float64
+		QngPersistInvalidPayloads *bool   `toml:",omitempty"`
+		OverrideCancun            *uint64 `toml:",omitempty"`
+		OverrideVerkle            *uint64 `toml:",omitempty"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -246,6 +262,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.BlobPool != nil {
 		c.BlobPool = *dec.BlobPool
 	}
+	if dec.TxPoolSnapshot != nil {
+		c.TxPoolSnapshot = *dec.TxPoolSnapshot
+	}
+	if dec.TxPoolAdmission != nil {
+		c.TxPoolAdmission = *dec.TxPoolAdmission
+	}
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
@@ -258,6 +280,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.VMTraceJsonConfig != nil {
 		c.VMTraceJsonConfig = *dec.VMTraceJsonConfig
 	}
+	if dec.TraceIndex != nil {
+		c.TraceIndex = *dec.TraceIndex
+	}
+	if dec.TraceIndexHistory != nil {
+		c.TraceIndexHistory = *dec.TraceIndexHistory
+	}
 	if dec.DocRoot != nil {
 		c.DocRoot = *dec.DocRoot
 	}
@@ -270,6 +298,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.RPCTxFeeCap != nil {
 		c.RPCTxFeeCap = *dec.RPCTxFeeCap
 	}
+	if dec.QngPersistInvalidPayloads != nil {
+		c.QngPersistInvalidPayloads = *dec.QngPersistInvalidPayloads
+	}
 	if dec.OverrideCancun != nil {
 		c.OverrideCancun = dec.OverrideCancun
 	}
@@ -0,0 +1,51 @@
+package filters
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// txPoolEventsBackend is implemented by the backends able to report the
+// lifecycle events of the pooled transactions.
+type txPoolEventsBackend interface {
+	SubscribeTxPoolEvents(ch chan<- []txpool.TxPoolEvent) event.Subscription
+}
+
+// TxpoolEvents creates a subscription that fires for every lifecycle event of
+// the pooled transactions: their addition, promotion, demotion, replacement,
+// drop and inclusion, along with the reason of drops and demotions.
+func (api *FilterAPI) TxpoolEvents(ctx context.Context) (*rpc.Subscription, error) {
+	backend, ok := api.sys.backend.(txPoolEventsBackend)
+	if !ok {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan []txpool.TxPoolEvent, 128)
+		eventsSub := backend.SubscribeTxPoolEvents(events)
+		defer eventsSub.Unsubscribe()
+
+		for {
+			select {
+			case batch := <-events:
+				for _, ev := range batch {
+					notifier.Notify(rpcSub.ID, ev)
+				}
+			case <-rpcSub.Err():
+				return
+			case <-eventsSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
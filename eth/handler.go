@@ -539,7 +539,7 @@ func (h *handler) txBroadcastLoop() {
 	for {
 		select {
 		case event := <-h.txsCh:
-			h.BroadcastTransactions(event.Txs)
+			h.BroadcastTransactions(h.publicTxs(event.Txs))
 		case <-h.txsSub.Err():
 			return
 		}
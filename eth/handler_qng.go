@@ -0,0 +1,48 @@
+package eth
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// privateTxPool is implemented by the pools holding private and conditional
+// transactions, which must not be propagated to the network: private ones to not
+// be front-run, conditional ones to not be included by other block producers
+// without checking their conditional.
+type privateTxPool interface {
+	IsPrivate(hash common.Hash) bool
+	Conditional(hash common.Hash) *txpool.TransactionConditional
+}
+
+// isWithheld returns whether the transaction must be withheld from the peers.
+func (h *handler) isWithheld(hash common.Hash) bool {
+	pool, ok := h.txpool.(privateTxPool)
+	return ok && (pool.IsPrivate(hash) || pool.Conditional(hash) != nil)
+}
+
+// publicTxs filters the withheld transactions out of the given batch.
+func (h *handler) publicTxs(txs types.Transactions) types.Transactions {
+	if _, ok := h.txpool.(privateTxPool); !ok {
+		return txs
+	}
+	public := make(types.Transactions, 0, len(txs))
+	for _, tx := range txs {
+		if !h.isWithheld(tx.Hash()) {
+			public = append(public, tx)
+		}
+	}
+	return public
+}
+
+// publicTxPool is the view of the transaction pool served to the peers, hiding
+// the withheld transactions.
+type publicTxPool handler
+
+// Get retrieves the transaction with the given hash, unless it's withheld.
+func (p *publicTxPool) Get(hash common.Hash) *types.Transaction {
+	if (*handler)(p).isWithheld(hash) {
+		return nil
+	}
+	return p.txpool.Get(hash)
+}
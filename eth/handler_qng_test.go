@@ -0,0 +1,51 @@
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testWithholdingTxPool is a mock transaction pool tracking private and
+// conditional transactions.
+type testWithholdingTxPool struct {
+	*testTxPool
+	private      map[common.Hash]bool
+	conditionals map[common.Hash]*txpool.TransactionConditional
+}
+
+func (p *testWithholdingTxPool) IsPrivate(hash common.Hash) bool { return p.private[hash] }
+
+func (p *testWithholdingTxPool) Conditional(hash common.Hash) *txpool.TransactionConditional {
+	return p.conditionals[hash]
+}
+
+// Tests that private and conditional transactions are neither broadcast nor
+// served to the peers.
+func TestWithheldTransactions(t *testing.T) {
+	var (
+		public      = types.NewTransaction(0, common.Address{}, common.Big0, 21000, common.Big1, nil)
+		private     = types.NewTransaction(1, common.Address{}, common.Big0, 21000, common.Big1, nil)
+		conditional = types.NewTransaction(2, common.Address{}, common.Big0, 21000, common.Big1, nil)
+		pool        = &testWithholdingTxPool{
+			testTxPool:   newTestTxPool(),
+			private:      map[common.Hash]bool{private.Hash(): true},
+			conditionals: map[common.Hash]*txpool.TransactionConditional{conditional.Hash(): {}},
+		}
+		h = &handler{txpool: pool}
+	)
+	pool.Add([]*types.Transaction{public, private, conditional}, false, false)
+
+	if txs := h.publicTxs(types.Transactions{public, private, conditional}); len(txs) != 1 || txs[0] != public {
+		t.Fatalf("broadcast transactions mismatch: have %d, want only %x", len(txs), public.Hash())
+	}
+	served := (*publicTxPool)(h)
+	if served.Get(public.Hash()) == nil {
+		t.Fatalf("public transaction not served")
+	}
+	if served.Get(private.Hash()) != nil || served.Get(conditional.Hash()) != nil {
+		t.Fatalf("withheld transaction served")
+	}
+}
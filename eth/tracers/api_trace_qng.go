@@ -0,0 +1,416 @@
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// parityTracer is the native tracer producing OpenEthereum flat traces.
+	parityTracer = "flatCallTracer"
+
+	// maxTraceFilterBlocks is the maximum number of blocks trace_filter
+	// re-executes in a single request.
+	maxTraceFilterBlocks = 1000
+
+	// Trace modes of the replay methods.
+	traceModeTrace     = "trace"
+	traceModeStateDiff = "stateDiff"
+	traceModeVMTrace   = "vmTrace"
+)
+
+// parityTracerConfig makes the flat call tracer report errors the same way
+// as OpenEthereum.
+var parityTracerConfig = json.RawMessage(`{"convertParityErrors":true}`)
+
+// traceIndexBackend is implemented by backends maintaining a persistent index
+// of the call frames of recent blocks, allowing trace_filter to only trace the
+// blocks involving the filtered addresses.
+type traceIndexBackend interface {
+	TraceIndexedBlocks(addrs []common.Address, from, to uint64) ([]uint64, error)
+}
+
+// TraceAPI is the collection of OpenEthereum compatible tracing APIs exposed
+// over the trace namespace.
+type TraceAPI struct {
+	api *API
+}
+
+// NewTraceAPI creates a new API definition for the trace_ methods.
+func NewTraceAPI(backend Backend) *TraceAPI {
+	return &TraceAPI{api: NewAPI(backend)}
+}
+
+// TraceFilterArgs are the arguments of trace_filter.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *uint64          `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+// parityTraceConfig returns the trace config running the flat call tracer.
+func parityTraceConfig() *TraceConfig {
+	tracer := parityTracer
+	return &TraceConfig{Tracer: &tracer, TracerConfig: parityTracerConfig}
+}
+
+// decodeParityTraces converts the flat call tracer results into traces.
+func decodeParityTraces(result interface{}) ([]*ParityTrace, error) {
+	raw, ok := result.(json.RawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected trace result %T", result)
+	}
+	var traces []*ParityTrace
+	if err := json.Unmarshal(raw, &traces); err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+// Block returns the flat traces of all transactions within a block.
+func (api *TraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]*ParityTrace, error) {
+	block, err := api.api.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return api.block(ctx, block)
+}
+
+func (api *TraceAPI) block(ctx context.Context, block *types.Block) ([]*ParityTrace, error) {
+	if block.NumberU64() == 0 {
+		return []*ParityTrace{}, nil
+	}
+	results, err := api.api.traceBlock(ctx, block, parityTraceConfig())
+	if err != nil {
+		return nil, err
+	}
+	traces := make([]*ParityTrace, 0, len(results))
+	for _, result := range results {
+		txTraces, err := decodeParityTraces(result.Result)
+		if err != nil {
+			return nil, err
+		}
+		traces = append(traces, txTraces...)
+	}
+	return traces, nil
+}
+
+// Transaction returns the flat traces of a transaction.
+func (api *TraceAPI) Transaction(ctx context.Context, hash common.Hash) ([]*ParityTrace, error) {
+	result, err := api.api.TraceTransaction(ctx, hash, parityTraceConfig())
+	if err != nil {
+		return nil, err
+	}
+	return decodeParityTraces(result)
+}
+
+// Get returns the flat trace of a transaction at the given trace address. An
+// empty address selects the top-level call.
+func (api *TraceAPI) Get(ctx context.Context, hash common.Hash, indices []hexutil.Uint64) (*ParityTrace, error) {
+	traces, err := api.Transaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	address := make([]int, len(indices))
+	for i, index := range indices {
+		address[i] = int(index)
+	}
+	for _, trace := range traces {
+		if slices.Equal(trace.TraceAddress, address) {
+			return trace, nil
+		}
+	}
+	return nil, nil
+}
+
+// ReplayTransaction re-executes a transaction and returns the requested trace
+// modes: "trace", "stateDiff" and/or "vmTrace".
+func (api *TraceAPI) ReplayTransaction(ctx context.Context, hash common.Hash, modes []string) (*TraceResults, error) {
+	if err := validateTraceModes(modes); err != nil {
+		return nil, err
+	}
+	found, _, blockHash, blockNumber, index, err := api.api.backend.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, ethapi.NewTxIndexingError()
+	}
+	if !found {
+		return nil, errTxNotFound
+	}
+	if blockNumber == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	block, err := api.api.blockByNumberAndHash(ctx, rpc.BlockNumber(blockNumber), blockHash)
+	if err != nil {
+		return nil, err
+	}
+	tx, vmctx, statedb, release, err := api.api.backend.StateAtTransaction(ctx, block, int(index), defaultTraceReexec)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	msg, err := core.TransactionToMessage(tx, types.MakeSigner(api.api.backend.ChainConfig(), block.Number(), block.Time()), block.BaseFee())
+	if err != nil {
+		return nil, err
+	}
+	txctx := &Context{
+		BlockHash:   blockHash,
+		BlockNumber: block.Number(),
+		TxIndex:     int(index),
+		TxHash:      hash,
+	}
+	return api.replayTx(ctx, tx, msg, txctx, vmctx, statedb, modes)
+}
+
+// ReplayBlockTransactions re-executes all transactions within a block and
+// returns the requested trace modes for each of them.
+func (api *TraceAPI) ReplayBlockTransactions(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, modes []string) ([]*TraceResults, error) {
+	if err := validateTraceModes(modes); err != nil {
+		return nil, err
+	}
+	var (
+		block *types.Block
+		err   error
+	)
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		block, err = api.api.blockByHash(ctx, hash)
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		block, err = api.api.blockByNumber(ctx, number)
+	} else {
+		return nil, errors.New("invalid arguments; neither block nor hash specified")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	parent, err := api.api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
+	if err != nil {
+		return nil, err
+	}
+	statedb, release, err := api.api.backend.StateAtBlock(ctx, parent, defaultTraceReexec, nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var (
+		txs      = block.Transactions()
+		blockCtx = core.NewEVMBlockContext(block.Header(), api.api.chainContext(ctx), nil)
+		signer   = types.MakeSigner(api.api.backend.ChainConfig(), block.Number(), block.Time())
+		results  = make([]*TraceResults, len(txs))
+	)
+	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
+		vmenv := vm.NewEVM(blockCtx, vm.TxContext{}, statedb, api.api.backend.ChainConfig(), vm.Config{})
+		core.ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb)
+	}
+	for i, tx := range txs {
+		msg, _ := core.TransactionToMessage(tx, signer, block.BaseFee())
+		txctx := &Context{
+			BlockHash:   block.Hash(),
+			BlockNumber: block.Number(),
+			TxIndex:     i,
+			TxHash:      tx.Hash(),
+		}
+		res, err := api.replayTx(ctx, tx, msg, txctx, blockCtx, statedb, modes)
+		if err != nil {
+			return nil, err
+		}
+		hash := tx.Hash()
+		res.TransactionHash = &hash
+		results[i] = res
+	}
+	return results, nil
+}
+
+// Filter returns the flat traces within a block range, matching the given
+// sender and recipient addresses. If both address sets are given, a trace
+// has to match both of them.
+func (api *TraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]*ParityTrace, error) {
+	var (
+		from = rpc.EarliestBlockNumber
+		to   = rpc.LatestBlockNumber
+	)
+	if args.FromBlock != nil {
+		from = *args.FromBlock
+	}
+	if args.ToBlock != nil {
+		to = *args.ToBlock
+	}
+	start, err := api.api.blockByNumber(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := api.api.blockByNumber(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	if start.NumberU64() > end.NumberU64() {
+		return nil, fmt.Errorf("invalid block range: %d > %d", start.NumberU64(), end.NumberU64())
+	}
+	numbers, indexed := api.indexedBlocks(args, start.NumberU64(), end.NumberU64())
+	if !indexed {
+		if blocks := end.NumberU64() - start.NumberU64() + 1; blocks > maxTraceFilterBlocks {
+			return nil, fmt.Errorf("block range too large: have %d, max %d", blocks, maxTraceFilterBlocks)
+		}
+		for number := start.NumberU64(); number <= end.NumberU64(); number++ {
+			numbers = append(numbers, number)
+		}
+	} else if len(numbers) > maxTraceFilterBlocks {
+		return nil, fmt.Errorf("too many matching blocks: have %d, max %d", len(numbers), maxTraceFilterBlocks)
+	}
+	var (
+		traces = []*ParityTrace{}
+		skip   uint64
+	)
+	if args.After != nil {
+		skip = *args.After
+	}
+	for _, number := range numbers {
+		block := end
+		if number != end.NumberU64() {
+			if block, err = api.api.blockByNumber(ctx, rpc.BlockNumber(number)); err != nil {
+				return nil, err
+			}
+		}
+		blockTraces, err := api.block(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		for _, trace := range blockTraces {
+			if !matchTraceAddress(trace.sender(), args.FromAddress) || !matchTraceAddress(trace.recipient(), args.ToAddress) {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			traces = append(traces, trace)
+			if args.Count != nil && uint64(len(traces)) >= *args.Count {
+				return traces, nil
+			}
+		}
+	}
+	return traces, nil
+}
+
+// indexedBlocks looks up the blocks within [from, to] involving the filtered
+// addresses in the trace index, if the backend maintains one covering the range.
+func (api *TraceAPI) indexedBlocks(args TraceFilterArgs, from, to uint64) ([]uint64, bool) {
+	backend, ok := api.api.backend.(traceIndexBackend)
+	if !ok {
+		return nil, false
+	}
+	// Any matching trace involves one of the addresses of a non-empty set
+	addrs := args.FromAddress
+	if len(addrs) == 0 {
+		addrs = args.ToAddress
+	}
+	if len(addrs) == 0 {
+		return nil, false
+	}
+	numbers, err := backend.TraceIndexedBlocks(addrs, from, to)
+	if err != nil {
+		return nil, false
+	}
+	return numbers, true
+}
+
+// matchTraceAddress reports whether the address is within the filter set. An
+// empty set matches everything.
+func matchTraceAddress(addr *common.Address, set []common.Address) bool {
+	if len(set) == 0 {
+		return true
+	}
+	return addr != nil && slices.Contains(set, *addr)
+}
+
+func validateTraceModes(modes []string) error {
+	for _, mode := range modes {
+		switch mode {
+		case traceModeTrace, traceModeStateDiff, traceModeVMTrace:
+		default:
+			return fmt.Errorf("invalid trace mode %q", mode)
+		}
+	}
+	return nil
+}
+
+// replayTx executes a transaction on top of the given state, collecting the
+// requested trace modes.
+func (api *TraceAPI) replayTx(ctx context.Context, tx *types.Transaction, msg *core.Message, txctx *Context, vmctx vm.BlockContext, statedb *state.StateDB, modes []string) (*TraceResults, error) {
+	var (
+		results = &TraceResults{Trace: []*ParityTrace{}}
+		hooks   = []*tracing.Hooks{{
+			OnExit: func(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+				if depth == 0 {
+					results.Output = common.CopyBytes(output)
+				}
+			},
+		}}
+		flat   *Tracer
+		vmt    *vmTraceRecorder
+		diff   *stateDiffRecorder
+		pre    *state.StateDB
+		err    error
+		tracer = &Tracer{Stop: func(error) {}}
+	)
+	if slices.Contains(modes, traceModeTrace) {
+		if flat, err = DefaultDirectory.New(parityTracer, txctx, parityTracerConfig); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, flat.Hooks)
+		tracer.Stop = flat.Stop
+	}
+	if slices.Contains(modes, traceModeVMTrace) {
+		vmt = new(vmTraceRecorder)
+		hooks = append(hooks, vmt.hooks())
+	}
+	if slices.Contains(modes, traceModeStateDiff) {
+		diff, pre = newStateDiffRecorder(), statedb.Copy()
+		hooks = append(hooks, diff.hooks())
+	}
+	tracer.Hooks = tracing.Join(hooks...)
+
+	if err := api.api.applyTracedTx(ctx, tracer, defaultTraceTimeout, tx, msg, txctx, vmctx, statedb); err != nil {
+		return nil, err
+	}
+	if flat != nil {
+		result, err := flat.GetResult()
+		if err != nil {
+			return nil, err
+		}
+		if results.Trace, err = decodeParityTraces(result); err != nil {
+			return nil, err
+		}
+		// Replayed traces are not positioned within the chain
+		for _, trace := range results.Trace {
+			trace.BlockHash, trace.BlockNumber = nil, nil
+			trace.TransactionHash, trace.TransactionPosition = nil, nil
+		}
+	}
+	if vmt != nil {
+		results.VMTrace = vmt.root
+	}
+	if diff != nil {
+		results.StateDiff = diff.diff(pre, statedb)
+	}
+	return results, nil
+}
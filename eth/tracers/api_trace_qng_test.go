@@ -0,0 +1,156 @@
+package tracers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/holiman/uint256"
+)
+
+func TestTraceAPI(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, _    = crypto.GenerateKey()
+		sender    = crypto.PubkeyToAddress(key.PublicKey)
+		recipient = common.HexToAddress("0xbeef")
+		caller    = common.HexToAddress("0xc0de")
+		callee    = common.HexToAddress("0xca11ee")
+		signer    = types.HomesteadSigner{}
+
+		// Stores 42 in memory
+		calleeCode = []byte{byte(vm.PUSH1), 42, byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.STOP)}
+
+		// Calls the callee, then sets slot 0 to 1
+		callerCode = append(append([]byte{
+			byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+			byte(vm.PUSH20)}, callee.Bytes()...),
+			byte(vm.GAS), byte(vm.CALL), byte(vm.POP),
+			byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		)
+		genesis = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				sender: {Balance: big.NewInt(params.Ether)},
+				caller: {Balance: common.Big0, Code: callerCode},
+				callee: {Balance: common.Big0, Code: calleeCode},
+			},
+		}
+		txHashes []common.Hash
+	)
+	backend, teardown := tracers.NewTestBackend(t, 2, genesis, func(i int, b *core.BlockGen) {
+		to, value := caller, common.Big0
+		if i == 1 {
+			to, value = recipient, big.NewInt(1000)
+		}
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), to, value, 100000, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+		txHashes = append(txHashes, tx.Hash())
+	})
+	defer teardown()
+	api := tracers.NewTraceAPI(backend)
+
+	// Check the flat traces of blocks and transactions
+	traces, err := api.Block(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if len(traces) != 2 {
+		t.Fatalf("block trace count mismatch: have %d, want 2", len(traces))
+	}
+	if top := traces[0]; top.Subtraces != 1 || *top.Action.To != caller || *top.BlockNumber != 1 || *top.TransactionHash != txHashes[0] {
+		t.Errorf("top-level trace mismatch: %+v", top)
+	}
+	if child := traces[1]; len(child.TraceAddress) != 1 || *child.Action.From != caller || *child.Action.To != callee {
+		t.Errorf("internal trace mismatch: %+v", child)
+	}
+	traces, err = api.Transaction(context.Background(), txHashes[0])
+	if err != nil || len(traces) != 2 {
+		t.Fatalf("transaction trace mismatch: %d traces, err %v", len(traces), err)
+	}
+	trace, err := api.Get(context.Background(), txHashes[0], []hexutil.Uint64{0})
+	if err != nil || trace == nil || *trace.Action.To != callee {
+		t.Fatalf("trace get mismatch: %+v, err %v", trace, err)
+	}
+	// Replay the transaction in all modes
+	res, err := api.ReplayTransaction(context.Background(), txHashes[0], []string{"trace", "stateDiff", "vmTrace"})
+	if err != nil {
+		t.Fatalf("failed to replay transaction: %v", err)
+	}
+	if len(res.Trace) != 2 || res.Trace[0].BlockHash != nil || res.Trace[0].TransactionHash != nil {
+		t.Errorf("replayed trace mismatch: %+v", res.Trace)
+	}
+	diff := res.StateDiff[caller]
+	if diff == nil || diff.Storage[common.Hash{}] == nil {
+		t.Fatalf("caller storage diff missing: %+v", diff)
+	}
+	blob, _ := json.Marshal(diff)
+	if want := `"storage":{"0x0000000000000000000000000000000000000000000000000000000000000000":{"*":{"from":"0x0000000000000000000000000000000000000000000000000000000000000000","to":"0x0000000000000000000000000000000000000000000000000000000000000001"}}}`; !bytes.Contains(blob, []byte(want)) {
+		t.Errorf("caller diff encoding mismatch: %s", blob)
+	}
+	if !bytes.Contains(blob, []byte(`"balance":"="`)) {
+		t.Errorf("unchanged balance not marked: %s", blob)
+	}
+	blob, _ = json.Marshal(res.StateDiff[sender].Nonce)
+	if string(blob) != `{"*":{"from":"0x0","to":"0x1"}}` {
+		t.Errorf("sender nonce diff mismatch: %s", blob)
+	}
+	vmTrace := res.VMTrace
+	if vmTrace == nil || !bytes.Equal(vmTrace.Code, callerCode) || len(vmTrace.Ops) != 13 {
+		t.Fatalf("vm trace mismatch: %+v", vmTrace)
+	}
+	if push := vmTrace.Ops[0].Ex.Push; len(push) != 1 || !(*uint256.Int)(push[0]).IsZero() {
+		t.Errorf("push mismatch: %v", push)
+	}
+	call := vmTrace.Ops[7]
+	if call.Sub == nil || !bytes.Equal(call.Sub.Code, calleeCode) || len(call.Sub.Ops) != 4 {
+		t.Fatalf("vm sub trace mismatch: %+v", call.Sub)
+	}
+	if mem := call.Sub.Ops[2].Ex.Mem; mem == nil || mem.Off != 0 || len(mem.Data) != 32 || mem.Data[31] != 42 {
+		t.Errorf("memory write mismatch: %+v", mem)
+	}
+	if store := vmTrace.Ops[11].Ex.Store; store == nil || (*uint256.Int)(store.Val).Uint64() != 1 {
+		t.Errorf("storage write mismatch: %+v", store)
+	}
+	// Replay a block with a single mode
+	results, err := api.ReplayBlockTransactions(context.Background(), rpc.BlockNumberOrHashWithNumber(1), []string{"trace"})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("block replay mismatch: %d results, err %v", len(results), err)
+	}
+	if results[0].TransactionHash == nil || *results[0].TransactionHash != txHashes[0] || results[0].StateDiff != nil || results[0].VMTrace != nil {
+		t.Errorf("block replay result mismatch: %+v", results[0])
+	}
+	if _, err := api.ReplayTransaction(context.Background(), txHashes[0], []string{"unknown"}); err == nil {
+		t.Error("invalid trace mode accepted")
+	}
+	// Filter traces by address
+	var (
+		from, to = rpc.BlockNumber(1), rpc.BlockNumber(2)
+		one      = uint64(1)
+	)
+	traces, err = api.Filter(context.Background(), tracers.TraceFilterArgs{FromBlock: &from, ToBlock: &to, ToAddress: []common.Address{callee}})
+	if err != nil || len(traces) != 1 || *traces[0].Action.From != caller {
+		t.Fatalf("filter by recipient mismatch: %+v, err %v", traces, err)
+	}
+	traces, err = api.Filter(context.Background(), tracers.TraceFilterArgs{FromBlock: &from, ToBlock: &to, FromAddress: []common.Address{sender}})
+	if err != nil || len(traces) != 2 {
+		t.Fatalf("filter by sender mismatch: %+v, err %v", traces, err)
+	}
+	traces, err = api.Filter(context.Background(), tracers.TraceFilterArgs{FromBlock: &from, ToBlock: &to, FromAddress: []common.Address{sender}, After: &one, Count: &one})
+	if err != nil || len(traces) != 1 || *traces[0].Action.To != recipient {
+		t.Fatalf("filter pagination mismatch: %+v, err %v", traces, err)
+	}
+}
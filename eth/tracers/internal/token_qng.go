@@ -0,0 +1,299 @@
+package internal
+
+import (
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// Token standards of the decoded transfers.
+const (
+	TokenERC20   = "erc20"
+	TokenERC721  = "erc721"
+	TokenERC1155 = "erc1155"
+)
+
+var (
+	// transferTopic is the topic of the ERC-20 and ERC-721 Transfer events.
+	transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+	// transferSingleTopic is the topic of the ERC-1155 TransferSingle event.
+	transferSingleTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+
+	// transferBatchTopic is the topic of the ERC-1155 TransferBatch event.
+	transferBatchTopic = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+)
+
+// TokenConfig configures the decoding of token movements.
+type TokenConfig struct {
+	// BalanceSlots maps token contracts to the storage slot of their balances
+	// mapping (e.g. 0 for OpenZeppelin ERC-20 tokens). Balance changes are only
+	// decoded for the listed tokens.
+	BalanceSlots map[common.Address]uint64 `json:"balanceSlots"`
+}
+
+// TokenTransfer is a token movement decoded from a standard transfer event.
+// ERC-1155 batch transfers are split into one movement per token id.
+type TokenTransfer struct {
+	Token    common.Address  `json:"token"`
+	Standard string          `json:"standard"`
+	Operator *common.Address `json:"operator,omitempty"`
+	From     common.Address  `json:"from"`
+	To       common.Address  `json:"to"`
+	ID       *hexutil.Big    `json:"id,omitempty"`
+	Amount   *hexutil.Big    `json:"amount"`
+}
+
+// TokenBalanceChange is the net change of a holder balance within a token
+// contract storage, over a whole transaction.
+type TokenBalanceChange struct {
+	Token  common.Address `json:"token"`
+	Holder common.Address `json:"holder"`
+	From   *hexutil.Big   `json:"from"`
+	To     *hexutil.Big   `json:"to"`
+}
+
+// TokenMovements are the token movements of a transaction.
+type TokenMovements struct {
+	Transfers      []*TokenTransfer      `json:"transfers"`
+	BalanceChanges []*TokenBalanceChange `json:"balanceChanges,omitempty"`
+}
+
+// Empty reports whether no token moved.
+func (m *TokenMovements) Empty() bool {
+	return len(m.Transfers) == 0 && len(m.BalanceChanges) == 0
+}
+
+// tokenSlotKey identifies a balance slot of a token contract.
+type tokenSlotKey struct {
+	token common.Address
+	slot  common.Hash
+}
+
+// tokenHolderKey identifies a holder balance of a token contract.
+type tokenHolderKey struct {
+	token  common.Address
+	holder common.Address
+}
+
+// tokenFrame marks the movements recorded before a call frame was entered.
+type tokenFrame struct {
+	transfers int
+	changes   int
+}
+
+// TokenCollector gathers the token movements of a transaction from the emitted
+// logs and, for the configured tokens, from the writes to their balance slots.
+// Movements within reverted call frames are dropped.
+type TokenCollector struct {
+	config TokenConfig
+
+	transfers []*TokenTransfer
+	changes   []*TokenBalanceChange // Balance slot writes, in execution order
+	frames    []tokenFrame
+
+	holders map[tokenSlotKey]common.Address // Balance slots hashed during execution
+}
+
+// NewTokenCollector creates a collector of token movements.
+func NewTokenCollector(config TokenConfig) *TokenCollector {
+	return &TokenCollector{
+		config:  config,
+		holders: make(map[tokenSlotKey]common.Address),
+	}
+}
+
+// Hooks returns the tracing hooks feeding the collector.
+func (c *TokenCollector) Hooks() *tracing.Hooks {
+	hooks := &tracing.Hooks{
+		OnEnter: c.OnEnter,
+		OnExit:  c.OnExit,
+		OnLog:   c.OnLog,
+	}
+	if len(c.config.BalanceSlots) > 0 {
+		hooks.OnOpcode = c.OnOpcode
+		hooks.OnStorageChange = c.OnStorageChange
+	}
+	return hooks
+}
+
+// Reset clears the movements to start collecting a new transaction.
+func (c *TokenCollector) Reset() {
+	c.transfers, c.changes, c.frames = nil, nil, c.frames[:0]
+	clear(c.holders)
+}
+
+func (c *TokenCollector) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	c.frames = append(c.frames, tokenFrame{transfers: len(c.transfers), changes: len(c.changes)})
+}
+
+func (c *TokenCollector) OnExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if len(c.frames) == 0 {
+		return
+	}
+	frame := c.frames[len(c.frames)-1]
+	c.frames = c.frames[:len(c.frames)-1]
+	if reverted {
+		c.transfers, c.changes = c.transfers[:frame.transfers], c.changes[:frame.changes]
+	}
+}
+
+// OnOpcode records the holders of the balance slots hashed by the configured
+// tokens, as mapping slots are keccak256(pad(holder) ++ pad(mapping slot)).
+func (c *TokenCollector) OnOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	if vm.OpCode(op) != vm.KECCAK256 || err != nil {
+		return
+	}
+	slot, ok := c.config.BalanceSlots[scope.Address()]
+	if !ok {
+		return
+	}
+	stack := scope.StackData()
+	if len(stack) < 2 {
+		return
+	}
+	offset, size := StackBack(stack, 0), StackBack(stack, 1)
+	if !size.IsUint64() || size.Uint64() != 64 || !offset.IsUint64() {
+		return
+	}
+	data, err := GetMemoryCopyPadded(scope.MemoryData(), int64(offset.Uint64()), 64)
+	if err != nil {
+		return
+	}
+	if new(uint256.Int).SetBytes(data[32:]).CmpUint64(slot) != 0 || !new(uint256.Int).SetBytes(data[:12]).IsZero() {
+		return
+	}
+	key := tokenSlotKey{token: scope.Address(), slot: crypto.Keccak256Hash(data)}
+	c.holders[key] = common.BytesToAddress(data[12:32])
+}
+
+func (c *TokenCollector) OnStorageChange(addr common.Address, slot common.Hash, prev, new common.Hash) {
+	holder, ok := c.holders[tokenSlotKey{token: addr, slot: slot}]
+	if !ok {
+		return
+	}
+	c.changes = append(c.changes, &TokenBalanceChange{
+		Token:  addr,
+		Holder: holder,
+		From:   (*hexutil.Big)(prev.Big()),
+		To:     (*hexutil.Big)(new.Big()),
+	})
+}
+
+func (c *TokenCollector) OnLog(log *types.Log) {
+	c.transfers = append(c.transfers, DecodeTokenTransfers(log)...)
+}
+
+// Movements returns the token movements collected since the last reset, with
+// the balance changes merged per token and holder.
+func (c *TokenCollector) Movements() *TokenMovements {
+	movements := &TokenMovements{Transfers: c.transfers}
+	if movements.Transfers == nil {
+		movements.Transfers = []*TokenTransfer{}
+	}
+	merged := make(map[tokenHolderKey]*TokenBalanceChange)
+	for _, change := range c.changes {
+		key := tokenHolderKey{token: change.Token, holder: change.Holder}
+		if prev, ok := merged[key]; ok {
+			prev.To = change.To
+			continue
+		}
+		merged[key] = &TokenBalanceChange{Token: change.Token, Holder: change.Holder, From: change.From, To: change.To}
+		movements.BalanceChanges = append(movements.BalanceChanges, merged[key])
+	}
+	// Drop the balances written back to their original value
+	movements.BalanceChanges = slices.DeleteFunc(movements.BalanceChanges, func(change *TokenBalanceChange) bool {
+		return change.From.ToInt().Cmp(change.To.ToInt()) == 0
+	})
+	return movements
+}
+
+// DecodeTokenTransfers decodes the token movements of a standard ERC-20,
+// ERC-721 or ERC-1155 transfer event. Nil is returned for any other log.
+func DecodeTokenTransfers(log *types.Log) []*TokenTransfer {
+	if len(log.Topics) == 0 {
+		return nil
+	}
+	switch log.Topics[0] {
+	case transferTopic:
+		// ERC-20 and ERC-721 share the event, the latter indexing the token id
+		transfer := &TokenTransfer{Token: log.Address}
+		switch {
+		case len(log.Topics) == 3 && len(log.Data) == 32:
+			transfer.Standard = TokenERC20
+			transfer.Amount = (*hexutil.Big)(new(big.Int).SetBytes(log.Data))
+		case len(log.Topics) == 4 && len(log.Data) == 0:
+			transfer.Standard = TokenERC721
+			transfer.ID = (*hexutil.Big)(log.Topics[3].Big())
+			transfer.Amount = (*hexutil.Big)(big.NewInt(1))
+		default:
+			return nil
+		}
+		transfer.From = common.BytesToAddress(log.Topics[1].Bytes())
+		transfer.To = common.BytesToAddress(log.Topics[2].Bytes())
+		return []*TokenTransfer{transfer}
+	case transferSingleTopic:
+		if len(log.Topics) != 4 || len(log.Data) != 64 {
+			return nil
+		}
+		return []*TokenTransfer{newERC1155Transfer(log, new(big.Int).SetBytes(log.Data[:32]), new(big.Int).SetBytes(log.Data[32:]))}
+	case transferBatchTopic:
+		if len(log.Topics) != 4 || len(log.Data) < 64 {
+			return nil
+		}
+		ids, ok := decodeUint256Array(log.Data, 0)
+		if !ok {
+			return nil
+		}
+		amounts, ok := decodeUint256Array(log.Data, 32)
+		if !ok || len(ids) != len(amounts) {
+			return nil
+		}
+		transfers := make([]*TokenTransfer, len(ids))
+		for i := range ids {
+			transfers[i] = newERC1155Transfer(log, ids[i], amounts[i])
+		}
+		return transfers
+	}
+	return nil
+}
+
+func newERC1155Transfer(log *types.Log, id, amount *big.Int) *TokenTransfer {
+	operator := common.BytesToAddress(log.Topics[1].Bytes())
+	return &TokenTransfer{
+		Token:    log.Address,
+		Standard: TokenERC1155,
+		Operator: &operator,
+		From:     common.BytesToAddress(log.Topics[2].Bytes()),
+		To:       common.BytesToAddress(log.Topics[3].Bytes()),
+		ID:       (*hexutil.Big)(id),
+		Amount:   (*hexutil.Big)(amount),
+	}
+}
+
+// decodeUint256Array decodes an ABI encoded dynamic uint256 array whose offset
+// is stored at the given position of the data.
+func decodeUint256Array(data []byte, pos int) ([]*big.Int, bool) {
+	offset := new(big.Int).SetBytes(data[pos : pos+32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data))-32 {
+		return nil, false
+	}
+	start := offset.Uint64()
+	length := new(big.Int).SetBytes(data[start : start+32])
+	if !length.IsUint64() || length.Uint64() > (uint64(len(data))-start-32)/32 {
+		return nil, false
+	}
+	items := make([]*big.Int, length.Uint64())
+	for i := range items {
+		at := start + 32 + uint64(i)*32
+		items[i] = new(big.Int).SetBytes(data[at : at+32])
+	}
+	return items, true
+}
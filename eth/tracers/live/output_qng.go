@@ -0,0 +1,75 @@
+package live
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// defaultOutputMaxSize is the size in megabytes after which an output file is
+// rotated.
+const defaultOutputMaxSize = 100
+
+// outputConfig is the location of the files written by a live tracer.
+type outputConfig struct {
+	Path    string `json:"path"`    // Directory to write the output files to
+	MaxSize int    `json:"maxSize"` // Maximum size in megabytes of a file before rotation
+}
+
+// jsonlOutput writes the records of a live tracer as JSON lines into a file
+// within the configured directory. The file is rotated by lumberjack into
+// <name>-<timestamp>.jsonl once it reaches the maximum size.
+type jsonlOutput struct {
+	tracer string
+	logger *lumberjack.Logger
+}
+
+// newJSONLOutput creates the output directory of the named tracer and opens
+// the given file within it.
+func newJSONLOutput(tracer string, config outputConfig, file string) (*jsonlOutput, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("%s tracer output path is required", tracer)
+	}
+	if config.MaxSize == 0 {
+		config.MaxSize = defaultOutputMaxSize
+	}
+	if config.MaxSize < 0 {
+		return nil, errors.New("output max size must be positive")
+	}
+	if err := os.MkdirAll(config.Path, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s tracer directory: %v", tracer, err)
+	}
+	return &jsonlOutput{
+		tracer: tracer,
+		logger: &lumberjack.Logger{
+			Filename: filepath.Join(config.Path, file),
+			MaxSize:  config.MaxSize,
+		},
+	}, nil
+}
+
+// write appends a record to the output file, returning its encoding or nil if
+// it cannot be encoded.
+func (o *jsonlOutput) write(record any) []byte {
+	blob, err := json.Marshal(record)
+	if err != nil {
+		log.Warn("Failed to encode live tracer record", "tracer", o.tracer, "err", err)
+		return nil
+	}
+	if _, err := o.logger.Write(append(blob, '\n')); err != nil {
+		log.Warn("Failed to write live tracer record", "tracer", o.tracer, "err", err)
+	}
+	return blob
+}
+
+// close flushes and closes the output file.
+func (o *jsonlOutput) close() {
+	if err := o.logger.Close(); err != nil {
+		log.Warn("Failed to close live tracer output", "tracer", o.tracer, "err", err)
+	}
+}
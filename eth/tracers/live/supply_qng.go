@@ -0,0 +1,451 @@
+package live
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// supplyFileName is the name of the active supply file.
+	supplyFileName = "supply.jsonl"
+
+	// supplyIndexName is the name of the database within the supply directory
+	// holding the supply records keyed by block, to serve lookups without
+	// scanning the supply files.
+	supplyIndexName = "index"
+
+	// supplyCacheLimit is the number of recent block totals kept in memory,
+	// used to continue the running total across reorgs.
+	supplyCacheLimit = 1024
+)
+
+var (
+	// ErrSupplyTracerDisabled is returned when querying the supply without the
+	// supply live tracer being active.
+	ErrSupplyTracerDisabled = errors.New("supply tracer not enabled")
+
+	// ErrSupplyNotFound is returned if no supply was recorded for a block.
+	ErrSupplyNotFound = errors.New("supply not recorded for block")
+)
+
+// activeSupply is the running supply tracer, if any.
+var activeSupply atomic.Pointer[supplyTracer]
+
+func init() {
+	tracers.LiveDirectory.Register("supply", newSupplyTracer)
+}
+
+// SupplyIssuance is the native coin created within a block.
+type SupplyIssuance struct {
+	GenesisAlloc *big.Int `json:"genesisAlloc"`
+	Reward       *big.Int `json:"reward"`
+	Withdrawals  *big.Int `json:"withdrawals"`
+	BridgeImport *big.Int `json:"bridgeImport"`
+}
+
+// SupplyBurn is the native coin destroyed within a block.
+type SupplyBurn struct {
+	EIP1559      *big.Int `json:"1559"`
+	Blob         *big.Int `json:"blob"`
+	Selfdestruct *big.Int `json:"selfdestruct"`
+	BridgeExport *big.Int `json:"bridgeExport"`
+}
+
+// SupplyInfo is the supply change of a single block, along with the running
+// total after applying it. The total is missing if the tracer did not see
+// the whole chain up to the block.
+type SupplyInfo struct {
+	Number     uint64          `json:"blockNumber"`
+	Hash       common.Hash     `json:"hash"`
+	ParentHash common.Hash     `json:"parentHash"`
+	Issuance   *SupplyIssuance `json:"issuance"`
+	Burn       *SupplyBurn     `json:"burn"`
+	Total      *big.Int        `json:"total,omitempty"`
+}
+
+func newSupplyInfo() *SupplyInfo {
+	return &SupplyInfo{
+		Issuance: &SupplyIssuance{
+			GenesisAlloc: new(big.Int),
+			Reward:       new(big.Int),
+			Withdrawals:  new(big.Int),
+			BridgeImport: new(big.Int),
+		},
+		Burn: &SupplyBurn{
+			EIP1559:      new(big.Int),
+			Blob:         new(big.Int),
+			Selfdestruct: new(big.Int),
+			BridgeExport: new(big.Int),
+		},
+	}
+}
+
+// add merges the issuance and burn of another delta into s.
+func (s *SupplyInfo) add(o *SupplyInfo) {
+	s.Issuance.GenesisAlloc.Add(s.Issuance.GenesisAlloc, o.Issuance.GenesisAlloc)
+	s.Issuance.Reward.Add(s.Issuance.Reward, o.Issuance.Reward)
+	s.Issuance.Withdrawals.Add(s.Issuance.Withdrawals, o.Issuance.Withdrawals)
+	s.Issuance.BridgeImport.Add(s.Issuance.BridgeImport, o.Issuance.BridgeImport)
+	s.Burn.EIP1559.Add(s.Burn.EIP1559, o.Burn.EIP1559)
+	s.Burn.Blob.Add(s.Burn.Blob, o.Burn.Blob)
+	s.Burn.Selfdestruct.Add(s.Burn.Selfdestruct, o.Burn.Selfdestruct)
+	s.Burn.BridgeExport.Add(s.Burn.BridgeExport, o.Burn.BridgeExport)
+}
+
+// Delta returns the net supply change of the block.
+func (s *SupplyInfo) Delta() *big.Int {
+	delta := new(big.Int).Add(s.Issuance.GenesisAlloc, s.Issuance.Reward)
+	delta.Add(delta, s.Issuance.Withdrawals)
+	delta.Add(delta, s.Issuance.BridgeImport)
+	delta.Sub(delta, s.Burn.EIP1559)
+	delta.Sub(delta, s.Burn.Blob)
+	delta.Sub(delta, s.Burn.Selfdestruct)
+	return delta.Sub(delta, s.Burn.BridgeExport)
+}
+
+type supplyTracerConfig struct {
+	outputConfig
+}
+
+// supplyTracer accounts the native coin issued and burnt in each block.
+// Balance changes within call frames (bridge imports and exports, pre-Cancun
+// self-destructs to self) are tracked per frame and dropped on revert.
+type supplyTracer struct {
+	config *params.ChainConfig
+	cancun bool
+
+	delta  *SupplyInfo   // Supply change of the block being processed
+	frames []*SupplyInfo // Supply changes of the open call frames
+
+	dir    string
+	output *jsonlOutput
+	index  ethdb.KeyValueStore // Supply records keyed by block number and hash
+
+	totals lru.BasicLRU[common.Hash, *big.Int] // Running totals of recent blocks
+	lock   sync.Mutex                          // Protects the running totals
+}
+
+func newSupplyTracer(cfg json.RawMessage) (*tracing.Hooks, error) {
+	var config supplyTracerConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse config: %v", err)
+		}
+	}
+	output, err := newJSONLOutput("supply", config.outputConfig, supplyFileName)
+	if err != nil {
+		return nil, err
+	}
+	index, err := leveldb.New(filepath.Join(config.Path, supplyIndexName), 16, 16, "eth/tracers/supply/", false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open supply index: %v", err)
+	}
+	t := &supplyTracer{
+		dir:    config.Path,
+		output: output,
+		index:  index,
+		totals: lru.NewBasicLRU[common.Hash, *big.Int](supplyCacheLimit),
+	}
+	if err := t.reindex(); err != nil {
+		index.Close()
+		return nil, err
+	}
+	activeSupply.Store(t)
+
+	return &tracing.Hooks{
+		OnBlockchainInit: t.OnBlockchainInit,
+		OnGenesisBlock:   t.OnGenesisBlock,
+		OnBlockStart:     t.OnBlockStart,
+		OnBlockEnd:       t.OnBlockEnd,
+		OnEnter:          t.OnEnter,
+		OnExit:           t.OnExit,
+		OnBalanceChange:  t.OnBalanceChange,
+		OnClose:          t.OnClose,
+	}, nil
+}
+
+func (t *supplyTracer) OnBlockchainInit(chainConfig *params.ChainConfig) {
+	t.config = chainConfig
+}
+
+func (t *supplyTracer) OnGenesisBlock(b *types.Block, alloc types.GenesisAlloc) {
+	// The genesis is reported on every start before the first block
+	t.lock.Lock()
+	known := t.total(0, b.Hash()) != nil
+	t.lock.Unlock()
+	if known {
+		return
+	}
+	t.delta = newSupplyInfo()
+	for _, account := range alloc {
+		if account.Balance != nil {
+			t.delta.Issuance.GenesisAlloc.Add(t.delta.Issuance.GenesisAlloc, account.Balance)
+		}
+	}
+	t.commit(b.Header())
+	t.delta = nil
+}
+
+func (t *supplyTracer) OnBlockStart(ev tracing.BlockEvent) {
+	t.delta = newSupplyInfo()
+	t.frames = t.frames[:0]
+
+	header := ev.Block.Header()
+	if t.config != nil {
+		t.cancun = t.config.IsCancun(header.Number, header.Time)
+	}
+	if header.BaseFee != nil {
+		burn := new(big.Int).SetUint64(header.GasUsed)
+		t.delta.Burn.EIP1559.Mul(burn, header.BaseFee)
+	}
+	if header.BlobGasUsed != nil && *header.BlobGasUsed > 0 && header.ExcessBlobGas != nil {
+		burn := new(big.Int).SetUint64(*header.BlobGasUsed)
+		t.delta.Burn.Blob.Mul(burn, eip4844.CalcBlobFee(*header.ExcessBlobGas))
+	}
+	t.delta.Number, t.delta.Hash, t.delta.ParentHash = header.Number.Uint64(), header.Hash(), header.ParentHash
+}
+
+func (t *supplyTracer) OnBlockEnd(err error) {
+	if t.delta == nil {
+		return
+	}
+	if err == nil {
+		t.commit(nil)
+	}
+	t.delta, t.frames = nil, t.frames[:0]
+}
+
+func (t *supplyTracer) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	frame := newSupplyInfo()
+	// Before Cancun, self-destructing to itself burns the contract balance
+	if !t.cancun && vm.OpCode(typ) == vm.SELFDESTRUCT && from == to && value != nil && value.Sign() > 0 {
+		frame.Burn.Selfdestruct.Set(value)
+	}
+	t.frames = append(t.frames, frame)
+}
+
+func (t *supplyTracer) OnExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if len(t.frames) == 0 {
+		return
+	}
+	frame := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+	if reverted {
+		return
+	}
+	t.current().add(frame)
+}
+
+func (t *supplyTracer) OnBalanceChange(a common.Address, prev, cur *big.Int, reason tracing.BalanceChangeReason) {
+	delta := t.current()
+	if delta == nil {
+		return
+	}
+	diff := new(big.Int).Sub(cur, prev)
+	switch reason {
+	case tracing.BalanceIncreaseRewardMineBlock, tracing.BalanceIncreaseRewardMineUncle:
+		delta.Issuance.Reward.Add(delta.Issuance.Reward, diff)
+	case tracing.BalanceIncreaseWithdrawal:
+		delta.Issuance.Withdrawals.Add(delta.Issuance.Withdrawals, diff)
+	case tracing.BalanceIncreaseGenesisBalance:
+		delta.Issuance.GenesisAlloc.Add(delta.Issuance.GenesisAlloc, diff)
+	case tracing.BalanceIncreaseMeerImport:
+		delta.Issuance.BridgeImport.Add(delta.Issuance.BridgeImport, diff)
+	case tracing.BalanceDecreaseSelfdestructBurn:
+		delta.Burn.Selfdestruct.Sub(delta.Burn.Selfdestruct, diff)
+	case tracing.BalanceDecreaseMeerExport:
+		delta.Burn.BridgeExport.Sub(delta.Burn.BridgeExport, diff)
+	}
+}
+
+func (t *supplyTracer) OnClose() {
+	t.output.close()
+	if err := t.index.Close(); err != nil {
+		log.Warn("Failed to close supply index", "err", err)
+	}
+	activeSupply.CompareAndSwap(t, nil)
+}
+
+// current returns the delta balance changes are accounted to.
+func (t *supplyTracer) current() *SupplyInfo {
+	if len(t.frames) > 0 {
+		return t.frames[len(t.frames)-1]
+	}
+	return t.delta
+}
+
+// commit completes the running total of the processed block and writes its
+// supply record. The header is only set for the genesis block.
+func (t *supplyTracer) commit(header *types.Header) {
+	if header != nil {
+		t.delta.Number, t.delta.Hash, t.delta.ParentHash = header.Number.Uint64(), header.Hash(), header.ParentHash
+	}
+	t.lock.Lock()
+	if header != nil {
+		t.delta.Total = t.delta.Delta()
+	} else if parent := t.total(t.delta.Number-1, t.delta.ParentHash); parent != nil {
+		t.delta.Total = new(big.Int).Add(parent, t.delta.Delta())
+	} else {
+		log.Warn("Supply total unknown, parent not traced", "number", t.delta.Number, "parent", t.delta.ParentHash)
+	}
+	if t.delta.Total != nil {
+		t.totals.Add(t.delta.Hash, t.delta.Total)
+	}
+	t.lock.Unlock()
+
+	blob := t.output.write(t.delta)
+	if blob == nil {
+		return
+	}
+	if err := t.index.Put(supplyKey(t.delta.Number, t.delta.Hash), blob); err != nil {
+		log.Warn("Failed to index supply record", "number", t.delta.Number, "err", err)
+	}
+}
+
+// supplyKey = number (uint64 big endian) + hash
+func supplyKey(number uint64, hash common.Hash) []byte {
+	return append(binary.BigEndian.AppendUint64(nil, number), hash.Bytes()...)
+}
+
+// total returns the running total after the given block, or nil if unknown.
+// The caller must hold the lock.
+func (t *supplyTracer) total(number uint64, hash common.Hash) *big.Int {
+	if total, ok := t.totals.Get(hash); ok {
+		return total
+	}
+	info, err := t.supplyAt(number, hash)
+	if err != nil || info.Total == nil {
+		return nil
+	}
+	t.totals.Add(hash, info.Total)
+	return info.Total
+}
+
+// reindex fills an empty supply index from the supply files, to pick up the
+// records written before the index existed.
+func (t *supplyTracer) reindex() error {
+	it := t.index.NewIterator(nil, nil)
+	empty := !it.Next()
+	it.Release()
+	if !empty {
+		return nil
+	}
+	var (
+		batch = t.index.NewBatch()
+		count int
+		werr  error
+	)
+	if err := t.scan(func(info *SupplyInfo) bool {
+		blob, err := json.Marshal(info)
+		if err != nil {
+			return false
+		}
+		batch.Put(supplyKey(info.Number, info.Hash), blob)
+		count++
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if werr = batch.Write(); werr != nil {
+				return true
+			}
+			batch.Reset()
+		}
+		return false
+	}); err != nil {
+		return err
+	}
+	if werr == nil {
+		werr = batch.Write()
+	}
+	if werr != nil {
+		return fmt.Errorf("failed to write supply index: %v", werr)
+	}
+	if count > 0 {
+		log.Info("Indexed supply records", "count", count)
+	}
+	return nil
+}
+
+// scan iterates over all recorded supply entries from the oldest to the most
+// recent one, until fn returns true.
+func (t *supplyTracer) scan(fn func(info *SupplyInfo) bool) error {
+	// Rotated files are timestamped and sort before the active one
+	files, err := filepath.Glob(filepath.Join(t.dir, "supply*.jsonl"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		done, err := scanSupplyFile(file, fn)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+	return nil
+}
+
+func scanSupplyFile(file string, fn func(info *SupplyInfo) bool) (bool, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		info := new(SupplyInfo)
+		if err := json.Unmarshal(scanner.Bytes(), info); err != nil {
+			// Skip partially written records
+			continue
+		}
+		if fn(info) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// supplyAt returns the last supply record written for the given block.
+func (t *supplyTracer) supplyAt(number uint64, hash common.Hash) (*SupplyInfo, error) {
+	blob, err := t.index.Get(supplyKey(number, hash))
+	if err != nil || len(blob) == 0 {
+		return nil, ErrSupplyNotFound
+	}
+	info := new(SupplyInfo)
+	if err := json.Unmarshal(blob, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// SupplyAt returns the supply change and running total recorded by the
+// supply live tracer for the given block.
+func SupplyAt(number uint64, hash common.Hash) (*SupplyInfo, error) {
+	t := activeSupply.Load()
+	if t == nil {
+		return nil, ErrSupplyTracerDisabled
+	}
+	return t.supplyAt(number, hash)
+}
@@ -0,0 +1,161 @@
+package live
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func newTestSupplyTracer(t *testing.T, dir string) (*supplyTracer, *tracing.Hooks) {
+	cfg, _ := json.Marshal(supplyTracerConfig{outputConfig{Path: dir}})
+	hooks, err := newSupplyTracer(cfg)
+	if err != nil {
+		t.Fatalf("failed to create supply tracer: %v", err)
+	}
+	return activeSupply.Load(), hooks
+}
+
+// Tests that the running supply total matches the sum of all balances after
+// importing blocks with rewards, base fee burns and a self-destruct burn.
+func TestSupplyTracer(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		burner   = common.HexToAddress("0xb0b0")
+		coinbase = common.HexToAddress("0xc014ba5e")
+		genesis  = &core.Genesis{
+			Config: params.AllEthashProtocolChanges,
+			Alloc: types.GenesisAlloc{
+				sender: {Balance: big.NewInt(params.Ether)},
+				// Self-destructs to itself, burning its balance before Cancun
+				burner: {Balance: big.NewInt(params.GWei), Code: []byte{byte(vm.ADDRESS), byte(vm.SELFDESTRUCT)}},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(genesis.Config)
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 3, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(coinbase)
+		if i == 1 {
+			tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{
+				Nonce:    0,
+				To:       &burner,
+				Value:    big.NewInt(1000),
+				Gas:      100000,
+				GasPrice: b.BaseFee(),
+			})
+			b.AddTx(tx)
+		}
+	})
+	dir := t.TempDir()
+	tracer, hooks := newTestSupplyTracer(t, dir)
+
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genesis, nil, ethash.NewFaker(), vm.Config{Tracer: hooks}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	state, _ := chain.State()
+	balances := new(big.Int)
+	for _, addr := range []common.Address{sender, burner, coinbase} {
+		balances.Add(balances, state.GetBalance(addr).ToBig())
+	}
+	head := chain.CurrentBlock()
+	info, err := SupplyAt(head.Number.Uint64(), head.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve head supply: %v", err)
+	}
+	if info.Total == nil || info.Total.Cmp(balances) != 0 {
+		t.Fatalf("supply total mismatch: have %v, want %v", info.Total, balances)
+	}
+	// Check the burns of the block carrying the self-destruct
+	info, err = SupplyAt(2, blocks[1].Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve block supply: %v", err)
+	}
+	if want := big.NewInt(params.GWei + 1000); info.Burn.Selfdestruct.Cmp(want) != 0 {
+		t.Errorf("selfdestruct burn mismatch: have %v, want %v", info.Burn.Selfdestruct, want)
+	}
+	if want := new(big.Int).Mul(blocks[1].BaseFee(), new(big.Int).SetUint64(blocks[1].GasUsed())); info.Burn.EIP1559.Cmp(want) != 0 {
+		t.Errorf("1559 burn mismatch: have %v, want %v", info.Burn.EIP1559, want)
+	}
+	if info.Issuance.Reward.Sign() <= 0 {
+		t.Errorf("missing block reward issuance")
+	}
+	chain.Stop()
+	hooks.OnClose()
+
+	// Restart the tracer and ensure the running totals are restored
+	restarted, _ := newTestSupplyTracer(t, dir)
+	if restarted == tracer {
+		t.Fatal("tracer not replaced")
+	}
+	if total := restarted.total(head.Number.Uint64(), head.Hash()); total == nil || total.Cmp(balances) != 0 {
+		t.Fatalf("restored total mismatch: have %v, want %v", total, balances)
+	}
+	restarted.OnClose()
+
+	// Drop the index and ensure it is rebuilt from the supply files
+	if err := os.RemoveAll(filepath.Join(dir, supplyIndexName)); err != nil {
+		t.Fatalf("failed to drop supply index: %v", err)
+	}
+	restarted, _ = newTestSupplyTracer(t, dir)
+	info, err = SupplyAt(head.Number.Uint64(), head.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve reindexed supply: %v", err)
+	}
+	if info.Total == nil || info.Total.Cmp(balances) != 0 {
+		t.Fatalf("reindexed total mismatch: have %v, want %v", info.Total, balances)
+	}
+	restarted.OnClose()
+	if _, err := SupplyAt(0, genesis.ToBlock().Hash()); err != ErrSupplyTracerDisabled {
+		t.Fatalf("supply query error mismatch: have %v, want %v", err, ErrSupplyTracerDisabled)
+	}
+}
+
+// Tests that bridge mints and burns within reverted call frames are dropped.
+func TestSupplyTracerRevert(t *testing.T) {
+	tracer, _ := newTestSupplyTracer(t, t.TempDir())
+	defer tracer.OnClose()
+
+	tracer.OnBlockchainInit(params.AllEthashProtocolChanges)
+	tracer.OnBlockStart(tracing.BlockEvent{Block: types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})})
+
+	var (
+		bridge = params.MeerBridgeAddress
+		amount = big.NewInt(100)
+	)
+	// Export within a reverted inner frame, import within a successful one
+	tracer.OnEnter(0, byte(vm.CALL), common.Address{}, bridge, nil, 0, nil)
+	tracer.OnEnter(1, byte(vm.CALL), common.Address{}, bridge, nil, 0, nil)
+	tracer.OnBalanceChange(bridge, amount, new(big.Int), tracing.BalanceDecreaseMeerExport)
+	tracer.OnExit(1, nil, 0, vm.ErrExecutionReverted, true)
+	tracer.OnBalanceChange(bridge, new(big.Int), amount, tracing.BalanceIncreaseMeerImport)
+	tracer.OnExit(0, nil, 0, nil, false)
+
+	// A reverted transaction drops all its changes
+	tracer.OnEnter(0, byte(vm.CALL), common.Address{}, bridge, nil, 0, nil)
+	tracer.OnBalanceChange(bridge, amount, new(big.Int), tracing.BalanceDecreaseMeerExport)
+	tracer.OnExit(0, nil, 0, vm.ErrExecutionReverted, true)
+
+	if tracer.delta.Burn.BridgeExport.Sign() != 0 {
+		t.Errorf("reverted export accounted: %v", tracer.delta.Burn.BridgeExport)
+	}
+	if tracer.delta.Issuance.BridgeImport.Cmp(amount) != 0 {
+		t.Errorf("import mismatch: have %v, want %v", tracer.delta.Issuance.BridgeImport, amount)
+	}
+}
@@ -0,0 +1,117 @@
+package live
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/internal"
+)
+
+// tokenFileName is the name of the active token movements file.
+const tokenFileName = "tokens.jsonl"
+
+func init() {
+	tracers.LiveDirectory.Register("token", newTokenTracer)
+}
+
+// TokenTx are the token movements of a transaction within a block.
+type TokenTx struct {
+	Hash  common.Hash `json:"txHash"`
+	Index int         `json:"txIndex"`
+	*internal.TokenMovements
+}
+
+// TokenBlock are the token movements of the transactions within a block. Only
+// the transactions moving tokens are listed.
+type TokenBlock struct {
+	Number       uint64      `json:"blockNumber"`
+	Hash         common.Hash `json:"hash"`
+	Transactions []*TokenTx  `json:"transactions"`
+}
+
+type tokenTracerConfig struct {
+	internal.TokenConfig
+	outputConfig
+}
+
+// tokenTracer writes the token movements of every imported block moving any
+// token as a JSON line.
+type tokenTracer struct {
+	collector *internal.TokenCollector
+
+	block *TokenBlock // Token movements of the block being processed
+	tx    *TokenTx    // Transaction being processed
+
+	output *jsonlOutput
+}
+
+func newTokenTracer(cfg json.RawMessage) (*tracing.Hooks, error) {
+	var config tokenTracerConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse config: %v", err)
+		}
+	}
+	output, err := newJSONLOutput("token", config.outputConfig, tokenFileName)
+	if err != nil {
+		return nil, err
+	}
+	t := &tokenTracer{
+		collector: internal.NewTokenCollector(config.TokenConfig),
+		output:    output,
+	}
+	hooks := t.collector.Hooks()
+	return &tracing.Hooks{
+		OnBlockStart:    t.OnBlockStart,
+		OnBlockEnd:      t.OnBlockEnd,
+		OnTxStart:       t.OnTxStart,
+		OnTxEnd:         t.OnTxEnd,
+		OnEnter:         hooks.OnEnter,
+		OnExit:          hooks.OnExit,
+		OnOpcode:        hooks.OnOpcode,
+		OnLog:           hooks.OnLog,
+		OnStorageChange: hooks.OnStorageChange,
+		OnClose:         t.OnClose,
+	}, nil
+}
+
+func (t *tokenTracer) OnBlockStart(ev tracing.BlockEvent) {
+	t.block = &TokenBlock{Number: ev.Block.NumberU64(), Hash: ev.Block.Hash(), Transactions: []*TokenTx{}}
+}
+
+func (t *tokenTracer) OnBlockEnd(err error) {
+	if t.block == nil {
+		return
+	}
+	if err == nil && len(t.block.Transactions) > 0 {
+		t.output.write(t.block)
+	}
+	t.block, t.tx = nil, nil
+}
+
+func (t *tokenTracer) OnTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	t.collector.Reset()
+	if t.block != nil {
+		t.tx = &TokenTx{Hash: tx.Hash()}
+	}
+}
+
+func (t *tokenTracer) OnTxEnd(receipt *types.Receipt, err error) {
+	if t.block == nil || t.tx == nil || receipt == nil || err != nil {
+		return
+	}
+	t.tx.Index = int(receipt.TransactionIndex)
+	if movements := t.collector.Movements(); !movements.Empty() {
+		t.tx.TokenMovements = movements
+		t.block.Transactions = append(t.block.Transactions, t.tx)
+	}
+	t.tx = nil
+}
+
+func (t *tokenTracer) OnClose() {
+	t.output.close()
+}
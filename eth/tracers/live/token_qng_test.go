@@ -0,0 +1,105 @@
+package live
+
+import (
+	"bufio"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the token tracer only writes the blocks moving tokens, listing
+// the transfers of each of their transactions.
+func TestTokenTracerBlocks(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+		token  = common.HexToAddress("0x70ce")
+		topic  = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+		// Emits Transfer(0, caller, 100)
+		tokenCode = append(append([]byte{
+			byte(vm.PUSH1), 100, byte(vm.PUSH1), 0, byte(vm.MSTORE),
+			byte(vm.CALLER), byte(vm.PUSH1), 0, byte(vm.PUSH32)}, topic.Bytes()...),
+			byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.LOG3), byte(vm.STOP),
+		)
+		genesis = &core.Genesis{
+			Config: params.AllEthashProtocolChanges,
+			Alloc: types.GenesisAlloc{
+				sender: {Balance: big.NewInt(params.Ether)},
+				token:  {Code: tokenCode},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(genesis.Config)
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 3, func(i int, b *core.BlockGen) {
+		if i == 1 {
+			tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{
+				Nonce:    0,
+				To:       &token,
+				Gas:      100000,
+				GasPrice: b.BaseFee(),
+			})
+			b.AddTx(tx)
+		}
+	})
+	dir := t.TempDir()
+	cfg, _ := json.Marshal(tokenTracerConfig{outputConfig: outputConfig{Path: dir}})
+	hooks, err := newTokenTracer(cfg)
+	if err != nil {
+		t.Fatalf("failed to create token tracer: %v", err)
+	}
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genesis, nil, ethash.NewFaker(), vm.Config{Tracer: hooks}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.Stop()
+	hooks.OnClose()
+
+	file, err := os.Open(filepath.Join(dir, tokenFileName))
+	if err != nil {
+		t.Fatalf("failed to open token movements: %v", err)
+	}
+	defer file.Close()
+
+	var written []TokenBlock
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var block TokenBlock
+		if err := json.Unmarshal(scanner.Bytes(), &block); err != nil {
+			t.Fatalf("failed to decode token movements: %v", err)
+		}
+		written = append(written, block)
+	}
+	if len(written) != 1 {
+		t.Fatalf("written block count mismatch: have %d, want 1", len(written))
+	}
+	block := written[0]
+	if block.Number != 2 || block.Hash != blocks[1].Hash() {
+		t.Fatalf("written block mismatch: have #%d %x, want #2 %x", block.Number, block.Hash, blocks[1].Hash())
+	}
+	if len(block.Transactions) != 1 || block.Transactions[0].Hash != blocks[1].Transactions()[0].Hash() {
+		t.Fatalf("written transactions mismatch: %+v", block.Transactions)
+	}
+	transfers := block.Transactions[0].Transfers
+	if len(transfers) != 1 {
+		t.Fatalf("transfer count mismatch: have %d, want 1", len(transfers))
+	}
+	if have := transfers[0]; have.Token != token || have.To != sender || have.Amount.ToInt().Int64() != 100 {
+		t.Fatalf("transfer mismatch: %+v", have)
+	}
+}
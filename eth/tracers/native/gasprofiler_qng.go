@@ -0,0 +1,375 @@
+package native
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+)
+
+func init() {
+	tracers.DefaultDirectory.Register("gasProfiler", newGasProfiler, false)
+}
+
+// gasCategory is the class of opcodes gas is attributed to.
+type gasCategory int
+
+const (
+	gasCompute gasCategory = iota // Arithmetic, hashing, control flow, logs and precompiles
+	gasStorage                    // Storage, transient storage and account state access
+	gasMemory                     // Memory, calldata, code and return data access
+	gasCall                       // Calls and contract creations, excluding the gas forwarded
+	gasCategories
+)
+
+var gasCategoryNames = [gasCategories]string{"compute", "storage", "memory", "call"}
+
+// opGasCategory returns the category the gas of an opcode is attributed to.
+func opGasCategory(op vm.OpCode) gasCategory {
+	switch op {
+	case vm.SLOAD, vm.SSTORE, vm.TLOAD, vm.TSTORE,
+		vm.BALANCE, vm.SELFBALANCE, vm.EXTCODESIZE, vm.EXTCODECOPY, vm.EXTCODEHASH, vm.SELFDESTRUCT:
+		return gasStorage
+	case vm.MLOAD, vm.MSTORE, vm.MSTORE8, vm.MCOPY, vm.MSIZE,
+		vm.CALLDATACOPY, vm.CODECOPY, vm.RETURNDATACOPY, vm.RETURN, vm.REVERT:
+		return gasMemory
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL, vm.CREATE, vm.CREATE2:
+		return gasCall
+	}
+	return gasCompute
+}
+
+// gasBreakdown is the gas spent per opcode category.
+type gasBreakdown [gasCategories]uint64
+
+func (b *gasBreakdown) add(other *gasBreakdown) {
+	for i := range b {
+		b[i] += other[i]
+	}
+}
+
+func (b *gasBreakdown) total() (total uint64) {
+	for _, gas := range b {
+		total += gas
+	}
+	return total
+}
+
+// MarshalJSON encodes the breakdown as an object keyed by category name.
+func (b gasBreakdown) MarshalJSON() ([]byte, error) {
+	enc := make(map[string]hexutil.Uint64, gasCategories)
+	for i, gas := range b {
+		enc[gasCategoryNames[i]] = hexutil.Uint64(gas)
+	}
+	return json.Marshal(enc)
+}
+
+// gasProfileFrame is the gas profile of a call frame.
+type gasProfileFrame struct {
+	Type       string             `json:"type"`
+	To         common.Address     `json:"to"`
+	Selector   hexutil.Bytes      `json:"selector,omitempty"`
+	Gas        hexutil.Uint64     `json:"gas"`
+	GasUsed    hexutil.Uint64     `json:"gasUsed"` // Gas spent by the frame and its subcalls
+	SelfGas    hexutil.Uint64     `json:"selfGas"` // Gas spent by the frame itself
+	Categories gasBreakdown       `json:"categories"`
+	Error      string             `json:"error,omitempty"`
+	Calls      []*gasProfileFrame `json:"calls,omitempty"`
+
+	breakdown gasBreakdown // Gas of the executed opcodes, by category
+	callCost  uint64       // Cost of the pending call opcode, including the gas forwarded
+	faulted   bool         // Whether the frame failed on an opcode error
+	faultCat  gasCategory  // Category of the failing opcode
+}
+
+// label returns the name of the frame in the folded stack output.
+func (f *gasProfileFrame) label() string {
+	label := f.Type + ":" + strings.ToLower(f.To.Hex())
+	if len(f.Selector) > 0 {
+		label += ":" + f.Selector.String()
+	}
+	return label
+}
+
+// gasProfileEntry is the gas spent by a contract, or a function of a contract,
+// aggregated over all the frames executing it.
+type gasProfileEntry struct {
+	Address    common.Address `json:"address"`
+	Selector   hexutil.Bytes  `json:"selector,omitempty"`
+	Calls      hexutil.Uint64 `json:"calls"`
+	SelfGas    hexutil.Uint64 `json:"selfGas"`
+	Categories gasBreakdown   `json:"categories"`
+}
+
+type gasProfileResult struct {
+	GasUsed      hexutil.Uint64     `json:"gasUsed"`      // Intrinsic and execution gas, minus the refund
+	IntrinsicGas hexutil.Uint64     `json:"intrinsicGas"` // Gas charged before the execution, zero for calls
+	Refund       hexutil.Uint64     `json:"refund"`       // Gas refunded after the execution, zero for calls
+	Categories   gasBreakdown       `json:"categories"`   // Execution gas, by category
+	Contracts    []*gasProfileEntry `json:"contracts"`
+	Functions    []*gasProfileEntry `json:"functions"`
+	Root         *gasProfileFrame   `json:"root"`
+	Folded       *string            `json:"folded,omitempty"`
+}
+
+type gasProfilerConfig struct {
+	Folded bool `json:"folded"` // If true, the profile is also returned in the folded stack format
+}
+
+// gasProfiler aggregates the gas spent by a transaction per call frame,
+// contract, function selector and opcode category. The profile can also be
+// returned in the folded stack format read by flame graph tools such as
+// flamegraph.pl, inferno or speedscope, with one line per call stack and
+// opcode category:
+//
+//	CALL:0x...:0xa9059cbb;DELEGATECALL:0x...:0xa9059cbb;storage 22100
+//
+// Gas forwarded to subcalls is attributed to the subcalls, and gas burnt by
+// failing opcodes to the category of the failing opcode. Precompile frames
+// are accounted as compute. The categories only cover the execution gas: when
+// profiling a transaction, its intrinsic gas and refund are reported apart, so
+// that the gas used is the intrinsic gas plus the categories minus the refund.
+// The intrinsic gas is folded as a stack of its own.
+//
+// Example:
+//
+//	> debug.traceCall({to: "0x...", data: "0x..."}, "latest", {tracer: "gasProfiler", tracerConfig: {folded: true}})
+type gasProfiler struct {
+	config    gasProfilerConfig
+	callstack []*gasProfileFrame
+	root      *gasProfileFrame
+	gasLimit  uint64
+	execGas   uint64 // Gas available to the execution, after the intrinsic gas
+	txGasUsed uint64 // Gas used by the transaction, if executed as one
+
+	interrupt atomic.Bool // Atomic flag to signal execution interruption
+	reason    error       // Textual reason for the interruption
+}
+
+// newGasProfiler returns a native go tracer which profiles the gas spent by a
+// transaction.
+func newGasProfiler(ctx *tracers.Context, cfg json.RawMessage) (*tracers.Tracer, error) {
+	var config gasProfilerConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	t := &gasProfiler{config: config}
+	return &tracers.Tracer{
+		Hooks: &tracing.Hooks{
+			OnTxStart: t.OnTxStart,
+			OnTxEnd:   t.OnTxEnd,
+			OnEnter:   t.OnEnter,
+			OnExit:    t.OnExit,
+			OnOpcode:  t.OnOpcode,
+			OnFault:   t.OnFault,
+		},
+		GetResult: t.GetResult,
+		Stop:      t.Stop,
+	}, nil
+}
+
+func (t *gasProfiler) OnTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	t.gasLimit = tx.Gas()
+}
+
+func (t *gasProfiler) OnTxEnd(receipt *types.Receipt, err error) {
+	if err != nil || receipt == nil {
+		return
+	}
+	t.txGasUsed = receipt.GasUsed
+}
+
+func (t *gasProfiler) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.interrupt.Load() {
+		return
+	}
+	frame := &gasProfileFrame{
+		Type: vm.OpCode(typ).String(),
+		To:   to,
+		Gas:  hexutil.Uint64(gas),
+	}
+	if op := vm.OpCode(typ); op != vm.CREATE && op != vm.CREATE2 && len(input) >= 4 {
+		frame.Selector = common.CopyBytes(input[:4])
+	}
+	if len(t.callstack) == 0 {
+		t.execGas = gas
+	} else {
+		// The cost of a call opcode includes the gas forwarded to the callee,
+		// which is accounted in the callee frame instead.
+		parent := t.callstack[len(t.callstack)-1]
+		if parent.callCost > gas {
+			parent.breakdown[gasCall] += parent.callCost - gas
+		}
+		parent.callCost = 0
+	}
+	t.callstack = append(t.callstack, frame)
+}
+
+func (t *gasProfiler) OnExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if t.interrupt.Load() || len(t.callstack) == 0 {
+		return
+	}
+	frame := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+
+	frame.GasUsed = hexutil.Uint64(gasUsed)
+	if err != nil {
+		frame.Error = err.Error()
+	}
+	// Account the gas not spent by any opcode, i.e. the gas burnt by a failing
+	// opcode or spent by a precompile, before deriving the frame own gas.
+	var children uint64
+	for _, call := range frame.Calls {
+		children += uint64(call.GasUsed)
+	}
+	self := gasUsed - min(children, gasUsed)
+	if spent := frame.breakdown.total(); self > spent {
+		cat := gasCompute
+		if frame.faulted {
+			cat = frame.faultCat
+		}
+		frame.breakdown[cat] += self - spent
+	}
+	frame.SelfGas = hexutil.Uint64(self)
+	frame.Categories = frame.breakdown
+
+	if len(t.callstack) == 0 {
+		t.root = frame
+		return
+	}
+	parent := t.callstack[len(t.callstack)-1]
+	parent.Calls = append(parent.Calls, frame)
+}
+
+func (t *gasProfiler) OnOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	if t.interrupt.Load() || len(t.callstack) == 0 {
+		return
+	}
+	frame := t.callstack[len(t.callstack)-1]
+	if err != nil {
+		// The opcode failed before execution, burning the gas left
+		frame.faulted, frame.faultCat = true, opGasCategory(vm.OpCode(op))
+		return
+	}
+	switch opcode := vm.OpCode(op); opcode {
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		// Settled once the forwarded gas is known on entering the callee
+		frame.callCost = cost
+	default:
+		frame.breakdown[opGasCategory(opcode)] += cost
+	}
+}
+
+func (t *gasProfiler) OnFault(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, depth int, err error) {
+	if t.interrupt.Load() || len(t.callstack) == 0 {
+		return
+	}
+	frame := t.callstack[len(t.callstack)-1]
+	frame.faulted, frame.faultCat = true, opGasCategory(vm.OpCode(op))
+}
+
+// GetResult returns the json-encoded gas profile, and any error arising from
+// the encoding or forceful termination (via `Stop`).
+func (t *gasProfiler) GetResult() (json.RawMessage, error) {
+	if t.root == nil {
+		return nil, errors.New("no call frame profiled")
+	}
+	result := &gasProfileResult{
+		GasUsed: t.root.GasUsed,
+		Root:    t.root,
+	}
+	if t.gasLimit > 0 {
+		// Executed as a transaction, account the gas spent outside the frames
+		t.root.Gas = hexutil.Uint64(t.gasLimit)
+		result.IntrinsicGas = hexutil.Uint64(t.gasLimit - min(t.execGas, t.gasLimit))
+		result.GasUsed += result.IntrinsicGas
+
+		if t.txGasUsed > 0 && uint64(result.GasUsed) > t.txGasUsed {
+			result.Refund = result.GasUsed - hexutil.Uint64(t.txGasUsed)
+			result.GasUsed = hexutil.Uint64(t.txGasUsed)
+		}
+	}
+	var (
+		contracts = make(map[common.Address]*gasProfileEntry)
+		functions = make(map[string]*gasProfileEntry)
+		folded    strings.Builder
+	)
+	var walk func(frame *gasProfileFrame, stack string)
+	walk = func(frame *gasProfileFrame, stack string) {
+		result.Categories.add(&frame.Categories)
+
+		contract, ok := contracts[frame.To]
+		if !ok {
+			contract = &gasProfileEntry{Address: frame.To}
+			contracts[frame.To] = contract
+			result.Contracts = append(result.Contracts, contract)
+		}
+		contract.Calls++
+		contract.SelfGas += frame.SelfGas
+		contract.Categories.add(&frame.Categories)
+
+		key := string(frame.To.Bytes()) + string(frame.Selector)
+		function, ok := functions[key]
+		if !ok {
+			function = &gasProfileEntry{Address: frame.To, Selector: frame.Selector}
+			functions[key] = function
+			result.Functions = append(result.Functions, function)
+		}
+		function.Calls++
+		function.SelfGas += frame.SelfGas
+		function.Categories.add(&frame.Categories)
+
+		if stack != "" {
+			stack += ";"
+		}
+		stack += frame.label()
+		if t.config.Folded {
+			for i, gas := range frame.Categories {
+				if gas > 0 {
+					fmt.Fprintf(&folded, "%s;%s %d\n", stack, gasCategoryNames[i], gas)
+				}
+			}
+		}
+		for _, call := range frame.Calls {
+			walk(call, stack)
+		}
+	}
+	walk(t.root, "")
+	if t.config.Folded && result.IntrinsicGas > 0 {
+		fmt.Fprintf(&folded, "intrinsic %d\n", result.IntrinsicGas)
+	}
+
+	byGas := func(a, b *gasProfileEntry) int {
+		return cmp.Compare(b.SelfGas, a.SelfGas)
+	}
+	slices.SortStableFunc(result.Contracts, byGas)
+	slices.SortStableFunc(result.Functions, byGas)
+	if t.config.Folded {
+		out := folded.String()
+		result.Folded = &out
+	}
+	res, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *gasProfiler) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+}
@@ -0,0 +1,172 @@
+package native_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+type gasProfileFrame struct {
+	Type       string                    `json:"type"`
+	To         common.Address            `json:"to"`
+	Selector   hexutil.Bytes             `json:"selector"`
+	GasUsed    hexutil.Uint64            `json:"gasUsed"`
+	SelfGas    hexutil.Uint64            `json:"selfGas"`
+	Categories map[string]hexutil.Uint64 `json:"categories"`
+	Calls      []*gasProfileFrame        `json:"calls"`
+}
+
+type gasProfileEntry struct {
+	Address  common.Address `json:"address"`
+	Selector hexutil.Bytes  `json:"selector"`
+	Calls    hexutil.Uint64 `json:"calls"`
+	SelfGas  hexutil.Uint64 `json:"selfGas"`
+}
+
+type gasProfile struct {
+	GasUsed    hexutil.Uint64            `json:"gasUsed"`
+	Categories map[string]hexutil.Uint64 `json:"categories"`
+	Contracts  []*gasProfileEntry        `json:"contracts"`
+	Functions  []*gasProfileEntry        `json:"functions"`
+	Root       *gasProfileFrame          `json:"root"`
+	Folded     string                    `json:"folded"`
+}
+
+// categoriesSum returns the gas of a frame summed over the opcode categories.
+func categoriesSum(categories map[string]hexutil.Uint64) (sum hexutil.Uint64) {
+	for _, gas := range categories {
+		sum += gas
+	}
+	return sum
+}
+
+// Tests that the gas profiler attributes the gas of nested calls to the call
+// frames, contracts, functions and opcode categories spending it.
+func TestGasProfiler(t *testing.T) {
+	var (
+		caller = common.HexToAddress("0xca11e7")
+		callee = common.HexToAddress("0xca11ee")
+
+		// Calls the callee with the 0xdeadbeef selector
+		callerCode = append(append([]byte{
+			byte(vm.PUSH4), 0xde, 0xad, 0xbe, 0xef, byte(vm.PUSH1), 224, byte(vm.SHL), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+			byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 4, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+			byte(vm.PUSH20)}, callee.Bytes()...),
+			byte(vm.GAS), byte(vm.CALL), byte(vm.STOP),
+		)
+		// Writes a cold storage slot
+		calleeCode = []byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP)}
+	)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(caller, callerCode)
+	statedb.SetCode(callee, calleeCode)
+
+	tracer, err := tracers.DefaultDirectory.New("gasProfiler", &tracers.Context{}, json.RawMessage(`{"folded":true}`))
+	require.NoError(t, err)
+
+	_, leftOver, err := runtime.Call(caller, []byte{0x12, 0x34, 0x56, 0x78}, &runtime.Config{
+		GasLimit:  1000000,
+		State:     statedb,
+		EVMConfig: vm.Config{Tracer: tracer.Hooks},
+	})
+	require.NoError(t, err)
+
+	res, err := tracer.GetResult()
+	require.NoError(t, err)
+	var profile gasProfile
+	require.NoError(t, json.Unmarshal(res, &profile))
+
+	root := profile.Root
+	require.Equal(t, hexutil.Uint64(1000000-leftOver), profile.GasUsed)
+	require.Equal(t, profile.GasUsed, root.GasUsed)
+	require.Equal(t, hexutil.Bytes{0x12, 0x34, 0x56, 0x78}, root.Selector)
+	require.Len(t, root.Calls, 1)
+
+	child := root.Calls[0]
+	require.Equal(t, "CALL", child.Type)
+	require.Equal(t, callee, child.To)
+	require.Equal(t, hexutil.Bytes{0xde, 0xad, 0xbe, 0xef}, child.Selector)
+	require.Equal(t, hexutil.Uint64(22100), child.Categories["storage"]) // Cold zero to non-zero write
+	require.Equal(t, hexutil.Uint64(6), child.Categories["compute"])
+
+	// The frame gas must add up across the tree and the categories
+	require.Equal(t, root.GasUsed, root.SelfGas+child.GasUsed)
+	require.Equal(t, child.GasUsed, child.SelfGas)
+	for _, frame := range []*gasProfileFrame{root, child} {
+		require.Equal(t, frame.SelfGas, categoriesSum(frame.Categories))
+	}
+	require.Equal(t, profile.GasUsed, categoriesSum(profile.Categories))
+	require.NotZero(t, root.Categories["call"])
+	require.NotZero(t, root.Categories["memory"])
+
+	// The callee writing storage is the most expensive contract and function
+	require.Len(t, profile.Contracts, 2)
+	require.Equal(t, callee, profile.Contracts[0].Address)
+	require.Equal(t, hexutil.Uint64(1), profile.Contracts[0].Calls)
+	require.Len(t, profile.Functions, 2)
+	require.Equal(t, hexutil.Bytes{0xde, 0xad, 0xbe, 0xef}, profile.Functions[0].Selector)
+	require.Equal(t, child.SelfGas, profile.Functions[0].SelfGas)
+
+	stack := "CALL:" + addrHex(caller) + ":0x12345678;CALL:" + addrHex(callee) + ":0xdeadbeef"
+	require.Contains(t, strings.Split(profile.Folded, "\n"), stack+";storage 22100")
+}
+
+// Tests that the gas profiler reports the intrinsic gas and the refund of a
+// transaction apart from the execution gas covered by the categories.
+func TestGasProfilerTransaction(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		from    = crypto.PubkeyToAddress(key.PublicKey)
+		cleaner = common.HexToAddress("0xc1ea4e")
+		config  = params.MergedTestChainConfig
+		signer  = types.LatestSigner(config)
+	)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetBalance(from, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	statedb.SetCode(cleaner, []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP)}) // Clears slot 0
+	statedb.SetState(cleaner, common.Hash{}, common.Hash{0x01})
+
+	tracer, err := tracers.DefaultDirectory.New("gasProfiler", &tracers.Context{}, json.RawMessage(`{"folded":true}`))
+	require.NoError(t, err)
+
+	header := &types.Header{Number: big.NewInt(1), GasLimit: 10_000_000, BaseFee: big.NewInt(params.InitialBaseFee), Difficulty: common.Big0}
+	tx := types.MustSignNewTx(key, signer, &types.LegacyTx{
+		To:       &cleaner,
+		Gas:      100_000,
+		GasPrice: header.BaseFee,
+		Data:     []byte{0x12, 0x34, 0x56, 0x78},
+	})
+	receipt, err := core.ApplyTransaction(config, nil, &common.Address{}, new(core.GasPool).AddGas(header.GasLimit), statedb, header, tx, new(uint64), vm.Config{Tracer: tracer.Hooks})
+	require.NoError(t, err)
+
+	res, err := tracer.GetResult()
+	require.NoError(t, err)
+	var profile struct {
+		gasProfile
+		IntrinsicGas hexutil.Uint64 `json:"intrinsicGas"`
+		Refund       hexutil.Uint64 `json:"refund"`
+	}
+	require.NoError(t, json.Unmarshal(res, &profile))
+
+	require.Equal(t, hexutil.Uint64(params.TxGas+4*params.TxDataNonZeroGasEIP2028), profile.IntrinsicGas)
+	require.NotZero(t, profile.Refund)
+	require.Equal(t, hexutil.Uint64(receipt.GasUsed), profile.GasUsed)
+	require.Equal(t, profile.GasUsed, profile.IntrinsicGas+categoriesSum(profile.Categories)-profile.Refund)
+	require.Contains(t, strings.Split(profile.Folded, "\n"), "intrinsic 21064")
+}
@@ -0,0 +1,77 @@
+package native
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/internal"
+)
+
+func init() {
+	tracers.DefaultDirectory.Register("tokenTracer", newTokenTracer, false)
+}
+
+// tokenTracer reports the token movements of a transaction, decoded from the
+// standard ERC-20/721 Transfer and ERC-1155 TransferSingle/TransferBatch events
+// and, for the tokens listed in the config, from the writes to their balances
+// mapping.
+//
+// Example:
+//
+//	> debug.traceTransaction("0x...", {tracer: "tokenTracer", tracerConfig: {balanceSlots: {"0x...": 0}}})
+//	{
+//	  transfers: [{
+//	    amount: "0x64",
+//	    from: "0x...",
+//	    standard: "erc20",
+//	    to: "0x...",
+//	    token: "0x..."
+//	  }],
+//	  balanceChanges: [...]
+//	}
+type tokenTracer struct {
+	collector *internal.TokenCollector
+	reason    error // Textual reason for the interruption
+}
+
+// newTokenTracer returns a native go tracer which collects the token movements
+// of a transaction.
+func newTokenTracer(ctx *tracers.Context, cfg json.RawMessage) (*tracers.Tracer, error) {
+	var config internal.TokenConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	t := &tokenTracer{collector: internal.NewTokenCollector(config)}
+	hooks := t.collector.Hooks()
+	hooks.OnTxStart = t.OnTxStart
+
+	return &tracers.Tracer{
+		Hooks:     hooks,
+		GetResult: t.GetResult,
+		Stop:      t.Stop,
+	}, nil
+}
+
+func (t *tokenTracer) OnTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	t.collector.Reset()
+}
+
+// GetResult returns the json-encoded token movements, and any error arising
+// from the encoding or forceful termination (via `Stop`).
+func (t *tokenTracer) GetResult() (json.RawMessage, error) {
+	res, err := json.Marshal(t.collector.Movements())
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *tokenTracer) Stop(err error) {
+	t.reason = err
+}
@@ -0,0 +1,134 @@
+package native_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/stretchr/testify/require"
+)
+
+// addrHex returns the address as encoded in JSON.
+func addrHex(addr common.Address) string {
+	return strings.ToLower(addr.Hex())
+}
+
+// Tests that the token tracer decodes transfer events and balance slot writes,
+// dropping the movements of reverted call frames.
+func TestTokenTracer(t *testing.T) {
+	var (
+		token    = common.HexToAddress("0x70ce")
+		reverter = common.HexToAddress("0xdead")
+		holder   = common.HexToAddress("0xb01de7")
+		topic    = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+		// Mints 100 tokens to the caller: balances[caller] = 100 with the
+		// balances mapping at slot 0, then emits Transfer(0, caller, 100).
+		tokenCode = append(append([]byte{
+			byte(vm.CALLER), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+			byte(vm.PUSH1), 0, byte(vm.PUSH1), 32, byte(vm.MSTORE),
+			byte(vm.PUSH1), 100, byte(vm.PUSH1), 64, byte(vm.PUSH1), 0, byte(vm.KECCAK256), byte(vm.SSTORE),
+			byte(vm.PUSH1), 100, byte(vm.PUSH1), 0, byte(vm.MSTORE),
+			byte(vm.CALLER), byte(vm.PUSH1), 0, byte(vm.PUSH32)}, topic.Bytes()...),
+			byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.LOG3), byte(vm.STOP),
+		)
+		// Calls the token, then reverts
+		reverterCode = append(append([]byte{
+			byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+			byte(vm.PUSH20)}, token.Bytes()...),
+			byte(vm.GAS), byte(vm.CALL), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT),
+		)
+	)
+	run := func(to common.Address) map[string]json.RawMessage {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetCode(token, tokenCode)
+		statedb.SetCode(reverter, reverterCode)
+
+		cfg := json.RawMessage(`{"balanceSlots":{"` + addrHex(token) + `":0}}`)
+		tracer, err := tracers.DefaultDirectory.New("tokenTracer", &tracers.Context{}, cfg)
+		require.NoError(t, err)
+		statedb.SetLogger(tracer.Hooks)
+
+		runtime.Call(to, nil, &runtime.Config{
+			Origin:    holder,
+			GasLimit:  1000000,
+			State:     statedb,
+			EVMConfig: vm.Config{Tracer: tracer.Hooks},
+		})
+		res, err := tracer.GetResult()
+		require.NoError(t, err)
+
+		var result map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(res, &result))
+		return result
+	}
+	result := run(token)
+	require.JSONEq(t, `[{"token":"`+addrHex(token)+`","standard":"erc20","from":"0x0000000000000000000000000000000000000000","to":"`+addrHex(holder)+`","amount":"0x64"}]`, string(result["transfers"]))
+	require.JSONEq(t, `[{"token":"`+addrHex(token)+`","holder":"`+addrHex(holder)+`","from":"0x0","to":"0x64"}]`, string(result["balanceChanges"]))
+
+	result = run(reverter)
+	require.JSONEq(t, `[]`, string(result["transfers"]))
+	require.NotContains(t, result, "balanceChanges")
+}
+
+// Tests the decoding of ERC-721 and ERC-1155 transfer events.
+func TestTokenTracerNFTs(t *testing.T) {
+	tracer, err := tracers.DefaultDirectory.New("tokenTracer", &tracers.Context{}, nil)
+	require.NoError(t, err)
+
+	var (
+		token    = common.HexToAddress("0x70ce")
+		operator = common.HexToHash("0x01")
+		from     = common.HexToHash("0x02")
+		to       = common.HexToHash("0x03")
+		word     = func(n int64) []byte { return common.BigToHash(big.NewInt(n)).Bytes() }
+		concat   = func(words ...[]byte) (data []byte) {
+			for _, w := range words {
+				data = append(data, w...)
+			}
+			return data
+		}
+	)
+	tracer.OnEnter(0, byte(vm.CALL), common.Address{}, token, nil, 0, nil)
+	tracer.OnLog(&types.Log{
+		Address: token,
+		Topics:  []common.Hash{crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")), from, to, common.BigToHash(big.NewInt(7))},
+	})
+	tracer.OnLog(&types.Log{
+		Address: token,
+		Topics:  []common.Hash{crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)")), operator, from, to},
+		Data:    concat(word(1), word(10)),
+	})
+	tracer.OnLog(&types.Log{
+		Address: token,
+		Topics:  []common.Hash{crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])")), operator, from, to},
+		Data:    concat(word(64), word(160), word(2), word(2), word(3), word(2), word(20), word(30)),
+	})
+	tracer.OnExit(0, nil, 0, nil, false)
+
+	res, err := tracer.GetResult()
+	require.NoError(t, err)
+	var (
+		fromAddr = addrHex(common.BytesToAddress(from.Bytes()))
+		toAddr   = addrHex(common.BytesToAddress(to.Bytes()))
+		opAddr   = addrHex(common.BytesToAddress(operator.Bytes()))
+		erc1155  = func(id, amount string) string {
+			return `{"token":"` + addrHex(token) + `","standard":"erc1155","operator":"` + opAddr + `","from":"` + fromAddr + `","to":"` + toAddr + `","id":"` + id + `","amount":"` + amount + `"}`
+		}
+	)
+	require.JSONEq(t, `{"transfers":[
+		{"token":"`+addrHex(token)+`","standard":"erc721","from":"`+fromAddr+`","to":"`+toAddr+`","id":"0x7","amount":"0x1"},
+		`+erc1155("0x1", "0xa")+`,
+		`+erc1155("0x2", "0x14")+`,
+		`+erc1155("0x3", "0x1e")+`
+	]}`, string(res))
+}
@@ -0,0 +1,420 @@
+package tracers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// ParityTrace is a single flat call frame in the OpenEthereum trace format, as
+// produced by the flatCallTracer.
+type ParityTrace struct {
+	Action              ParityTraceAction  `json:"action"`
+	BlockHash           *common.Hash       `json:"blockHash,omitempty"`
+	BlockNumber         *uint64            `json:"blockNumber,omitempty"`
+	Error               string             `json:"error,omitempty"`
+	Result              *ParityTraceResult `json:"result,omitempty"`
+	Subtraces           int                `json:"subtraces"`
+	TraceAddress        []int              `json:"traceAddress"`
+	TransactionHash     *common.Hash       `json:"transactionHash,omitempty"`
+	TransactionPosition *uint64            `json:"transactionPosition,omitempty"`
+	Type                string             `json:"type"`
+}
+
+// ParityTraceAction is the action of a flat call frame.
+type ParityTraceAction struct {
+	Author         *common.Address `json:"author,omitempty"`
+	RewardType     string          `json:"rewardType,omitempty"`
+	SelfDestructed *common.Address `json:"address,omitempty"`
+	Balance        *hexutil.Big    `json:"balance,omitempty"`
+	CallType       string          `json:"callType,omitempty"`
+	CreationMethod string          `json:"creationMethod,omitempty"`
+	From           *common.Address `json:"from,omitempty"`
+	Gas            *hexutil.Uint64 `json:"gas,omitempty"`
+	Init           *hexutil.Bytes  `json:"init,omitempty"`
+	Input          *hexutil.Bytes  `json:"input,omitempty"`
+	RefundAddress  *common.Address `json:"refundAddress,omitempty"`
+	To             *common.Address `json:"to,omitempty"`
+	Value          *hexutil.Big    `json:"value,omitempty"`
+}
+
+// ParityTraceResult is the result of a successful flat call frame.
+type ParityTraceResult struct {
+	Address *common.Address `json:"address,omitempty"`
+	Code    *hexutil.Bytes  `json:"code,omitempty"`
+	GasUsed *hexutil.Uint64 `json:"gasUsed,omitempty"`
+	Output  *hexutil.Bytes  `json:"output,omitempty"`
+}
+
+// sender returns the account initiating the traced action.
+func (t *ParityTrace) sender() *common.Address {
+	if t.Type == "suicide" {
+		return t.Action.SelfDestructed
+	}
+	return t.Action.From
+}
+
+// recipient returns the account receiving the traced action.
+func (t *ParityTrace) recipient() *common.Address {
+	switch t.Type {
+	case "create":
+		if t.Result != nil {
+			return t.Result.Address
+		}
+		return nil
+	case "suicide":
+		return t.Action.RefundAddress
+	}
+	return t.Action.To
+}
+
+// TraceResults is the outcome of replaying a transaction in the requested
+// trace modes. Modes not requested are left empty.
+type TraceResults struct {
+	Output          hexutil.Bytes                        `json:"output"`
+	StateDiff       map[common.Address]*AccountStateDiff `json:"stateDiff"`
+	Trace           []*ParityTrace                       `json:"trace"`
+	VMTrace         *VMTrace                             `json:"vmTrace"`
+	TransactionHash *common.Hash                         `json:"transactionHash,omitempty"`
+}
+
+// DiffValue is a value change in the OpenEthereum state diff format: "=" if
+// unchanged, {"+": to} if created, {"-": from} if deleted and
+// {"*": {"from": from, "to": to}} if modified.
+type DiffValue struct {
+	From interface{}
+	To   interface{}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d *DiffValue) MarshalJSON() ([]byte, error) {
+	switch {
+	case d.From == nil && d.To == nil:
+		return json.Marshal("=")
+	case d.From == nil:
+		return json.Marshal(map[string]interface{}{"+": d.To})
+	case d.To == nil:
+		return json.Marshal(map[string]interface{}{"-": d.From})
+	}
+	return json.Marshal(map[string]interface{}{"*": map[string]interface{}{"from": d.From, "to": d.To}})
+}
+
+// AccountStateDiff is the state change of a single account.
+type AccountStateDiff struct {
+	Balance *DiffValue                 `json:"balance"`
+	Nonce   *DiffValue                 `json:"nonce"`
+	Code    *DiffValue                 `json:"code"`
+	Storage map[common.Hash]*DiffValue `json:"storage"`
+}
+
+// stateDiffRecorder tracks the accounts and slots modified by a transaction,
+// to be diffed between the pre- and post-state afterwards.
+type stateDiffRecorder struct {
+	accounts map[common.Address]map[common.Hash]struct{}
+}
+
+func newStateDiffRecorder() *stateDiffRecorder {
+	return &stateDiffRecorder{accounts: make(map[common.Address]map[common.Hash]struct{})}
+}
+
+func (r *stateDiffRecorder) hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnBalanceChange: func(addr common.Address, prev, cur *big.Int, reason tracing.BalanceChangeReason) {
+			r.touch(addr)
+		},
+		OnNonceChange: func(addr common.Address, prev, cur uint64) {
+			r.touch(addr)
+		},
+		OnCodeChange: func(addr common.Address, prevCodeHash common.Hash, prevCode []byte, codeHash common.Hash, code []byte) {
+			r.touch(addr)
+		},
+		OnStorageChange: func(addr common.Address, slot common.Hash, prev, cur common.Hash) {
+			r.touch(addr)[slot] = struct{}{}
+		},
+	}
+}
+
+func (r *stateDiffRecorder) touch(addr common.Address) map[common.Hash]struct{} {
+	slots, ok := r.accounts[addr]
+	if !ok {
+		slots = make(map[common.Hash]struct{})
+		r.accounts[addr] = slots
+	}
+	return slots
+}
+
+// diff compares the touched accounts between the pre- and post-state.
+func (r *stateDiffRecorder) diff(pre, post *state.StateDB) map[common.Address]*AccountStateDiff {
+	diffs := make(map[common.Address]*AccountStateDiff)
+	for addr, slots := range r.accounts {
+		var (
+			existed = pre.Exist(addr)
+			exists  = post.Exist(addr)
+			d       = &AccountStateDiff{Storage: make(map[common.Hash]*DiffValue)}
+		)
+		switch {
+		case !existed && !exists:
+			continue
+
+		case !existed:
+			d.Balance = &DiffValue{To: (*hexutil.Big)(post.GetBalance(addr).ToBig())}
+			d.Nonce = &DiffValue{To: hexutil.Uint64(post.GetNonce(addr))}
+			d.Code = &DiffValue{To: hexutil.Bytes(post.GetCode(addr))}
+			for slot := range slots {
+				if val := post.GetState(addr, slot); val != (common.Hash{}) {
+					d.Storage[slot] = &DiffValue{To: val}
+				}
+			}
+
+		case !exists:
+			d.Balance = &DiffValue{From: (*hexutil.Big)(pre.GetBalance(addr).ToBig())}
+			d.Nonce = &DiffValue{From: hexutil.Uint64(pre.GetNonce(addr))}
+			d.Code = &DiffValue{From: hexutil.Bytes(pre.GetCode(addr))}
+			for slot := range slots {
+				if val := pre.GetState(addr, slot); val != (common.Hash{}) {
+					d.Storage[slot] = &DiffValue{From: val}
+				}
+			}
+
+		default:
+			var changed bool
+			d.Balance, d.Nonce, d.Code = new(DiffValue), new(DiffValue), new(DiffValue)
+			if from, to := pre.GetBalance(addr), post.GetBalance(addr); !from.Eq(to) {
+				d.Balance = &DiffValue{From: (*hexutil.Big)(from.ToBig()), To: (*hexutil.Big)(to.ToBig())}
+				changed = true
+			}
+			if from, to := pre.GetNonce(addr), post.GetNonce(addr); from != to {
+				d.Nonce = &DiffValue{From: hexutil.Uint64(from), To: hexutil.Uint64(to)}
+				changed = true
+			}
+			if from, to := pre.GetCode(addr), post.GetCode(addr); !bytes.Equal(from, to) {
+				d.Code = &DiffValue{From: hexutil.Bytes(from), To: hexutil.Bytes(to)}
+				changed = true
+			}
+			for slot := range slots {
+				if from, to := pre.GetState(addr, slot), post.GetState(addr, slot); from != to {
+					d.Storage[slot] = &DiffValue{From: from, To: to}
+					changed = true
+				}
+			}
+			if !changed {
+				continue
+			}
+		}
+		diffs[addr] = d
+	}
+	return diffs
+}
+
+// VMTrace is the OpenEthereum virtual machine trace of a single call frame.
+type VMTrace struct {
+	Code hexutil.Bytes `json:"code"`
+	Ops  []*VMTraceOp  `json:"ops"`
+}
+
+// VMTraceOp is a single executed instruction. Sub holds the trace of the
+// call frame opened by the instruction, if any.
+type VMTraceOp struct {
+	Cost uint64     `json:"cost"`
+	Ex   *VMTraceEx `json:"ex"`
+	Pc   uint64     `json:"pc"`
+	Sub  *VMTrace   `json:"sub"`
+}
+
+// VMTraceEx holds the effects of an executed instruction. It is missing if
+// the instruction failed.
+type VMTraceEx struct {
+	Mem   *VMTraceMem     `json:"mem"`
+	Push  []*hexutil.U256 `json:"push"`
+	Store *VMTraceStore   `json:"store"`
+	Used  uint64          `json:"used"`
+}
+
+// VMTraceMem is the memory region written by an instruction.
+type VMTraceMem struct {
+	Data hexutil.Bytes `json:"data"`
+	Off  uint64        `json:"off"`
+}
+
+// VMTraceStore is the storage slot written by an instruction.
+type VMTraceStore struct {
+	Key *hexutil.U256 `json:"key"`
+	Val *hexutil.U256 `json:"val"`
+}
+
+// vmTraceStackOps is the instruction set used to look up the number of stack
+// items consumed and produced by an opcode, which is fork independent.
+var vmTraceStackOps, _ = vm.LookupInstructionSet(params.Rules{
+	IsHomestead: true, IsEIP150: true, IsEIP155: true, IsEIP158: true,
+	IsByzantium: true, IsConstantinople: true, IsPetersburg: true, IsIstanbul: true,
+	IsBerlin: true, IsLondon: true, IsMerge: true, IsShanghai: true, IsCancun: true,
+})
+
+// vmTraceFrame is a call frame being recorded.
+type vmTraceFrame struct {
+	trace   *VMTrace
+	attach  bool       // Whether the frame is a real call frame (not a selfdestruct)
+	pending *VMTraceOp // Last instruction, waiting for its effects
+	op      vm.OpCode
+	memOff  uint64 // Memory region written by the pending instruction
+	memSize uint64
+}
+
+// vmTraceRecorder builds the OpenEthereum virtual machine trace.
+type vmTraceRecorder struct {
+	env    *tracing.VMContext
+	root   *VMTrace
+	frames []*vmTraceFrame
+}
+
+func (r *vmTraceRecorder) hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart: r.onTxStart,
+		OnEnter:   r.onEnter,
+		OnExit:    r.onExit,
+		OnOpcode:  r.onOpcode,
+		OnFault:   r.onFault,
+	}
+}
+
+func (r *vmTraceRecorder) onTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	r.env = env
+}
+
+func (r *vmTraceRecorder) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	frame := &vmTraceFrame{trace: &VMTrace{Ops: []*VMTraceOp{}}, attach: vm.OpCode(typ) != vm.SELFDESTRUCT}
+	switch vm.OpCode(typ) {
+	case vm.CREATE, vm.CREATE2:
+		frame.trace.Code = common.CopyBytes(input)
+	default:
+		frame.trace.Code = r.env.StateDB.GetCode(to)
+	}
+	if len(r.frames) == 0 {
+		r.root = frame.trace
+	} else if parent := r.frames[len(r.frames)-1]; frame.attach && parent.pending != nil {
+		parent.pending.Sub = frame.trace
+	}
+	r.frames = append(r.frames, frame)
+}
+
+func (r *vmTraceRecorder) onExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if len(r.frames) == 0 {
+		return
+	}
+	frame := r.frames[len(r.frames)-1]
+	r.frames = r.frames[:len(r.frames)-1]
+
+	// The terminating instruction has no trailing one to collect its effects
+	if op := frame.pending; op != nil {
+		if err != nil && !errors.Is(err, vm.ErrExecutionReverted) {
+			op.Ex = nil
+		} else {
+			op.Ex.Used -= op.Cost
+		}
+		frame.pending = nil
+	}
+}
+
+func (r *vmTraceRecorder) onOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	if len(r.frames) == 0 {
+		return
+	}
+	frame := r.frames[len(r.frames)-1]
+	r.complete(frame, gas, scope)
+
+	traced := &VMTraceOp{Cost: cost, Pc: pc}
+	frame.trace.Ops = append(frame.trace.Ops, traced)
+	if err != nil {
+		// The instruction failed before executing, no effects
+		return
+	}
+	traced.Ex = &VMTraceEx{Push: []*hexutil.U256{}, Used: gas}
+	frame.pending, frame.op = traced, vm.OpCode(op)
+
+	// Record the memory and storage written by the instruction
+	var (
+		stack = scope.StackData()
+		arg   = func(n int) *uint256.Int { return &stack[len(stack)-1-n] }
+	)
+	frame.memOff, frame.memSize = 0, 0
+	switch vm.OpCode(op) {
+	case vm.MSTORE:
+		frame.memOff, frame.memSize = arg(0).Uint64(), 32
+	case vm.MSTORE8:
+		frame.memOff, frame.memSize = arg(0).Uint64(), 1
+	case vm.CALLDATACOPY, vm.CODECOPY, vm.RETURNDATACOPY, vm.MCOPY:
+		frame.memOff, frame.memSize = arg(0).Uint64(), arg(2).Uint64()
+	case vm.EXTCODECOPY:
+		frame.memOff, frame.memSize = arg(1).Uint64(), arg(3).Uint64()
+	case vm.CALL, vm.CALLCODE:
+		frame.memOff, frame.memSize = arg(5).Uint64(), arg(6).Uint64()
+	case vm.DELEGATECALL, vm.STATICCALL:
+		frame.memOff, frame.memSize = arg(4).Uint64(), arg(5).Uint64()
+	case vm.SSTORE:
+		key, val := hexutil.U256(*arg(0)), hexutil.U256(*arg(1))
+		traced.Ex.Store = &VMTraceStore{Key: &key, Val: &val}
+	}
+}
+
+func (r *vmTraceRecorder) onFault(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, depth int, err error) {
+	if len(r.frames) == 0 {
+		return
+	}
+	frame := r.frames[len(r.frames)-1]
+	if frame.pending != nil {
+		frame.pending.Ex = nil
+		frame.pending = nil
+	}
+}
+
+// complete fills in the effects of the pending instruction of the frame,
+// using the state before executing the next one.
+func (r *vmTraceRecorder) complete(frame *vmTraceFrame, gas uint64, scope tracing.OpContext) {
+	traced := frame.pending
+	if traced == nil {
+		return
+	}
+	frame.pending = nil
+	traced.Ex.Used = gas
+
+	// Collect the stack items produced by the instruction
+	var (
+		stack = scope.StackData()
+		push  int
+	)
+	switch op := frame.op; {
+	case op >= vm.DUP1 && op <= vm.DUP16:
+		push = int(op-vm.DUP1) + 2
+	case op >= vm.SWAP1 && op <= vm.SWAP16:
+		push = int(op-vm.SWAP1) + 2
+	default:
+		if def := vmTraceStackOps[op]; def != nil {
+			pops, max := def.Stack()
+			push = int(params.StackLimit) + pops - max
+		}
+	}
+	if push > len(stack) {
+		push = len(stack)
+	}
+	for i := len(stack) - push; i < len(stack); i++ {
+		item := hexutil.U256(stack[i])
+		traced.Ex.Push = append(traced.Ex.Push, &item)
+	}
+	// Collect the memory written by the instruction
+	if frame.memSize > 0 {
+		mem := scope.MemoryData()
+		if end := frame.memOff + frame.memSize; end >= frame.memOff && end <= uint64(len(mem)) {
+			traced.Ex.Mem = &VMTraceMem{Off: frame.memOff, Data: common.CopyBytes(mem[frame.memOff:end])}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// defaultPrivateTxBlocks is the number of blocks a private transaction is kept
+// private for if no maximum block is given.
+const defaultPrivateTxBlocks = 25
+
+// PrivateTxBackend is implemented by the backends able to pool transactions
+// without propagating them to the network.
+type PrivateTxBackend interface {
+	// SendPrivateTx pools the transaction for the local block producer only,
+	// until the chain reaches maxBlock. The transaction is then dropped, or
+	// released to the network if release is set.
+	SendPrivateTx(ctx context.Context, tx *types.Transaction, maxBlock uint64, release bool) error
+}
+
+// ConditionalTxBackend is implemented by the backends able to pool transactions
+// constrained by a conditional.
+type ConditionalTxBackend interface {
+	// SendConditionalTx pools the transaction to only be included in blocks
+	// satisfying the conditional.
+	SendConditionalTx(ctx context.Context, tx *types.Transaction, cond *txpool.TransactionConditional) error
+}
+
+// ReplacementBackend is implemented by the backends able to simulate the
+// replacement of pooled transactions.
+type ReplacementBackend interface {
+	SimulateReplacement(tx *types.Transaction) (*txpool.ReplacementResult, error)
+}
+
+// PrivateTxArgs represents the options of eth_sendPrivateRawTransaction.
+type PrivateTxArgs struct {
+	MaxBlockNumber *hexutil.Uint64 `json:"maxBlockNumber,omitempty"` // Last block to keep the transaction private for
+	Release        bool            `json:"release,omitempty"`        // Gossip the transaction after the max block instead of dropping it
+}
+
+// SendPrivateRawTransaction adds the signed transaction to the transaction pool
+// without propagating it to the network, so it's only included by the local
+// block producer. Once the chain reaches the max block, which defaults to a few
+// blocks ahead, the transaction is dropped or optionally released to the network.
+func (s *TransactionAPI) SendPrivateRawTransaction(ctx context.Context, input hexutil.Bytes, args *PrivateTxArgs) (common.Hash, error) {
+	backend, ok := s.b.(PrivateTxBackend)
+	if !ok {
+		return common.Hash{}, errors.New("private transactions not supported")
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if err := checkTxFee(tx.GasPrice(), tx.Gas(), s.b.RPCTxFeeCap()); err != nil {
+		return common.Hash{}, err
+	}
+	if !s.b.UnprotectedAllowed() && !tx.Protected() {
+		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
+	}
+	if args == nil {
+		args = new(PrivateTxArgs)
+	}
+	head := s.b.CurrentBlock()
+	maxBlock := head.Number.Uint64() + defaultPrivateTxBlocks
+	if args.MaxBlockNumber != nil {
+		maxBlock = uint64(*args.MaxBlockNumber)
+	}
+	if err := backend.SendPrivateTx(ctx, tx, maxBlock, args.Release); err != nil {
+		return common.Hash{}, err
+	}
+	signer := types.MakeSigner(s.b.ChainConfig(), head.Number, head.Time)
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted private transaction", "hash", tx.Hash().Hex(), "from", from, "nonce", tx.Nonce(), "maxblock", maxBlock, "release", args.Release)
+	return tx.Hash(), nil
+}
+
+// SendRawTransactionConditional adds the signed transaction to the transaction
+// pool, to only be included in a block within the conditional's block number and
+// timestamp ranges, on top of a state matching its known accounts. The pool keeps
+// the transaction while the conditional can still hold.
+func (s *TransactionAPI) SendRawTransactionConditional(ctx context.Context, input hexutil.Bytes, cond txpool.TransactionConditional) (common.Hash, error) {
+	backend, ok := s.b.(ConditionalTxBackend)
+	if !ok {
+		return common.Hash{}, errors.New("conditional transactions not supported")
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if err := checkTxFee(tx.GasPrice(), tx.Gas(), s.b.RPCTxFeeCap()); err != nil {
+		return common.Hash{}, err
+	}
+	if !s.b.UnprotectedAllowed() && !tx.Protected() {
+		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
+	}
+	if err := cond.Validate(); err != nil {
+		return common.Hash{}, err
+	}
+	// Reject the transaction upfront if its conditional doesn't hold already
+	statedb, head, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if cond.Expired(head) {
+		return common.Hash{}, &txpool.ConditionalError{Reason: "conditional expired"}
+	}
+	if err := cond.CheckState(statedb); err != nil {
+		return common.Hash{}, err
+	}
+	if err := backend.SendConditionalTx(ctx, tx, &cond); err != nil {
+		return common.Hash{}, err
+	}
+	signer := types.MakeSigner(s.b.ChainConfig(), head.Number, head.Time)
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted conditional transaction", "hash", tx.Hash().Hex(), "from", from, "nonce", tx.Nonce(), "accounts", len(cond.KnownAccounts))
+	return tx.Hash(), nil
+}
+
+// SimulateReplacement reports whether the signed transaction would replace the
+// pooled transaction with the same sender and nonce, along with the minimum fees
+// required to, without submitting it.
+func (s *TxPoolAPI) SimulateReplacement(input hexutil.Bytes) (*txpool.ReplacementResult, error) {
+	backend, ok := s.b.(ReplacementBackend)
+	if !ok {
+		return nil, errors.New("replacement simulation not supported")
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return nil, err
+	}
+	return backend.SimulateReplacement(tx)
+}
@@ -0,0 +1,420 @@
+package ethapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxBundleTxs is the maximum number of transactions a single bundle may carry.
+const maxBundleTxs = 256
+
+// CallBundleTx is a single bundle entry. It is either a signed, RLP encoded
+// transaction given as a hex string, or an unsigned call object which is
+// executed without signature and nonce checks, the same way as eth_call.
+type CallBundleTx struct {
+	Signed   *types.Transaction
+	Unsigned *TransactionArgs
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *CallBundleTx) UnmarshalJSON(input []byte) error {
+	if len(input) > 0 && input[0] == '"' {
+		var raw hexutil.Bytes
+		if err := json.Unmarshal(input, &raw); err != nil {
+			return err
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return err
+		}
+		t.Signed, t.Unsigned = tx, nil
+		return nil
+	}
+	args := new(TransactionArgs)
+	if err := json.Unmarshal(input, args); err != nil {
+		return err
+	}
+	t.Signed, t.Unsigned = nil, args
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t CallBundleTx) MarshalJSON() ([]byte, error) {
+	if t.Signed != nil {
+		raw, err := t.Signed.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(hexutil.Bytes(raw))
+	}
+	return json.Marshal(t.Unsigned)
+}
+
+// CallBundleArgs are the arguments of eth_callBundle.
+type CallBundleArgs struct {
+	Txs            []CallBundleTx         `json:"txs"`
+	StateBlock     *rpc.BlockNumberOrHash `json:"stateBlockNumber"`
+	StateOverrides *StateOverride         `json:"stateOverrides"`
+	BlockOverrides *BlockOverrides        `json:"blockOverrides"`
+}
+
+// CallBundleTxResult is the outcome of a single bundle transaction.
+type CallBundleTxResult struct {
+	TxHash       common.Hash     `json:"txHash"`
+	From         common.Address  `json:"from"`
+	To           *common.Address `json:"to"`
+	GasUsed      hexutil.Uint64  `json:"gasUsed"`
+	Status       hexutil.Uint64  `json:"status"`
+	ReturnData   hexutil.Bytes   `json:"returnData"`
+	Logs         []*types.Log    `json:"logs"`
+	Error        string          `json:"error,omitempty"`
+	RevertReason string          `json:"revertReason,omitempty"`
+	CoinbaseDiff *hexutil.Big    `json:"coinbaseDiff"`
+}
+
+// BalanceDiff is the change of an account balance.
+type BalanceDiff struct {
+	From *hexutil.Big `json:"from"`
+	To   *hexutil.Big `json:"to"`
+}
+
+// NonceDiff is the change of an account nonce.
+type NonceDiff struct {
+	From hexutil.Uint64 `json:"from"`
+	To   hexutil.Uint64 `json:"to"`
+}
+
+// CodeDiff is the change of an account code.
+type CodeDiff struct {
+	From hexutil.Bytes `json:"from"`
+	To   hexutil.Bytes `json:"to"`
+}
+
+// StorageDiff is the change of a single storage slot.
+type StorageDiff struct {
+	From common.Hash `json:"from"`
+	To   common.Hash `json:"to"`
+}
+
+// AccountDiff holds the modified fields of an account. Unmodified fields
+// are omitted.
+type AccountDiff struct {
+	Balance *BalanceDiff                 `json:"balance,omitempty"`
+	Nonce   *NonceDiff                   `json:"nonce,omitempty"`
+	Code    *CodeDiff                    `json:"code,omitempty"`
+	Storage map[common.Hash]*StorageDiff `json:"storage,omitempty"`
+}
+
+// CallBundleResult is the outcome of eth_callBundle.
+type CallBundleResult struct {
+	StateBlockNumber hexutil.Uint64                  `json:"stateBlockNumber"`
+	StateBlockHash   common.Hash                     `json:"stateBlockHash"`
+	BlockNumber      hexutil.Uint64                  `json:"blockNumber"`
+	Coinbase         common.Address                  `json:"coinbase"`
+	GasUsed          hexutil.Uint64                  `json:"gasUsed"`
+	CoinbaseDiff     *hexutil.Big                    `json:"coinbaseDiff"`
+	Results          []*CallBundleTxResult           `json:"results"`
+	StateDiff        map[common.Address]*AccountDiff `json:"stateDiff"`
+}
+
+// bundleRecorder tracks the accounts and storage slots touched during the
+// bundle execution, as well as the output of the outermost call frame.
+type bundleRecorder struct {
+	accounts map[common.Address]map[common.Hash]struct{}
+	output   []byte
+}
+
+func newBundleRecorder() *bundleRecorder {
+	return &bundleRecorder{accounts: make(map[common.Address]map[common.Hash]struct{})}
+}
+
+func (r *bundleRecorder) hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart: func(*tracing.VMContext, *types.Transaction, common.Address) {
+			r.output = nil
+		},
+		OnExit: func(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+			if depth == 0 {
+				r.output = common.CopyBytes(output)
+			}
+		},
+		OnBalanceChange: func(addr common.Address, prev, new *big.Int, reason tracing.BalanceChangeReason) {
+			r.touch(addr)
+		},
+		OnNonceChange: func(addr common.Address, prev, new uint64) {
+			r.touch(addr)
+		},
+		OnCodeChange: func(addr common.Address, prevCodeHash common.Hash, prevCode []byte, codeHash common.Hash, code []byte) {
+			r.touch(addr)
+		},
+		OnStorageChange: func(addr common.Address, slot common.Hash, prev, new common.Hash) {
+			r.touch(addr)[slot] = struct{}{}
+		},
+	}
+}
+
+func (r *bundleRecorder) touch(addr common.Address) map[common.Hash]struct{} {
+	slots, ok := r.accounts[addr]
+	if !ok {
+		slots = make(map[common.Hash]struct{})
+		r.accounts[addr] = slots
+	}
+	return slots
+}
+
+// diff compares the touched accounts between the state before and after the
+// bundle. Changes reverted within the bundle are not reported.
+func (r *bundleRecorder) diff(pre, post *state.StateDB) map[common.Address]*AccountDiff {
+	diffs := make(map[common.Address]*AccountDiff)
+	for addr, slots := range r.accounts {
+		var (
+			d       = new(AccountDiff)
+			changed bool
+		)
+		if from, to := pre.GetBalance(addr), post.GetBalance(addr); !from.Eq(to) {
+			d.Balance = &BalanceDiff{From: (*hexutil.Big)(from.ToBig()), To: (*hexutil.Big)(to.ToBig())}
+			changed = true
+		}
+		if from, to := pre.GetNonce(addr), post.GetNonce(addr); from != to {
+			d.Nonce = &NonceDiff{From: hexutil.Uint64(from), To: hexutil.Uint64(to)}
+			changed = true
+		}
+		if from, to := pre.GetCode(addr), post.GetCode(addr); !bytes.Equal(from, to) {
+			d.Code = &CodeDiff{From: from, To: to}
+			changed = true
+		}
+		for slot := range slots {
+			if from, to := pre.GetState(addr, slot), post.GetState(addr, slot); from != to {
+				if d.Storage == nil {
+					d.Storage = make(map[common.Hash]*StorageDiff)
+				}
+				d.Storage[slot] = &StorageDiff{From: from, To: to}
+				changed = true
+			}
+		}
+		if changed {
+			diffs[addr] = d
+		}
+	}
+	return diffs
+}
+
+// CallBundle executes an ordered list of transactions on top of the given
+// block, as if they were included in the next block. The block environment
+// can be adjusted with block overrides and the parent state with state
+// overrides. Nothing is persisted.
+//
+// A transaction failing with a consensus error (e.g. invalid nonce or
+// insufficient funds) aborts the whole bundle, while reverted executions are
+// reported in the per-transaction results.
+func (s *BlockChainAPI) CallBundle(ctx context.Context, args CallBundleArgs) (*CallBundleResult, error) {
+	if len(args.Txs) == 0 {
+		return nil, errors.New("bundle missing transactions")
+	}
+	if len(args.Txs) > maxBundleTxs {
+		return nil, fmt.Errorf("bundle too large: have %d, max %d", len(args.Txs), maxBundleTxs)
+	}
+	defer func(start time.Time) { log.Debug("Executing bundle call finished", "runtime", time.Since(start)) }(time.Now())
+
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if args.StateBlock != nil {
+		bNrOrHash = *args.StateBlock
+	}
+	statedb, parent, err := s.b.StateAndHeaderByNumberOrHash(ctx, bNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	if err := args.StateOverrides.Apply(statedb); err != nil {
+		return nil, err
+	}
+	timeout := s.b.RPCEVMTimeout()
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	var (
+		config   = s.b.ChainConfig()
+		header   = args.BlockOverrides.MakeHeader(bundleHeader(config, parent))
+		blockCtx = core.NewEVMBlockContext(header, NewChainContext(ctx, s.b), nil)
+	)
+	args.BlockOverrides.Apply(&blockCtx)
+
+	// Abort the transaction in flight once the context is done
+	var current atomic.Pointer[vm.EVM]
+	go func() {
+		<-ctx.Done()
+		if evm := current.Load(); evm != nil {
+			evm.Cancel()
+		}
+	}()
+	var (
+		recorder = newBundleRecorder()
+		hooks    = recorder.hooks()
+		pre      = statedb.Copy()
+		signer   = types.MakeSigner(config, blockCtx.BlockNumber, blockCtx.Time)
+		gp       = new(core.GasPool).AddGas(blockCtx.GasLimit)
+		gasCap   = s.b.RPCGasCap()
+		usedGas  uint64
+		results  = make([]*CallBundleTxResult, 0, len(args.Txs))
+		vmConfig = vm.Config{NoBaseFee: true, Tracer: hooks}
+	)
+	statedb.SetLogger(hooks)
+	for i, btx := range args.Txs {
+		var (
+			msg *core.Message
+			tx  *types.Transaction
+		)
+		switch {
+		case btx.Signed != nil:
+			tx = btx.Signed
+			if msg, err = core.TransactionToMessage(tx, signer, blockCtx.BaseFee); err != nil {
+				return nil, fmt.Errorf("tx %d: %w", i, err)
+			}
+		case btx.Unsigned != nil:
+			call := *btx.Unsigned
+			if call.Nonce == nil {
+				nonce := hexutil.Uint64(statedb.GetNonce(call.from()))
+				call.Nonce = &nonce
+			}
+			// Unsigned calls may not exceed the gas left in the block
+			callCap := gp.Gas()
+			if gasCap != 0 && gasCap < callCap {
+				callCap = gasCap
+			}
+			if err := call.CallDefaults(callCap, blockCtx.BaseFee, config.ChainID); err != nil {
+				return nil, fmt.Errorf("tx %d: %w", i, err)
+			}
+			msg, tx = call.ToMessage(blockCtx.BaseFee), call.ToTransaction()
+		default:
+			return nil, fmt.Errorf("tx %d: empty bundle transaction", i)
+		}
+		evm := s.b.GetEVM(ctx, msg, statedb, header, &vmConfig, &blockCtx)
+		current.Store(evm)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		}
+		coinbaseBefore := statedb.GetBalance(blockCtx.Coinbase).ToBig()
+
+		statedb.SetTxContext(tx.Hash(), i)
+		receipt, err := core.ApplyTransactionWithEVM(msg, config, gp, statedb, blockCtx.BlockNumber, header.Hash(), tx, &usedGas, evm)
+		if evm.Cancelled() {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tx %d [%v]: %w", i, tx.Hash(), err)
+		}
+		if err := statedb.Error(); err != nil {
+			return nil, err
+		}
+		res := &CallBundleTxResult{
+			TxHash:       tx.Hash(),
+			From:         msg.From,
+			To:           msg.To,
+			GasUsed:      hexutil.Uint64(receipt.GasUsed),
+			Status:       hexutil.Uint64(receipt.Status),
+			ReturnData:   recorder.output,
+			Logs:         receipt.Logs,
+			CoinbaseDiff: (*hexutil.Big)(new(big.Int).Sub(statedb.GetBalance(blockCtx.Coinbase).ToBig(), coinbaseBefore)),
+		}
+		if res.Logs == nil {
+			res.Logs = []*types.Log{}
+		}
+		if receipt.Status == types.ReceiptStatusFailed {
+			res.Error = vm.ErrExecutionReverted.Error()
+			if len(recorder.output) > 0 {
+				res.Error = newRevertError(recorder.output).Error()
+				if reason, err := abi.UnpackRevert(recorder.output); err == nil {
+					res.RevertReason = reason
+				}
+			}
+		}
+		results = append(results, res)
+	}
+	return &CallBundleResult{
+		StateBlockNumber: hexutil.Uint64(parent.Number.Uint64()),
+		StateBlockHash:   parent.Hash(),
+		BlockNumber:      hexutil.Uint64(blockCtx.BlockNumber.Uint64()),
+		Coinbase:         blockCtx.Coinbase,
+		GasUsed:          hexutil.Uint64(usedGas),
+		CoinbaseDiff:     (*hexutil.Big)(new(big.Int).Sub(statedb.GetBalance(blockCtx.Coinbase).ToBig(), pre.GetBalance(blockCtx.Coinbase).ToBig())),
+		Results:          results,
+		StateDiff:        recorder.diff(pre, statedb),
+	}, nil
+}
+
+// MakeHeader returns a copy of the given header with the overrides applied. The
+// blob base fee has no header field and is only applied to the block context.
+func (diff *BlockOverrides) MakeHeader(header *types.Header) *types.Header {
+	if diff == nil {
+		return header
+	}
+	header = types.CopyHeader(header)
+	if diff.Number != nil {
+		header.Number = diff.Number.ToInt()
+	}
+	if diff.Difficulty != nil {
+		header.Difficulty = diff.Difficulty.ToInt()
+	}
+	if diff.Time != nil {
+		header.Time = uint64(*diff.Time)
+	}
+	if diff.GasLimit != nil {
+		header.GasLimit = uint64(*diff.GasLimit)
+	}
+	if diff.Coinbase != nil {
+		header.Coinbase = *diff.Coinbase
+	}
+	if diff.Random != nil {
+		header.MixDigest = *diff.Random
+	}
+	if diff.BaseFee != nil {
+		header.BaseFee = diff.BaseFee.ToInt()
+	}
+	return header
+}
+
+// bundleHeader assembles the header of the block a bundle is simulated in,
+// building on top of the given parent.
+func bundleHeader(config *params.ChainConfig, parent *types.Header) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Coinbase:   parent.Coinbase,
+		Difficulty: parent.Difficulty,
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + 1,
+		MixDigest:  parent.MixDigest,
+	}
+	if config.IsLondon(header.Number) {
+		header.BaseFee = eip1559.CalcBaseFee(config, parent)
+	}
+	if parent.ExcessBlobGas != nil && parent.BlobGasUsed != nil {
+		excess := eip4844.CalcExcessBlobGas(*parent.ExcessBlobGas, *parent.BlobGasUsed)
+		header.ExcessBlobGas = &excess
+	}
+	return header
+}
@@ -0,0 +1,157 @@
+package ethapi
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// bundleTestCode returns contract code which sets slot 0 to 1 and emits an
+// empty log when called without calldata, and reverts with "bad" otherwise.
+func bundleTestCode() []byte {
+	revert := append([]byte{0x08, 0xc3, 0x79, 0xa0}, common.LeftPadBytes([]byte{0x20}, 32)...)
+	revert = append(revert, common.LeftPadBytes([]byte{3}, 32)...)
+	revert = append(revert, common.RightPadBytes([]byte("bad"), 32)...)
+	revert = common.RightPadBytes(revert, 128)
+
+	code := []byte{
+		byte(vm.CALLDATASIZE), byte(vm.PUSH1), 15, byte(vm.JUMPI),
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG0),
+		byte(vm.STOP),
+		byte(vm.JUMPDEST),
+	}
+	for i := 0; i < 4; i++ {
+		code = append(code, byte(vm.PUSH32))
+		code = append(code, revert[i*32:(i+1)*32]...)
+		code = append(code, byte(vm.PUSH1), byte(i*32), byte(vm.MSTORE))
+	}
+	return append(code, byte(vm.PUSH1), 100, byte(vm.PUSH1), 0, byte(vm.REVERT))
+}
+
+func TestCallBundle(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		contract = common.HexToAddress("0xc0de")
+		coinbase = common.HexToAddress("0xc014ba5e")
+		genesis  = &core.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		genBlocks = 5
+		signer    = types.LatestSigner(params.MergedTestChainConfig)
+		tip       = big.NewInt(params.GWei)
+	)
+	api := NewBlockChainAPI(newTestBackend(t, genBlocks, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {
+		b.SetPoS()
+	}))
+	sign := func(nonce uint64) CallBundleTx {
+		tx, err := types.SignNewTx(accounts[0].key, signer, &types.DynamicFeeTx{
+			ChainID:   params.MergedTestChainConfig.ChainID,
+			Nonce:     nonce,
+			To:        &accounts[1].addr,
+			Value:     big.NewInt(1000),
+			Gas:       params.TxGas,
+			GasTipCap: tip,
+			GasFeeCap: big.NewInt(100 * params.GWei),
+		})
+		if err != nil {
+			t.Fatalf("failed to sign tx: %v", err)
+		}
+		return CallBundleTx{Signed: tx}
+	}
+	code := hexutil.Bytes(bundleTestCode())
+	args := CallBundleArgs{
+		Txs: []CallBundleTx{
+			sign(0),
+			{Unsigned: &TransactionArgs{From: &accounts[1].addr, To: &contract}},
+			{Unsigned: &TransactionArgs{From: &accounts[1].addr, To: &contract, Input: &hexutil.Bytes{0x01}}},
+		},
+		StateOverrides: &StateOverride{contract: OverrideAccount{Code: &code}},
+		BlockOverrides: &BlockOverrides{Coinbase: &coinbase},
+	}
+	// Round trip through JSON to exercise the mixed transaction encoding
+	blob, err := json.Marshal(args)
+	if err != nil {
+		t.Fatalf("failed to encode bundle: %v", err)
+	}
+	var decoded CallBundleArgs
+	if err := json.Unmarshal(blob, &decoded); err != nil {
+		t.Fatalf("failed to decode bundle: %v", err)
+	}
+	res, err := api.CallBundle(context.Background(), decoded)
+	if err != nil {
+		t.Fatalf("bundle call failed: %v", err)
+	}
+	if uint64(res.BlockNumber) != uint64(genBlocks+1) || res.Coinbase != coinbase {
+		t.Fatalf("block environment mismatch: number %d, coinbase %x", res.BlockNumber, res.Coinbase)
+	}
+	if len(res.Results) != 3 {
+		t.Fatalf("result count mismatch: have %d, want 3", len(res.Results))
+	}
+	transfer, call, reverted := res.Results[0], res.Results[1], res.Results[2]
+	if transfer.TxHash != decoded.Txs[0].Signed.Hash() || uint64(transfer.Status) != types.ReceiptStatusSuccessful {
+		t.Errorf("transfer result mismatch: %+v", transfer)
+	}
+	if uint64(transfer.GasUsed) != params.TxGas {
+		t.Errorf("transfer gas mismatch: have %d, want %d", transfer.GasUsed, params.TxGas)
+	}
+	fee := new(big.Int).Mul(tip, big.NewInt(int64(params.TxGas)))
+	if transfer.CoinbaseDiff.ToInt().Cmp(fee) != 0 || res.CoinbaseDiff.ToInt().Cmp(fee) != 0 {
+		t.Errorf("coinbase diff mismatch: tx %v, bundle %v, want %v", transfer.CoinbaseDiff, res.CoinbaseDiff, fee)
+	}
+	if uint64(call.Status) != types.ReceiptStatusSuccessful || len(call.Logs) != 1 || call.Logs[0].Address != contract {
+		t.Errorf("call result mismatch: %+v", call)
+	}
+	if uint64(reverted.Status) != types.ReceiptStatusFailed || reverted.RevertReason != "bad" {
+		t.Errorf("revert result mismatch: status %d, reason %q, error %q", reverted.Status, reverted.RevertReason, reverted.Error)
+	}
+	if uint64(res.GasUsed) != uint64(transfer.GasUsed+call.GasUsed+reverted.GasUsed) {
+		t.Errorf("bundle gas mismatch: have %d", res.GasUsed)
+	}
+	// Check the resulting state diff
+	sender := res.StateDiff[accounts[0].addr]
+	if sender == nil || sender.Nonce == nil || sender.Nonce.From != 0 || sender.Nonce.To != 1 {
+		t.Errorf("sender diff mismatch: %+v", sender)
+	}
+	recipient := res.StateDiff[accounts[1].addr]
+	if recipient == nil || recipient.Balance == nil || recipient.Balance.To.ToInt().Int64() != 1000 {
+		t.Errorf("recipient diff mismatch: %+v", recipient)
+	}
+	slot := res.StateDiff[contract]
+	if slot == nil || slot.Code != nil || len(slot.Storage) != 1 || slot.Storage[common.Hash{}].To != common.BigToHash(common.Big1) {
+		t.Errorf("contract diff mismatch: %+v", slot)
+	}
+	// The block overrides must also apply to the block the logs are placed in
+	number := hexutil.Big(*big.NewInt(100))
+	args.Txs = []CallBundleTx{args.Txs[1]}
+	args.BlockOverrides = &BlockOverrides{Number: &number, Coinbase: &coinbase}
+	if res, err = api.CallBundle(context.Background(), args); err != nil {
+		t.Fatalf("overridden bundle call failed: %v", err)
+	}
+	head, _ := api.b.HeaderByNumber(context.Background(), rpc.LatestBlockNumber)
+	header := args.BlockOverrides.MakeHeader(bundleHeader(params.MergedTestChainConfig, head))
+	if logs := res.Results[0].Logs; len(logs) != 1 || logs[0].BlockNumber != 100 || logs[0].BlockHash != header.Hash() {
+		t.Errorf("overridden log mismatch: %+v, want block #100 %x", logs, header.Hash())
+	}
+	// A consensus failure aborts the whole bundle
+	args.Txs = []CallBundleTx{sign(0), sign(0)}
+	if _, err := api.CallBundle(context.Background(), args); err == nil {
+		t.Fatal("bundle with a reused nonce succeeded")
+	}
+}
@@ -0,0 +1,320 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// maxBundleTxs is the maximum number of transactions in a single bundle.
+	maxBundleTxs = 64
+
+	// maxPendingBundles is the maximum number of bundles waiting for inclusion.
+	maxPendingBundles = 1024
+
+	// bundleResultsCap is the number of bundle results retained for querying.
+	bundleResultsCap = 4096
+)
+
+// Bundle states reported by BundleResult.
+const (
+	BundlePending  = "pending"  // Waiting for its target block to be built
+	BundleBuilt    = "built"    // Included in the latest payload built for its target block
+	BundleIncluded = "included" // Included in the canonical block at its target height
+	BundleMissed   = "missed"   // Built, but the canonical block at its target height lacks it
+	BundleRejected = "rejected" // Left out of the latest payload built for its target block
+	BundleExpired  = "expired"  // Target block passed without the bundle ever being simulated
+)
+
+var (
+	errEmptyBundle        = errors.New("empty bundle")
+	errBundleTooLarge     = fmt.Errorf("bundle exceeds %d transactions", maxBundleTxs)
+	errBundleBlobTx       = errors.New("blob transactions are not supported in bundles")
+	errBundleTargetPassed = errors.New("bundle target block already passed")
+	errBundlePoolFull     = errors.New("bundle pool full")
+	errBundleKnown        = errors.New("bundle already known")
+)
+
+// Bundle is a list of transactions to be included all together, in order, at
+// the top of a specific block, or not at all.
+type Bundle struct {
+	Txs               types.Transactions // Transactions of the bundle, in execution order
+	BlockNumber       uint64             // Number of the only block the bundle may be included in
+	RevertingTxHashes []common.Hash      // Transactions allowed to revert without invalidating the bundle
+}
+
+// Hash returns the bundle identifier, the hash of its transaction hashes.
+func (b *Bundle) Hash() common.Hash {
+	hashes := make([]byte, 0, len(b.Txs)*common.HashLength)
+	for _, tx := range b.Txs {
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	return crypto.Keccak256Hash(hashes)
+}
+
+// mayRevert returns whether the given bundle transaction is allowed to revert.
+func (b *Bundle) mayRevert(hash common.Hash) bool {
+	for _, allowed := range b.RevertingTxHashes {
+		if allowed == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// BundleResult is the outcome of a submitted bundle.
+type BundleResult struct {
+	Hash        common.Hash    `json:"bundleHash"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Status      string         `json:"status"`
+	Reason      string         `json:"reason,omitempty"`    // Rejection reason, if the bundle was rejected
+	GasUsed     hexutil.Uint64 `json:"gasUsed,omitempty"`   // Gas used by the bundle, if it was built
+	Block       *common.Hash   `json:"blockHash,omitempty"` // Hash of the payload or canonical block including the bundle
+
+	txs []common.Hash // Transactions of the bundle, to look them up in the chain
+}
+
+// bundlePool holds the bundles waiting for their target block, along with the
+// results of the recently processed bundles.
+type bundlePool struct {
+	bundles map[common.Hash]*Bundle                  // Bundles waiting for their target block
+	order   map[common.Hash]uint64                   // Submission sequence of the waiting bundles
+	results lru.BasicLRU[common.Hash, *BundleResult] // Results of the submitted bundles
+	seq     uint64
+	lock    sync.Mutex
+}
+
+func newBundlePool() *bundlePool {
+	return &bundlePool{
+		bundles: make(map[common.Hash]*Bundle),
+		order:   make(map[common.Hash]uint64),
+		results: lru.NewBasicLRU[common.Hash, *BundleResult](bundleResultsCap),
+	}
+}
+
+// add inserts a new bundle targeting a block after the given head.
+func (p *bundlePool) add(bundle *Bundle, head uint64) (common.Hash, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if bundle.BlockNumber <= head {
+		return common.Hash{}, errBundleTargetPassed
+	}
+	p.prune(head + 1)
+
+	hash := bundle.Hash()
+	if _, ok := p.bundles[hash]; ok {
+		return common.Hash{}, errBundleKnown
+	}
+	if len(p.bundles) >= maxPendingBundles {
+		return common.Hash{}, errBundlePoolFull
+	}
+	p.seq++
+	p.bundles[hash] = bundle
+	p.order[hash] = p.seq
+	p.results.Add(hash, &BundleResult{Hash: hash, BlockNumber: hexutil.Uint64(bundle.BlockNumber), Status: BundlePending})
+	return hash, nil
+}
+
+// pending returns the bundles targeting the given block in submission order,
+// dropping the ones whose target block already passed.
+func (p *bundlePool) pending(number uint64) []*Bundle {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.prune(number)
+
+	var hashes []common.Hash
+	for hash, bundle := range p.bundles {
+		if bundle.BlockNumber == number {
+			hashes = append(hashes, hash)
+		}
+	}
+	sort.Slice(hashes, func(i, j int) bool { return p.order[hashes[i]] < p.order[hashes[j]] })
+
+	bundles := make([]*Bundle, len(hashes))
+	for i, hash := range hashes {
+		bundles[i] = p.bundles[hash]
+	}
+	return bundles
+}
+
+// prune drops the bundles targeting blocks before the given one, marking the
+// ones never simulated as expired. The caller must hold the lock.
+func (p *bundlePool) prune(number uint64) {
+	for hash, bundle := range p.bundles {
+		if bundle.BlockNumber >= number {
+			continue
+		}
+		delete(p.bundles, hash)
+		delete(p.order, hash)
+		if result, ok := p.results.Peek(hash); ok && result.Status == BundlePending {
+			result.Status = BundleExpired
+		}
+	}
+}
+
+// trackAll records the outcome of the bundles in the latest payload built for
+// their target block.
+func (p *bundlePool) trackAll(block common.Hash, results []*BundleResult) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, result := range results {
+		if result.Status == BundleBuilt {
+			result.Block = &block
+		}
+		p.results.Add(result.Hash, result)
+	}
+}
+
+// result returns the outcome of a submitted bundle, nil if unknown.
+func (p *bundlePool) result(hash common.Hash) *BundleResult {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	result, ok := p.results.Get(hash)
+	if !ok {
+		return nil
+	}
+	cpy := *result
+	return &cpy
+}
+
+// AddBundle validates a bundle and queues it for inclusion in its target block.
+func (miner *Miner) AddBundle(bundle *Bundle) (common.Hash, error) {
+	if len(bundle.Txs) == 0 {
+		return common.Hash{}, errEmptyBundle
+	}
+	if len(bundle.Txs) > maxBundleTxs {
+		return common.Hash{}, errBundleTooLarge
+	}
+	head := miner.chain.CurrentBlock()
+	signer := types.LatestSigner(miner.chainConfig)
+	for i, tx := range bundle.Txs {
+		if tx.Type() == types.BlobTxType {
+			return common.Hash{}, errBundleBlobTx
+		}
+		if _, err := types.Sender(signer, tx); err != nil {
+			return common.Hash{}, fmt.Errorf("transaction %d (%x): %w", i, tx.Hash(), err)
+		}
+	}
+	return miner.bundles.add(bundle, head.Number.Uint64())
+}
+
+// BundleStatus returns the outcome of a submitted bundle, nil if unknown. Built
+// bundles are reported as included or missed once their target block is known
+// in the canonical chain.
+func (miner *Miner) BundleStatus(hash common.Hash) *BundleResult {
+	result := miner.bundles.result(hash)
+	if result == nil || result.Status != BundleBuilt {
+		return result
+	}
+	block := miner.chain.GetBlockByNumber(uint64(result.BlockNumber))
+	if block == nil {
+		return result
+	}
+	included := make(map[common.Hash]struct{}, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		included[tx.Hash()] = struct{}{}
+	}
+	result.Status = BundleIncluded
+	for _, tx := range result.txs {
+		if _, ok := included[tx]; !ok {
+			result.Status = BundleMissed
+			break
+		}
+	}
+	if result.Status == BundleIncluded {
+		hash := block.Hash()
+		result.Block = &hash
+	}
+	return result
+}
+
+// commitBundles simulates the bundles targeting the block being built on top
+// of the pending state, including each one atomically only if all of its
+// transactions succeed. Reverting transactions are only tolerated if the
+// bundle explicitly allows them.
+func (miner *Miner) commitBundles(env *environment) []*BundleResult {
+	bundles := miner.bundles.pending(env.header.Number.Uint64())
+	if len(bundles) == 0 {
+		return nil
+	}
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	results := make([]*BundleResult, 0, len(bundles))
+	for _, bundle := range bundles {
+		result := &BundleResult{
+			Hash:        bundle.Hash(),
+			BlockNumber: hexutil.Uint64(bundle.BlockNumber),
+			Status:      BundleBuilt,
+		}
+		for _, tx := range bundle.Txs {
+			result.txs = append(result.txs, tx.Hash())
+		}
+		gasUsed, err := miner.commitBundle(env, bundle)
+		if err != nil {
+			log.Debug("Rejected transaction bundle", "hash", result.Hash, "err", err)
+			result.Status, result.Reason = BundleRejected, err.Error()
+		} else {
+			result.GasUsed = hexutil.Uint64(gasUsed)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// commitBundle applies all the transactions of a bundle, rolling the whole
+// environment back if any of them fails. Since the state is finalised after
+// each transaction, the rollback swaps back a copy of the state taken upfront
+// rather than reverting to a journal snapshot.
+func (miner *Miner) commitBundle(env *environment, bundle *Bundle) (uint64, error) {
+	var (
+		state    = env.state.Copy()
+		header   = types.CopyHeader(env.header)
+		gp       = env.gasPool.Gas()
+		tcount   = env.tcount
+		txs      = len(env.txs)
+		receipts = len(env.receipts)
+		sidecars = len(env.sidecars)
+		blobs    = env.blobs
+	)
+	rollback := func() {
+		env.state = state
+		env.header = header
+		env.gasPool.SetGas(gp)
+		env.tcount = tcount
+		env.txs = env.txs[:txs]
+		env.receipts = env.receipts[:receipts]
+		env.sidecars = env.sidecars[:sidecars]
+		env.blobs = blobs
+	}
+	for i, tx := range bundle.Txs {
+		if tx.Protected() && !miner.chainConfig.IsEIP155(env.header.Number) {
+			rollback()
+			return 0, fmt.Errorf("transaction %d (%x): replay protected before EIP-155", i, tx.Hash())
+		}
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		if err := miner.commitTransaction(env, tx); err != nil {
+			rollback()
+			return 0, fmt.Errorf("transaction %d (%x): %w", i, tx.Hash(), err)
+		}
+		if receipt := env.receipts[len(env.receipts)-1]; receipt.Status == types.ReceiptStatusFailed && !bundle.mayRevert(tx.Hash()) {
+			rollback()
+			return 0, fmt.Errorf("transaction %d (%x): execution reverted", i, tx.Hash())
+		}
+	}
+	return env.header.GasUsed - header.GasUsed, nil
+}
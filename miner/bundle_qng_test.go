@@ -0,0 +1,135 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestBundles(t *testing.T) {
+	w, b := newTestWorker(t, params.TestChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+
+	signer := types.LatestSigner(params.TestChainConfig)
+	transfer := func(nonce uint64) *types.Transaction {
+		return types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &testUserAddress,
+			Value:    big.NewInt(1),
+			Gas:      params.TxGas,
+			GasPrice: big.NewInt(2 * params.InitialBaseFee),
+		})
+	}
+	revert := types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+		Nonce:    2,
+		Gas:      100_000,
+		GasPrice: big.NewInt(2 * params.InitialBaseFee),
+		Data:     common.FromHex("0x60006000fd"), // REVERT(0, 0)
+	})
+	var (
+		included   = &Bundle{Txs: types.Transactions{transfer(0), transfer(1)}, BlockNumber: 1}
+		nonceGap   = &Bundle{Txs: types.Transactions{transfer(2), transfer(4)}, BlockNumber: 1}
+		reverting  = &Bundle{Txs: types.Transactions{revert}, BlockNumber: 1}
+		protected  = &Bundle{Txs: types.Transactions{revert, transfer(3)}, BlockNumber: 1, RevertingTxHashes: []common.Hash{revert.Hash()}}
+		nextBlock  = &Bundle{Txs: types.Transactions{transfer(4)}, BlockNumber: 2}
+		submission = []*Bundle{included, nonceGap, reverting, protected, nextBlock}
+	)
+	for i, bundle := range submission {
+		if _, err := w.AddBundle(bundle); err != nil {
+			t.Fatalf("bundle %d: failed to add: %v", i, err)
+		}
+	}
+	if _, err := w.AddBundle(included); err != errBundleKnown {
+		t.Fatalf("duplicate bundle: have %v, want %v", err, errBundleKnown)
+	}
+	if _, err := w.AddBundle(&Bundle{Txs: types.Transactions{transfer(6)}, BlockNumber: 0}); err != errBundleTargetPassed {
+		t.Fatalf("stale bundle: have %v, want %v", err, errBundleTargetPassed)
+	}
+	r := w.generateWork(&generateParams{
+		parentHash: b.chain.CurrentBlock().Hash(),
+		timestamp:  uint64(time.Now().Unix()),
+		coinbase:   common.HexToAddress("0xdeadbeef"),
+	})
+	if r.err != nil {
+		t.Fatalf("failed to generate work: %v", r.err)
+	}
+	// The bundles must be included atomically at the top of the block, superseding
+	// the conflicting pool transaction
+	want := types.Transactions{transfer(0), transfer(1), revert, transfer(3)}
+	txs := r.block.Transactions()
+	if len(txs) != len(want) {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(txs), len(want))
+	}
+	for i, tx := range txs {
+		if tx.Hash() != want[i].Hash() {
+			t.Fatalf("transaction %d mismatch: have %x, want %x", i, tx.Hash(), want[i].Hash())
+		}
+	}
+	w.bundles.trackAll(r.block.Hash(), r.bundles)
+
+	// Bundles are only built until their target block makes it into the chain
+	if result := w.BundleStatus(included.Hash()); result == nil || result.Status != BundleBuilt {
+		t.Fatalf("status mismatch before import: have %+v, want %s", result, BundleBuilt)
+	}
+	// The rolled back bundles must leave no trace in the built state
+	if _, err := b.chain.InsertChain(types.Blocks{r.block}); err != nil {
+		t.Fatalf("failed to import built block: %v", err)
+	}
+	for i, test := range []struct {
+		bundle *Bundle
+		status string
+	}{
+		{included, BundleIncluded},
+		{nonceGap, BundleRejected},
+		{reverting, BundleRejected},
+		{protected, BundleIncluded},
+		{nextBlock, BundlePending},
+	} {
+		result := w.BundleStatus(test.bundle.Hash())
+		if result == nil || result.Status != test.status {
+			t.Fatalf("bundle %d: status mismatch: have %+v, want %s", i, result, test.status)
+		}
+		if test.status == BundleIncluded && (result.Block == nil || *result.Block != r.block.Hash() || result.GasUsed == 0) {
+			t.Fatalf("bundle %d: inclusion not tracked: %+v", i, result)
+		}
+		if test.status == BundleRejected && result.Reason == "" {
+			t.Fatalf("bundle %d: missing rejection reason", i)
+		}
+	}
+	// Bundles built into a payload losing out to another block are missed
+	expiring := &Bundle{Txs: types.Transactions{transfer(5)}, BlockNumber: 3}
+	if _, err := w.AddBundle(expiring); err != nil {
+		t.Fatalf("failed to add expiring bundle: %v", err)
+	}
+	for _, noTxs := range []bool{false, true} {
+		r := w.generateWork(&generateParams{
+			parentHash: r.block.Hash(),
+			timestamp:  r.block.Time() + 1,
+			coinbase:   common.HexToAddress("0xdeadbeef"),
+			noTxs:      noTxs,
+		})
+		if r.err != nil {
+			t.Fatalf("failed to generate work: %v", r.err)
+		}
+		if noTxs {
+			if _, err := b.chain.InsertChain(types.Blocks{r.block}); err != nil {
+				t.Fatalf("failed to import empty block: %v", err)
+			}
+		} else {
+			w.bundles.trackAll(r.block.Hash(), r.bundles)
+		}
+	}
+	if result := w.BundleStatus(nextBlock.Hash()); result.Status != BundleMissed {
+		t.Fatalf("bundle not missed: %+v", result)
+	}
+	// Bundles whose target block passed without being simulated expire
+	w.bundles.pending(4)
+	if result := w.BundleStatus(expiring.Hash()); result.Status != BundleExpired {
+		t.Fatalf("bundle not expired: %+v", result)
+	}
+}
@@ -0,0 +1,71 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that conditional transactions are only included if their constraints
+// hold against the block being built.
+func TestConditionalTransactions(t *testing.T) {
+	signer := types.LatestSigner(params.TestChainConfig)
+	conditional := types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+		Nonce:    1,
+		To:       &testUserAddress,
+		Value:    big.NewInt(1),
+		Gas:      params.TxGas,
+		GasPrice: big.NewInt(params.InitialBaseFee),
+	})
+	minBlock, slot := hexutil.Uint64(2), common.Hash{0x01}
+
+	tests := []struct {
+		cond     *txpool.TransactionConditional
+		included bool
+	}{
+		{&txpool.TransactionConditional{}, true},
+		{&txpool.TransactionConditional{BlockNumberMin: &minBlock}, false},
+		{&txpool.TransactionConditional{KnownAccounts: map[common.Address]txpool.KnownAccount{
+			testUserAddress: {StorageSlots: map[common.Hash]common.Hash{{}: {}}},
+		}}, true},
+		{&txpool.TransactionConditional{KnownAccounts: map[common.Address]txpool.KnownAccount{
+			testUserAddress: {StorageSlots: map[common.Hash]common.Hash{{}: slot}},
+		}}, false},
+		{&txpool.TransactionConditional{KnownAccounts: map[common.Address]txpool.KnownAccount{
+			testUserAddress: {StorageRoot: &types.EmptyRootHash},
+		}}, true},
+	}
+	for i, tt := range tests {
+		w, b := newTestWorker(t, params.TestChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+		if err := b.txPool.AddConditional("", conditional, tt.cond); err != nil {
+			t.Fatalf("test %d: failed to add conditional transaction: %v", i, err)
+		}
+		if err := b.txPool.Sync(); err != nil {
+			t.Fatalf("test %d: failed to sync pool: %v", i, err)
+		}
+		r := w.generateWork(&generateParams{
+			parentHash: b.chain.CurrentBlock().Hash(),
+			timestamp:  uint64(time.Now().Unix()),
+			coinbase:   common.HexToAddress("0xdeadbeef"),
+		})
+		if r.err != nil {
+			t.Fatalf("test %d: failed to generate work: %v", i, r.err)
+		}
+		txs := r.block.Transactions()
+		if len(txs) == 0 || txs[0].Hash() != pendingTxs[0].Hash() {
+			t.Fatalf("test %d: unconditional transaction missing", i)
+		}
+		if included := len(txs) == 2 && txs[1].Hash() == conditional.Hash(); included != tt.included {
+			t.Errorf("test %d: conditional transaction inclusion mismatch: have %v, want %v", i, included, tt.included)
+		}
+		b.chain.Stop()
+	}
+}
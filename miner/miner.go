@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -48,6 +49,9 @@ type Config struct {
 	GasCeil             uint64         // Target gas ceiling for mined blocks.
 	GasPrice            *big.Int       // Minimum gas price for mining a transaction
 	Recommit            time.Duration  // The time interval for miner to re-create mining work.
+
+	TxOrdering      string           `toml:",omitempty"` // Transaction ordering strategy (price, fifo or priority)
+	PrioritySenders []common.Address `toml:",omitempty"` // Senders included first by the priority ordering
 }
 
 // DefaultConfig contains default settings for miner.
@@ -73,10 +77,17 @@ type Miner struct {
 	chain       *core.BlockChain
 	pending     *pending
 	pendingMu   sync.Mutex // Lock protects the pending block
+	orderer     TxOrderer  // Ordering of the pending transactions of different accounts
+	bundles     *bundlePool
 }
 
 // New creates a new miner with provided config.
 func New(eth Backend, config Config, engine consensus.Engine) *Miner {
+	orderer, err := NewTxOrderer(config.TxOrdering, config.PrioritySenders)
+	if err != nil {
+		log.Error("Invalid transaction ordering, using price ordering", "err", err)
+		orderer = PriceTimeOrderer{}
+	}
 	return &Miner{
 		config:      &config,
 		chainConfig: eth.BlockChain().Config(),
@@ -84,6 +95,8 @@ func New(eth Backend, config Config, engine consensus.Engine) *Miner {
 		txpool:      eth.TxPool(),
 		chain:       eth.BlockChain(),
 		pending:     &pending{},
+		orderer:     orderer,
+		bundles:     newBundlePool(),
 	}
 }
 
@@ -1,6 +1,8 @@
 package miner
 
 import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
@@ -27,3 +29,22 @@ func (payload *Payload) ResolveFullBlock() *types.Block {
 	}
 	return payload.full
 }
+
+// commitForcedTransactions applies the transactions forced by the QNG driver at
+// the top of the block, in the given order. Contrary to pool transactions, they
+// may not be skipped: if any of them fails, the whole build fails.
+func (miner *Miner) commitForcedTransactions(env *environment, txs types.Transactions) error {
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	for i, tx := range txs {
+		if tx.Protected() && !miner.chainConfig.IsEIP155(env.header.Number) {
+			return fmt.Errorf("forced transaction %d (%x): replay protected before EIP-155", i, tx.Hash())
+		}
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		if err := miner.commitTransaction(env, tx); err != nil {
+			return fmt.Errorf("forced transaction %d (%x): %w", i, tx.Hash(), err)
+		}
+	}
+	return nil
+}
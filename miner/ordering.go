@@ -26,17 +26,18 @@ import (
 	"github.com/holiman/uint256"
 )
 
-// txWithMinerFee wraps a transaction with its gas price or effective miner gasTipCap
-type txWithMinerFee struct {
-	tx   *txpool.LazyTransaction
-	from common.Address
-	fees *uint256.Int
+// OrderedTx wraps a pending transaction with its sender and effective miner
+// gasTipCap, as considered by a TxOrderer.
+type OrderedTx struct {
+	Tx   *txpool.LazyTransaction
+	From common.Address
+	Fees *uint256.Int // Effective miner gasTipCap at the block's base fee
 }
 
-// newTxWithMinerFee creates a wrapped transaction, calculating the effective
+// newOrderedTx creates a wrapped transaction, calculating the effective
 // miner gasTipCap if a base fee is provided.
 // Returns error in case of a negative effective miner gasTipCap.
-func newTxWithMinerFee(tx *txpool.LazyTransaction, from common.Address, baseFee *uint256.Int) (*txWithMinerFee, error) {
+func newOrderedTx(tx *txpool.LazyTransaction, from common.Address, baseFee *uint256.Int) (*OrderedTx, error) {
 	tip := new(uint256.Int).Set(tx.GasTipCap)
 	if baseFee != nil {
 		if tx.GasFeeCap.Cmp(baseFee) < 0 {
@@ -47,48 +48,43 @@ func newTxWithMinerFee(tx *txpool.LazyTransaction, from common.Address, baseFee
 			tip = tx.GasTipCap
 		}
 	}
-	return &txWithMinerFee{
-		tx:   tx,
-		from: from,
-		fees: tip,
+	return &OrderedTx{
+		Tx:   tx,
+		From: from,
+		Fees: tip,
 	}, nil
 }
 
-// txByPriceAndTime implements both the sort and the heap interface, making it useful
-// for all at once sorting as well as individually adding and removing elements.
-type txByPriceAndTime []*txWithMinerFee
-
-func (s txByPriceAndTime) Len() int { return len(s) }
-func (s txByPriceAndTime) Less(i, j int) bool {
-	// If the prices are equal, use the time the transaction was first seen for
-	// deterministic sorting
-	cmp := s[i].fees.Cmp(s[j].fees)
-	if cmp == 0 {
-		return s[i].tx.Time.Before(s[j].tx.Time)
-	}
-	return cmp > 0
+// txHeads implements both the sort and the heap interface over the next
+// transactions of the accounts, ordered by a TxOrderer.
+type txHeads struct {
+	txs     []*OrderedTx
+	orderer TxOrderer
 }
-func (s txByPriceAndTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 
-func (s *txByPriceAndTime) Push(x interface{}) {
-	*s = append(*s, x.(*txWithMinerFee))
+func (s *txHeads) Len() int           { return len(s.txs) }
+func (s *txHeads) Less(i, j int) bool { return s.orderer.Less(s.txs[i], s.txs[j]) }
+func (s *txHeads) Swap(i, j int)      { s.txs[i], s.txs[j] = s.txs[j], s.txs[i] }
+
+func (s *txHeads) Push(x interface{}) {
+	s.txs = append(s.txs, x.(*OrderedTx))
 }
 
-func (s *txByPriceAndTime) Pop() interface{} {
-	old := *s
+func (s *txHeads) Pop() interface{} {
+	old := s.txs
 	n := len(old)
 	x := old[n-1]
 	old[n-1] = nil
-	*s = old[0 : n-1]
+	s.txs = old[0 : n-1]
 	return x
 }
 
 // transactionsByPriceAndNonce represents a set of transactions that can return
-// transactions in a profit-maximizing sorted order, while supporting removing
-// entire batches of transactions for non-executable accounts.
+// transactions in the order of a TxOrderer, profit-maximizing by default, while
+// supporting removing entire batches of transactions for non-executable accounts.
 type transactionsByPriceAndNonce struct {
 	txs     map[common.Address][]*txpool.LazyTransaction // Per account nonce-sorted list of transactions
-	heads   txByPriceAndTime                             // Next transaction for each unique account (price heap)
+	heads   *txHeads                                     // Next transaction for each unique account (ordered heap)
 	signer  types.Signer                                 // Signer for the set of transactions
 	baseFee *uint256.Int                                 // Current base fee
 }
@@ -99,23 +95,32 @@ type transactionsByPriceAndNonce struct {
 // Note, the input map is reowned so the caller should not interact any more with
 // if after providing it to the constructor.
 func newTransactionsByPriceAndNonce(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) *transactionsByPriceAndNonce {
+	return newTransactionsByOrder(signer, txs, baseFee, PriceTimeOrderer{})
+}
+
+// newTransactionsByOrder creates a transaction set that can retrieve transactions
+// in the order of the given orderer in a nonce-honouring way.
+//
+// Note, the input map is reowned so the caller should not interact any more with
+// if after providing it to the constructor.
+func newTransactionsByOrder(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int, orderer TxOrderer) *transactionsByPriceAndNonce {
 	// Convert the basefee from header format to uint256 format
 	var baseFeeUint *uint256.Int
 	if baseFee != nil {
 		baseFeeUint = uint256.MustFromBig(baseFee)
 	}
-	// Initialize a price and received time based heap with the head transactions
-	heads := make(txByPriceAndTime, 0, len(txs))
+	// Initialize an ordered heap with the head transactions
+	heads := &txHeads{txs: make([]*OrderedTx, 0, len(txs)), orderer: orderer}
 	for from, accTxs := range txs {
-		wrapped, err := newTxWithMinerFee(accTxs[0], from, baseFeeUint)
+		wrapped, err := newOrderedTx(accTxs[0], from, baseFeeUint)
 		if err != nil {
 			delete(txs, from)
 			continue
 		}
-		heads = append(heads, wrapped)
+		heads.txs = append(heads.txs, wrapped)
 		txs[from] = accTxs[1:]
 	}
-	heap.Init(&heads)
+	heap.Init(heads)
 
 	// Assemble and return the transaction set
 	return &transactionsByPriceAndNonce{
@@ -126,38 +131,44 @@ func newTransactionsByPriceAndNonce(signer types.Signer, txs map[common.Address]
 	}
 }
 
-// Peek returns the next transaction by price.
+// Peek returns the next transaction in order.
 func (t *transactionsByPriceAndNonce) Peek() (*txpool.LazyTransaction, *uint256.Int) {
-	if len(t.heads) == 0 {
+	if t.Empty() {
 		return nil, nil
 	}
-	return t.heads[0].tx, t.heads[0].fees
+	return t.heads.txs[0].Tx, t.heads.txs[0].Fees
+}
+
+// Before reports whether the next transaction of the set should be included
+// before the next transaction of the other set. Both sets must be non-empty.
+func (t *transactionsByPriceAndNonce) Before(other *transactionsByPriceAndNonce) bool {
+	return t.heads.orderer.Less(t.heads.txs[0], other.heads.txs[0])
 }
 
 // Shift replaces the current best head with the next one from the same account.
 func (t *transactionsByPriceAndNonce) Shift() {
-	acc := t.heads[0].from
+	acc := t.heads.txs[0].From
 	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
-		if wrapped, err := newTxWithMinerFee(txs[0], acc, t.baseFee); err == nil {
-			t.heads[0], t.txs[acc] = wrapped, txs[1:]
-			heap.Fix(&t.heads, 0)
+		if wrapped, err := newOrderedTx(txs[0], acc, t.baseFee); err == nil {
+			t.heads.txs[0], t.txs[acc] = wrapped, txs[1:]
+			heap.Fix(t.heads, 0)
 			return
 		}
 	}
-	heap.Pop(&t.heads)
+	heap.Pop(t.heads)
 }
 
 // Pop removes the best transaction, *not* replacing it with the next one from
 // the same account. This should be used when a transaction cannot be executed
 // and hence all subsequent ones should be discarded from the same account.
 func (t *transactionsByPriceAndNonce) Pop() {
-	heap.Pop(&t.heads)
+	heap.Pop(t.heads)
 }
 
 // Empty returns if the price heap is empty. It can be used to check it simpler
 // than calling peek and checking for nil return.
 func (t *transactionsByPriceAndNonce) Empty() bool {
-	return len(t.heads) == 0
+	return t.heads == nil || len(t.heads.txs) == 0
 }
 
 // Clear removes the entire content of the heap.
@@ -0,0 +1,96 @@
+package miner
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Names of the built-in transaction ordering strategies.
+const (
+	TxOrderingPrice    = "price"    // Highest effective tip first, then first-seen time
+	TxOrderingFIFO     = "fifo"     // First-seen time only, regardless of the tip
+	TxOrderingPriority = "priority" // Allowlisted senders first, then by price
+)
+
+// TxOrderer defines the order in which the miner includes the pending
+// transactions of different accounts into a block. The nonce order of the
+// transactions of a single account is always honoured.
+type TxOrderer interface {
+	// Less reports whether transaction a should be included before b.
+	Less(a, b *OrderedTx) bool
+}
+
+// PriceTimeOrderer orders transactions by their effective miner tip, using the
+// time the transactions were first seen to break ties. It is the default,
+// profit-maximizing ordering.
+type PriceTimeOrderer struct{}
+
+// Less implements TxOrderer.
+func (PriceTimeOrderer) Less(a, b *OrderedTx) bool {
+	// If the prices are equal, use the time the transaction was first seen for
+	// deterministic sorting
+	cmp := a.Fees.Cmp(b.Fees)
+	if cmp == 0 {
+		return a.Tx.Time.Before(b.Tx.Time)
+	}
+	return cmp > 0
+}
+
+// FIFOOrderer orders transactions strictly by the time they were first seen,
+// ignoring their tips. Ties are broken by transaction hash for determinism.
+type FIFOOrderer struct{}
+
+// Less implements TxOrderer.
+func (FIFOOrderer) Less(a, b *OrderedTx) bool {
+	if !a.Tx.Time.Equal(b.Tx.Time) {
+		return a.Tx.Time.Before(b.Tx.Time)
+	}
+	return bytes.Compare(a.Tx.Hash[:], b.Tx.Hash[:]) < 0
+}
+
+// PriorityOrderer includes the transactions of an allowlist of senders before
+// any other, ordering the transactions within each group by a fallback orderer.
+type PriorityOrderer struct {
+	senders  map[common.Address]struct{}
+	fallback TxOrderer
+}
+
+// NewPriorityOrderer creates an orderer prioritizing the given senders, using
+// the fallback orderer within the prioritized and the other transactions.
+func NewPriorityOrderer(senders []common.Address, fallback TxOrderer) *PriorityOrderer {
+	set := make(map[common.Address]struct{}, len(senders))
+	for _, sender := range senders {
+		set[sender] = struct{}{}
+	}
+	return &PriorityOrderer{senders: set, fallback: fallback}
+}
+
+// Less implements TxOrderer.
+func (o *PriorityOrderer) Less(a, b *OrderedTx) bool {
+	_, pa := o.senders[a.From]
+	_, pb := o.senders[b.From]
+	if pa != pb {
+		return pa
+	}
+	return o.fallback.Less(a, b)
+}
+
+// NewTxOrderer creates one of the built-in transaction orderers by name. An
+// empty name selects the default price and time ordering.
+func NewTxOrderer(name string, priority []common.Address) (TxOrderer, error) {
+	switch name {
+	case "", TxOrderingPrice:
+		return PriceTimeOrderer{}, nil
+	case TxOrderingFIFO:
+		return FIFOOrderer{}, nil
+	case TxOrderingPriority:
+		if len(priority) == 0 {
+			return nil, fmt.Errorf("transaction ordering %q requires priority senders", name)
+		}
+		return NewPriorityOrderer(priority, PriceTimeOrderer{}), nil
+	default:
+		return nil, fmt.Errorf("unknown transaction ordering %q", name)
+	}
+}
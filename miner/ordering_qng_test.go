@@ -0,0 +1,186 @@
+package miner
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// Tests that the built-in orderers sort the heads of the accounts as expected
+// while honouring the nonce order within an account.
+func TestTxOrderers(t *testing.T) {
+	t.Parallel()
+
+	var (
+		signer = types.LatestSignerForChainID(common.Big1)
+		start  = time.Unix(1_700_000_000, 0)
+		keys   = make(map[string]common.Address)
+		txs    = make(map[common.Hash]string)
+	)
+	// Create three accounts: a cheap early one, an expensive late one and a
+	// mid-priced one in between, each having two transactions
+	build := func() map[common.Address][]*txpool.LazyTransaction {
+		groups := make(map[common.Address][]*txpool.LazyTransaction)
+		for i, spec := range []struct {
+			name string
+			tip  int64
+		}{{"cheap", 1}, {"mid", 5}, {"rich", 10}} {
+			key, _ := crypto.HexToECDSA(common.Bytes2Hex(common.LeftPadBytes([]byte{byte(i + 1)}, 32)))
+			addr := crypto.PubkeyToAddress(key.PublicKey)
+			keys[spec.name] = addr
+			for nonce := uint64(0); nonce < 2; nonce++ {
+				tx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+					Nonce:     nonce,
+					To:        &common.Address{},
+					Gas:       21000,
+					GasFeeCap: big.NewInt(100),
+					GasTipCap: big.NewInt(spec.tip),
+				})
+				txs[tx.Hash()] = spec.name
+				groups[addr] = append(groups[addr], &txpool.LazyTransaction{
+					Hash:      tx.Hash(),
+					Tx:        tx,
+					Time:      start.Add(time.Duration(int(nonce)*3+i) * time.Second),
+					GasFeeCap: uint256.MustFromBig(tx.GasFeeCap()),
+					GasTipCap: uint256.MustFromBig(tx.GasTipCap()),
+					Gas:       tx.Gas(),
+				})
+			}
+		}
+		return groups
+	}
+	build()
+
+	tests := []struct {
+		name     string
+		ordering string
+		priority []string
+		want     []string
+	}{
+		{"default", "", nil, []string{"rich", "rich", "mid", "mid", "cheap", "cheap"}},
+		{"price", TxOrderingPrice, nil, []string{"rich", "rich", "mid", "mid", "cheap", "cheap"}},
+		{"fifo", TxOrderingFIFO, nil, []string{"cheap", "mid", "rich", "cheap", "mid", "rich"}},
+		{"priority", TxOrderingPriority, []string{"cheap"}, []string{"cheap", "cheap", "rich", "rich", "mid", "mid"}},
+		{"priority-multi", TxOrderingPriority, []string{"cheap", "mid"}, []string{"mid", "mid", "cheap", "cheap", "rich", "rich"}},
+	}
+	for _, tt := range tests {
+		var priority []common.Address
+		for _, name := range tt.priority {
+			priority = append(priority, keys[name])
+		}
+		orderer, err := NewTxOrderer(tt.ordering, priority)
+		if err != nil {
+			t.Fatalf("%s: failed to create orderer: %v", tt.name, err)
+		}
+		set := newTransactionsByOrder(signer, build(), big.NewInt(1), orderer)
+
+		var have []string
+		for tx, _ := set.Peek(); tx != nil; tx, _ = set.Peek() {
+			have = append(have, txs[tx.Hash])
+			set.Shift()
+		}
+		if len(have) != len(tt.want) {
+			t.Fatalf("%s: transaction count mismatch: have %d, want %d", tt.name, len(have), len(tt.want))
+		}
+		for i := range have {
+			if have[i] != tt.want[i] {
+				t.Fatalf("%s: ordering mismatch: have %v, want %v", tt.name, have, tt.want)
+			}
+		}
+	}
+}
+
+func TestNewTxOrderer(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewTxOrderer("random", nil); err == nil {
+		t.Fatalf("unknown ordering accepted")
+	}
+	if _, err := NewTxOrderer(TxOrderingPriority, nil); err == nil {
+		t.Fatalf("priority ordering without senders accepted")
+	}
+}
+
+// Tests that only the price ordering includes the local transactions first,
+// the other orderings mixing locals and remotes in the same order.
+func TestTxOrderingLocals(t *testing.T) {
+	t.Parallel()
+
+	for _, ordering := range []string{TxOrderingPrice, TxOrderingFIFO} {
+		var (
+			localKey, _  = crypto.GenerateKey()
+			remoteKey, _ = crypto.GenerateKey()
+			genesis      = &core.Genesis{
+				Config: params.TestChainConfig,
+				Alloc: types.GenesisAlloc{
+					crypto.PubkeyToAddress(localKey.PublicKey):  {Balance: testBankFunds},
+					crypto.PubkeyToAddress(remoteKey.PublicKey): {Balance: testBankFunds},
+				},
+			}
+			signer   = types.LatestSigner(params.TestChainConfig)
+			transfer = func(key *ecdsa.PrivateKey, price int64) *types.Transaction {
+				return types.MustSignNewTx(key, signer, &types.LegacyTx{
+					To:       &testUserAddress,
+					Gas:      params.TxGas,
+					GasPrice: big.NewInt(price * params.InitialBaseFee),
+				})
+			}
+		)
+		chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+		if err != nil {
+			t.Fatalf("%s: failed to create chain: %v", ordering, err)
+		}
+		defer chain.Stop()
+
+		pool, _ := txpool.New(testTxPoolConfig.PriceLimit, chain, []txpool.SubPool{legacypool.New(testTxPoolConfig, chain)})
+		defer pool.Close()
+
+		// Add an expensive remote transaction before a cheap local one
+		remote, local := transfer(remoteKey, 3), transfer(localKey, 2)
+		if err := pool.Add(types.Transactions{remote}, false, true)[0]; err != nil {
+			t.Fatalf("%s: failed to add remote transaction: %v", ordering, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if err := pool.Add(types.Transactions{local}, true, true)[0]; err != nil {
+			t.Fatalf("%s: failed to add local transaction: %v", ordering, err)
+		}
+		config := testConfig
+		config.TxOrdering = ordering
+		miner := New(&testWorkerBackend{chain: chain, txPool: pool}, config, ethash.NewFaker())
+
+		r := miner.generateWork(&generateParams{
+			parentHash: chain.CurrentBlock().Hash(),
+			timestamp:  chain.CurrentBlock().Time + 1,
+			coinbase:   common.HexToAddress("0xdeadbeef"),
+		})
+		if r.err != nil {
+			t.Fatalf("%s: failed to generate work: %v", ordering, r.err)
+		}
+		want := types.Transactions{local, remote}
+		if ordering == TxOrderingFIFO {
+			want = types.Transactions{remote, local}
+		}
+		txs := r.block.Transactions()
+		if len(txs) != len(want) {
+			t.Fatalf("%s: transaction count mismatch: have %d, want %d", ordering, len(txs), len(want))
+		}
+		for i, tx := range txs {
+			if tx.Hash() != want[i].Hash() {
+				t.Errorf("%s: transaction %d mismatch: have %x, want %x", ordering, i, tx.Hash(), want[i].Hash())
+			}
+		}
+	}
+}
@@ -41,6 +41,8 @@ type BuildPayloadArgs struct {
 	Random       common.Hash           // The provided randomness value
 	Withdrawals  types.Withdrawals     // The provided withdrawals
 	BeaconRoot   *common.Hash          // The provided beaconRoot (Cancun)
+	GasLimit     uint64                // The gas limit to target instead of the miner's gas ceiling, zero if unset
+	Transactions types.Transactions    // Transactions forced at the top of the block, in order
 	Version      engine.PayloadVersion // Versioning byte for payload id calculation.
 }
 
@@ -55,6 +57,12 @@ func (args *BuildPayloadArgs) Id() engine.PayloadID {
 	if args.BeaconRoot != nil {
 		hasher.Write(args.BeaconRoot[:])
 	}
+	if args.GasLimit != 0 {
+		binary.Write(hasher, binary.BigEndian, args.GasLimit)
+	}
+	for _, tx := range args.Transactions {
+		hasher.Write(tx.Hash().Bytes())
+	}
 	var out engine.PayloadID
 	copy(out[:], hasher.Sum(nil)[:8])
 	out[0] = byte(args.Version)
@@ -72,6 +80,7 @@ type Payload struct {
 	full     *types.Block
 	sidecars []*types.BlobTxSidecar
 	fullFees *big.Int
+	stats    buildStats
 	stop     chan struct{}
 	lock     sync.Mutex
 	cond     *sync.Cond
@@ -82,6 +91,7 @@ func newPayload(empty *types.Block, id engine.PayloadID) *Payload {
 	payload := &Payload{
 		id:    id,
 		empty: empty,
+		stats: buildStats{started: time.Now(), emptyFees: new(big.Int)},
 		stop:  make(chan struct{}),
 	}
 	log.Info("Starting work on payload", "id", payload.id)
@@ -89,20 +99,27 @@ func newPayload(empty *types.Block, id engine.PayloadID) *Payload {
 	return payload
 }
 
-// update updates the full-block with latest built version.
-func (payload *Payload) update(r *newPayloadResult, elapsed time.Duration) {
+// update updates the full-block with latest built version, returning whether
+// the full-block was replaced.
+func (payload *Payload) update(r *newPayloadResult, elapsed time.Duration) bool {
 	payload.lock.Lock()
 	defer payload.lock.Unlock()
 
 	select {
 	case <-payload.stop:
-		return // reject stale update
+		return false // reject stale update
 	default:
 	}
+	if r.err != nil {
+		payload.stats.track(r, elapsed, false)
+		return false
+	}
 	// Ensure the newly provided full block has a higher transaction fee.
 	// In post-merge stage, there is no uncle reward anymore and transaction
 	// fee(apart from the mev revenue) is the only indicator for comparison.
-	if payload.full == nil || r.fees.Cmp(payload.fullFees) > 0 {
+	improved := payload.full == nil || r.fees.Cmp(payload.fullFees) > 0
+	payload.stats.track(r, elapsed, improved)
+	if improved {
 		payload.full = r.block
 		payload.fullFees = r.fees
 		payload.sidecars = r.sidecars
@@ -121,6 +138,7 @@ func (payload *Payload) update(r *newPayloadResult, elapsed time.Duration) {
 		)
 	}
 	payload.cond.Broadcast() // fire signal for notifying full block
+	return improved
 }
 
 // Resolve returns the latest built payload and also terminates the background
@@ -188,6 +206,8 @@ func (miner *Miner) buildPayload(args *BuildPayloadArgs) (*Payload, error) {
 		random:      args.Random,
 		withdrawals: args.Withdrawals,
 		beaconRoot:  args.BeaconRoot,
+		gasLimit:    args.GasLimit,
+		forcedTxs:   args.Transactions,
 		noTxs:       true,
 	}
 	empty := miner.generateWork(emptyParams)
@@ -197,10 +217,13 @@ func (miner *Miner) buildPayload(args *BuildPayloadArgs) (*Payload, error) {
 
 	// Construct a payload object for return.
 	payload := newPayload(empty.block, args.Id())
+	payload.stats.emptyFees = empty.fees
 
 	// Spin up a routine for updating the payload in background. This strategy
 	// can maximum the revenue for including transactions with highest fee.
 	go func() {
+		defer payload.trackRebuilds()
+
 		// Setup the timer for re-building the payload. The initial clock is kept
 		// for triggering process immediately.
 		timer := time.NewTimer(0)
@@ -219,6 +242,8 @@ func (miner *Miner) buildPayload(args *BuildPayloadArgs) (*Payload, error) {
 			random:      args.Random,
 			withdrawals: args.Withdrawals,
 			beaconRoot:  args.BeaconRoot,
+			gasLimit:    args.GasLimit,
+			forcedTxs:   args.Transactions,
 			noTxs:       false,
 		}
 
@@ -227,11 +252,12 @@ func (miner *Miner) buildPayload(args *BuildPayloadArgs) (*Payload, error) {
 			case <-timer.C:
 				start := time.Now()
 				r := miner.generateWork(fullParams)
-				if r.err == nil {
-					payload.update(r, time.Since(start))
-				} else {
+				if r.err != nil {
 					log.Info("Error while generating work", "id", payload.id, "err", r.err)
 				}
+				if payload.update(r, time.Since(start)) {
+					miner.bundles.trackAll(r.block.Hash(), r.bundles)
+				}
 				timer.Reset(miner.config.Recommit)
 			case <-payload.stop:
 				log.Info("Stopping work on payload", "id", payload.id, "reason", "delivery")
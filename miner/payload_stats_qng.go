@@ -0,0 +1,148 @@
+package miner
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// maxBuildIterations is the number of most recent build iterations tracked
+	// for a payload.
+	maxBuildIterations = 64
+
+	// maxSkippedTxs is the maximum number of skipped transactions recorded for
+	// a single build iteration.
+	maxSkippedTxs = 256
+)
+
+var (
+	payloadBuildTimer     = metrics.NewRegisteredTimer("miner/payload/build", nil)
+	payloadIterationMeter = metrics.NewRegisteredMeter("miner/payload/iterations", nil)
+	payloadImproveMeter   = metrics.NewRegisteredMeter("miner/payload/improved", nil)
+	payloadFailureMeter   = metrics.NewRegisteredMeter("miner/payload/failed", nil)
+	payloadSkippedMeter   = metrics.NewRegisteredMeter("miner/payload/skipped", nil)
+	payloadRebuildsHist   = metrics.NewRegisteredHistogram("miner/payload/rebuilds", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+// SkippedTx is a transaction left out of a payload build iteration.
+type SkippedTx struct {
+	Hash   common.Hash `json:"hash"`
+	Reason string      `json:"reason"`
+}
+
+// PayloadBuildIteration describes a single rebuild of a payload.
+type PayloadBuildIteration struct {
+	Time     time.Time      `json:"time"`
+	Elapsed  time.Duration  `json:"elapsed"` // Build time in nanoseconds
+	Txs      int            `json:"txs"`
+	GasUsed  hexutil.Uint64 `json:"gasUsed"`
+	Fees     *hexutil.Big   `json:"fees"`
+	Improved bool           `json:"improved"` // Whether the iteration replaced the full payload
+	Skipped  []SkippedTx    `json:"skipped,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// PayloadBuildStats summarizes the building progress of a payload.
+type PayloadBuildStats struct {
+	ID           engine.PayloadID        `json:"id"`
+	Started      time.Time               `json:"started"`
+	LastUpdate   time.Time               `json:"lastUpdate"` // Time the full payload last changed, zero if never built
+	Iterations   int                     `json:"iterations"`
+	Improvements int                     `json:"improvements"`
+	EmptyFees    *hexutil.Big            `json:"emptyFees"`
+	FullFees     *hexutil.Big            `json:"fullFees"`
+	FeeDelta     *hexutil.Big            `json:"feeDelta"` // Revenue gained by the full payload over the empty one
+	Resolved     bool                    `json:"resolved"`
+	History      []PayloadBuildIteration `json:"history"` // Most recent iterations, oldest first
+}
+
+// buildStats tracks the build iterations of a payload.
+type buildStats struct {
+	started      time.Time
+	lastUpdate   time.Time
+	iterations   int
+	improvements int
+	emptyFees    *big.Int
+	history      []PayloadBuildIteration
+}
+
+// skip records a transaction left out of the block being built.
+func (env *environment) skip(hash common.Hash, reason string) {
+	if len(env.skipped) < maxSkippedTxs {
+		env.skipped = append(env.skipped, SkippedTx{Hash: hash, Reason: reason})
+	}
+}
+
+// track records a build iteration. The caller must hold the payload lock.
+func (s *buildStats) track(r *newPayloadResult, elapsed time.Duration, improved bool) {
+	iteration := PayloadBuildIteration{
+		Time:     time.Now(),
+		Elapsed:  elapsed,
+		Improved: improved,
+		Skipped:  r.skipped,
+	}
+	if r.err != nil {
+		iteration.Error = r.err.Error()
+		payloadFailureMeter.Mark(1)
+	} else {
+		iteration.Txs = len(r.block.Transactions())
+		iteration.GasUsed = hexutil.Uint64(r.block.GasUsed())
+		iteration.Fees = (*hexutil.Big)(r.fees)
+	}
+	s.iterations++
+	if improved {
+		s.improvements++
+		s.lastUpdate = iteration.Time
+		payloadImproveMeter.Mark(1)
+	}
+	if len(s.history) == maxBuildIterations {
+		s.history = append(s.history[:0], s.history[1:]...)
+	}
+	s.history = append(s.history, iteration)
+
+	payloadBuildTimer.Update(elapsed)
+	payloadIterationMeter.Mark(1)
+	payloadSkippedMeter.Mark(int64(len(r.skipped)))
+}
+
+// Stats returns the building progress of the payload.
+func (payload *Payload) Stats() *PayloadBuildStats {
+	payload.lock.Lock()
+	defer payload.lock.Unlock()
+
+	stats := &PayloadBuildStats{
+		ID:           payload.id,
+		Started:      payload.stats.started,
+		LastUpdate:   payload.stats.lastUpdate,
+		Iterations:   payload.stats.iterations,
+		Improvements: payload.stats.improvements,
+		EmptyFees:    (*hexutil.Big)(payload.stats.emptyFees),
+		FullFees:     (*hexutil.Big)(new(big.Int)),
+		FeeDelta:     (*hexutil.Big)(new(big.Int)),
+		History:      append([]PayloadBuildIteration(nil), payload.stats.history...),
+	}
+	if payload.full != nil {
+		stats.FullFees = (*hexutil.Big)(payload.fullFees)
+		stats.FeeDelta = (*hexutil.Big)(new(big.Int).Sub(payload.fullFees, payload.stats.emptyFees))
+	}
+	select {
+	case <-payload.stop:
+		stats.Resolved = true
+	default:
+	}
+	return stats
+}
+
+// trackRebuilds records the number of build iterations of the payload once its
+// construction terminated.
+func (payload *Payload) trackRebuilds() {
+	payload.lock.Lock()
+	defer payload.lock.Unlock()
+
+	payloadRebuildsHist.Update(int64(payload.stats.iterations))
+}
@@ -0,0 +1,54 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestPayloadBuildStats(t *testing.T) {
+	var (
+		block   = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), GasLimit: 30_000_000})
+		payload = newPayload(block, engine.PayloadID{0x1})
+		skipped = []SkippedTx{{Hash: common.Hash{0x2}, Reason: "gas limit reached"}}
+	)
+	payload.update(&newPayloadResult{block: block, fees: big.NewInt(10), skipped: skipped}, time.Millisecond)
+	payload.update(&newPayloadResult{block: block, fees: big.NewInt(5)}, time.Millisecond)
+	payload.update(&newPayloadResult{err: errors.New("missing parent")}, time.Millisecond)
+
+	stats := payload.Stats()
+	if stats.Iterations != 3 || stats.Improvements != 1 || len(stats.History) != 3 {
+		t.Fatalf("iterations mismatch: have %d/%d/%d, want 3/1/3", stats.Iterations, stats.Improvements, len(stats.History))
+	}
+	if stats.FullFees.ToInt().Int64() != 10 || stats.FeeDelta.ToInt().Int64() != 10 {
+		t.Fatalf("fees mismatch: have %v/%v, want 10/10", stats.FullFees, stats.FeeDelta)
+	}
+	if !stats.History[0].Improved || stats.History[1].Improved || len(stats.History[0].Skipped) != 1 {
+		t.Fatalf("unexpected iterations: %+v", stats.History)
+	}
+	if stats.History[2].Error == "" || stats.LastUpdate != stats.History[0].Time {
+		t.Fatalf("failed iteration not tracked: %+v", stats.History[2])
+	}
+	if stats.Resolved {
+		t.Fatalf("payload resolved prematurely")
+	}
+	// The history is capped and stale updates are not tracked
+	for i := 0; i < maxBuildIterations; i++ {
+		payload.update(&newPayloadResult{block: block, fees: big.NewInt(1)}, time.Millisecond)
+	}
+	payload.Resolve()
+	payload.update(&newPayloadResult{block: block, fees: big.NewInt(100)}, time.Millisecond)
+
+	stats = payload.Stats()
+	if stats.Iterations != maxBuildIterations+3 || len(stats.History) != maxBuildIterations {
+		t.Fatalf("history not capped: have %d/%d", stats.Iterations, len(stats.History))
+	}
+	if !stats.Resolved || stats.FullFees.ToInt().Int64() != 10 {
+		t.Fatalf("stale update tracked: resolved %v, fees %v", stats.Resolved, stats.FullFees)
+	}
+}
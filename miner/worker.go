@@ -56,6 +56,7 @@ type environment struct {
 	receipts []*types.Receipt
 	sidecars []*types.BlobTxSidecar
 	blobs    int
+	skipped  []SkippedTx // transactions left out of the block, capped at maxSkippedTxs
 }
 
 const (
@@ -73,18 +74,22 @@ type newPayloadResult struct {
 	sidecars []*types.BlobTxSidecar // collected blobs of blob transactions
 	stateDB  *state.StateDB         // StateDB after executing the transactions
 	receipts []*types.Receipt       // Receipts collected during construction
+	skipped  []SkippedTx            // Transactions left out of the block
+	bundles  []*BundleResult        // Outcome of the bundles targeting the block
 }
 
 // generateParams wraps various settings for generating sealing task.
 type generateParams struct {
-	timestamp   uint64            // The timestamp for sealing task
-	forceTime   bool              // Flag whether the given timestamp is immutable or not
-	parentHash  common.Hash       // Parent block hash, empty means the latest chain head
-	coinbase    common.Address    // The fee recipient address for including transaction
-	random      common.Hash       // The randomness generated by beacon chain, empty before the merge
-	withdrawals types.Withdrawals // List of withdrawals to include in block (shanghai field)
-	beaconRoot  *common.Hash      // The beacon root (cancun field).
-	noTxs       bool              // Flag whether an empty block without any transaction is expected
+	timestamp   uint64             // The timestamp for sealing task
+	forceTime   bool               // Flag whether the given timestamp is immutable or not
+	parentHash  common.Hash        // Parent block hash, empty means the latest chain head
+	coinbase    common.Address     // The fee recipient address for including transaction
+	random      common.Hash        // The randomness generated by beacon chain, empty before the merge
+	withdrawals types.Withdrawals  // List of withdrawals to include in block (shanghai field)
+	beaconRoot  *common.Hash       // The beacon root (cancun field).
+	gasLimit    uint64             // The gas limit to target instead of the configured gas ceiling, zero if unset
+	forcedTxs   types.Transactions // Transactions to include at the top of the block, even if noTxs is set
+	noTxs       bool               // Flag whether an empty block without any pool transaction is expected
 }
 
 // generateWork generates a sealing block based on the given parameters.
@@ -93,7 +98,15 @@ func (miner *Miner) generateWork(params *generateParams) *newPayloadResult {
 	if err != nil {
 		return &newPayloadResult{err: err}
 	}
+	if len(params.forcedTxs) > 0 {
+		if err := miner.commitForcedTransactions(work, params.forcedTxs); err != nil {
+			return &newPayloadResult{err: err}
+		}
+	}
+	var bundles []*BundleResult
 	if !params.noTxs {
+		bundles = miner.commitBundles(work)
+
 		interrupt := new(atomic.Int32)
 		timer := time.AfterFunc(miner.config.Recommit, func() {
 			interrupt.Store(commitInterruptTimeout)
@@ -116,6 +129,8 @@ func (miner *Miner) generateWork(params *generateParams) *newPayloadResult {
 		sidecars: work.sidecars,
 		stateDB:  work.state,
 		receipts: work.receipts,
+		skipped:  work.skipped,
+		bundles:  bundles,
 	}
 }
 
@@ -145,10 +160,14 @@ func (miner *Miner) prepareWork(genParams *generateParams) (*environment, error)
 		timestamp = parent.Time + 1
 	}
 	// Construct the sealing block header.
+	gasCeil := miner.config.GasCeil
+	if genParams.gasLimit != 0 {
+		gasCeil = genParams.gasLimit
+	}
 	header := &types.Header{
 		ParentHash: parent.Hash(),
 		Number:     new(big.Int).Add(parent.Number, common.Big1),
-		GasLimit:   core.CalcGasLimit(parent.GasLimit, miner.config.GasCeil),
+		GasLimit:   core.CalcGasLimit(parent.GasLimit, gasCeil),
 		Time:       timestamp,
 		Coinbase:   genParams.coinbase,
 	}
@@ -164,8 +183,11 @@ func (miner *Miner) prepareWork(genParams *generateParams) (*environment, error)
 	if miner.chainConfig.IsLondon(header.Number) {
 		header.BaseFee = eip1559.CalcBaseFee(miner.chainConfig, parent)
 		if !miner.chainConfig.IsLondon(parent.Number) {
-			parentGasLimit := parent.GasLimit * miner.chainConfig.ElasticityMultiplier()
-			header.GasLimit = core.CalcGasLimit(parentGasLimit, miner.config.GasCeil)
+			parentGasLimit := parent.GasLimit * miner.chainConfig.ElasticityMultiplierAt(header.Number)
+			header.GasLimit = core.CalcGasLimit(parentGasLimit, gasCeil)
+		}
+		if limit, ok := miner.chainConfig.GasLimitTransition(header.Number); ok {
+			header.GasLimit = limit
 		}
 	}
 	// Run the consensus preparation with the default or customized consensus engine.
@@ -302,8 +324,8 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 			ltx *txpool.LazyTransaction
 			txs *transactionsByPriceAndNonce
 		)
-		pltx, ptip := plainTxs.Peek()
-		bltx, btip := blobTxs.Peek()
+		pltx, _ := plainTxs.Peek()
+		bltx, _ := blobTxs.Peek()
 
 		switch {
 		case pltx == nil:
@@ -311,7 +333,7 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 		case bltx == nil:
 			txs, ltx = plainTxs, pltx
 		default:
-			if ptip.Lt(btip) {
+			if blobTxs.Before(plainTxs) {
 				txs, ltx = blobTxs, bltx
 			} else {
 				txs, ltx = plainTxs, pltx
@@ -323,11 +345,13 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 		// If we don't have enough space for the next transaction, skip the account.
 		if env.gasPool.Gas() < ltx.Gas {
 			log.Trace("Not enough gas left for transaction", "hash", ltx.Hash, "left", env.gasPool.Gas(), "needed", ltx.Gas)
+			env.skip(ltx.Hash, "gas limit reached")
 			txs.Pop()
 			continue
 		}
 		if left := uint64(params.MaxBlobGasPerBlock - env.blobs*params.BlobTxBlobGasPerBlob); left < ltx.BlobGas {
 			log.Trace("Not enough blob gas left for transaction", "hash", ltx.Hash, "left", left, "needed", ltx.BlobGas)
+			env.skip(ltx.Hash, "blob gas limit reached")
 			txs.Pop()
 			continue
 		}
@@ -335,6 +359,7 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 		tx := ltx.Resolve()
 		if tx == nil {
 			log.Trace("Ignoring evicted transaction", "hash", ltx.Hash)
+			env.skip(ltx.Hash, "evicted from pool")
 			txs.Pop()
 			continue
 		}
@@ -346,9 +371,20 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 		// phase, start ignoring the sender until we do.
 		if tx.Protected() && !miner.chainConfig.IsEIP155(env.header.Number) {
 			log.Trace("Ignoring replay protected transaction", "hash", ltx.Hash, "eip155", miner.chainConfig.EIP155Block)
+			env.skip(ltx.Hash, "replay protected before EIP-155")
 			txs.Pop()
 			continue
 		}
+		// Re-check the constraints of conditional transactions against the
+		// pending state, skipping the sender if they don't hold
+		if cond := miner.txpool.Conditional(ltx.Hash); cond != nil {
+			if err := cond.Check(env.header, env.state); err != nil {
+				log.Trace("Ignoring conditional transaction", "hash", ltx.Hash, "err", err)
+				env.skip(ltx.Hash, err.Error())
+				txs.Pop()
+				continue
+			}
+		}
 		// Start executing the transaction
 		env.state.SetTxContext(tx.Hash(), env.tcount)
 
@@ -357,6 +393,7 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 		case errors.Is(err, core.ErrNonceTooLow):
 			// New head notification data race between the transaction pool and miner, shift
 			log.Trace("Skipping transaction with low nonce", "hash", ltx.Hash, "sender", from, "nonce", tx.Nonce())
+			env.skip(ltx.Hash, err.Error())
 			txs.Shift()
 
 		case errors.Is(err, nil):
@@ -367,6 +404,7 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 			// Transaction is regarded as invalid, drop all consecutive transactions from
 			// the same sender because of `nonce-too-high` clause.
 			log.Debug("Transaction failed, account skipped", "hash", ltx.Hash, "err", err)
+			env.skip(ltx.Hash, err.Error())
 			txs.Pop()
 		}
 	}
@@ -397,11 +435,16 @@ func (miner *Miner) fillTransactions(interrupt *atomic.Int32, env *environment)
 	filter.OnlyPlainTxs, filter.OnlyBlobTxs = false, true
 	pendingBlobTxs := miner.txpool.Pending(filter)
 
-	// Split the pending transactions into locals and remotes.
+	// Split the pending transactions into locals and remotes. Only the price
+	// ordering prefers locals, the others order all transactions together.
 	localPlainTxs, remotePlainTxs := make(map[common.Address][]*txpool.LazyTransaction), pendingPlainTxs
 	localBlobTxs, remoteBlobTxs := make(map[common.Address][]*txpool.LazyTransaction), pendingBlobTxs
 
-	for _, account := range miner.txpool.Locals() {
+	var locals []common.Address
+	if _, ok := miner.orderer.(PriceTimeOrderer); ok {
+		locals = miner.txpool.Locals()
+	}
+	for _, account := range locals {
 		if txs := remotePlainTxs[account]; len(txs) > 0 {
 			delete(remotePlainTxs, account)
 			localPlainTxs[account] = txs
@@ -413,16 +456,16 @@ func (miner *Miner) fillTransactions(interrupt *atomic.Int32, env *environment)
 	}
 	// Fill the block with all available pending transactions.
 	if len(localPlainTxs) > 0 || len(localBlobTxs) > 0 {
-		plainTxs := newTransactionsByPriceAndNonce(env.signer, localPlainTxs, env.header.BaseFee)
-		blobTxs := newTransactionsByPriceAndNonce(env.signer, localBlobTxs, env.header.BaseFee)
+		plainTxs := newTransactionsByOrder(env.signer, localPlainTxs, env.header.BaseFee, miner.orderer)
+		blobTxs := newTransactionsByOrder(env.signer, localBlobTxs, env.header.BaseFee, miner.orderer)
 
 		if err := miner.commitTransactions(env, plainTxs, blobTxs, interrupt); err != nil {
 			return err
 		}
 	}
 	if len(remotePlainTxs) > 0 || len(remoteBlobTxs) > 0 {
-		plainTxs := newTransactionsByPriceAndNonce(env.signer, remotePlainTxs, env.header.BaseFee)
-		blobTxs := newTransactionsByPriceAndNonce(env.signer, remoteBlobTxs, env.header.BaseFee)
+		plainTxs := newTransactionsByOrder(env.signer, remotePlainTxs, env.header.BaseFee, miner.orderer)
+		blobTxs := newTransactionsByOrder(env.signer, remoteBlobTxs, env.header.BaseFee, miner.orderer)
 
 		if err := miner.commitTransactions(env, plainTxs, blobTxs, interrupt); err != nil {
 			return err
@@ -368,6 +368,9 @@ type ChainConfig struct {
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
+
+	meerBridge      *MeerBridgeConfig // UTXO bridge of the Qitmeer network, bound by ResolveMeerNetwork
+	meerFeePolicies []*MeerFeePolicy  // Fee policies of the Qitmeer network, bound by ResolveMeerNetwork
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -394,23 +397,14 @@ func (c *ChainConfig) Description() string {
 	var banner string
 
 	// Create some basic network config output
-	network := NetworkNames[c.ChainID.String()]
+	network := NetworkName(c.ChainID)
 	if network == "" {
 		network = "unknown"
 	}
 	banner += fmt.Sprintf("Chain ID:  %v (%s)\n", c.ChainID, network)
-	switch {
-	case IsQngNetwork(c.ChainID):
-		banner += "Consensus: MeerDAG (proof-of-work)\n"
-		return QngEIPsBanner(banner, c)
-	case IsAmanaNetwork(c.ChainID):
-		banner += "Consensus: Amana (proof-of-authority)\n"
-		return QngEIPsBanner(banner, c)
-	case IsFlanaNetwork(c.ChainID):
-		banner += "Consensus: Flana (rollup)\n"
-		return QngEIPsBanner(banner, c)
-	case IsMizanaNetwork(c.ChainID):
-		banner += "Consensus: Mizana (ZK rollup)\n"
+	switch family := MeerNetworks.Family(c.ChainID); {
+	case family != "":
+		banner += fmt.Sprintf("Consensus: %s\n", family.consensus())
 		return QngEIPsBanner(banner, c)
 	case c.Ethash != nil:
 		if c.TerminalTotalDifficulty == nil {
@@ -931,6 +925,7 @@ type Rules struct {
 	IsBerlin, IsLondon                                      bool
 	IsMerge, IsShanghai, IsCancun, IsPrague                 bool
 	IsVerkle                                                bool
+	IsMeerBridge                                            bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -961,5 +956,6 @@ func (c *ChainConfig) Rules(num *big.Int, isMerge bool, timestamp uint64) Rules
 		IsPrague:         isMerge && c.IsPrague(num, timestamp),
 		IsVerkle:         isVerkle,
 		IsEIP4762:        isVerkle,
+		IsMeerBridge:     c.MeerBridge(num) != nil,
 	}
 }
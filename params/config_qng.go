@@ -1,131 +1,557 @@
 package params
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"math/big"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MeerFamily identifies the family of Qitmeer networks a chain belongs to.
+type MeerFamily string
+
+const (
+	MeerFamilyQng    MeerFamily = "qng"
+	MeerFamilyAmana  MeerFamily = "amana"
+	MeerFamilyFlana  MeerFamily = "flana"
+	MeerFamilyMizana MeerFamily = "mizana"
 )
 
+// consensus returns a human-readable description of the consensus driving the
+// networks of the family.
+func (f MeerFamily) consensus() string {
+	switch f {
+	case MeerFamilyQng:
+		return "MeerDAG (proof-of-work)"
+	case MeerFamilyAmana:
+		return "Amana (proof-of-authority)"
+	case MeerFamilyFlana:
+		return "Flana (rollup)"
+	case MeerFamilyMizana:
+		return "Mizana (ZK rollup)"
+	default:
+		return ""
+	}
+}
+
+// MeerNetworkKind identifies the role of a network within its family.
+type MeerNetworkKind string
+
+const (
+	MeerMainnet MeerNetworkKind = "main"
+	MeerTestnet MeerNetworkKind = "test"
+	MeerMixnet  MeerNetworkKind = "mix"
+	MeerPrivnet MeerNetworkKind = "priv"
+)
+
+// MeerFeePolicy is the block size and base fee policy of a Qitmeer network,
+// active from the given block height onwards. Zero fields retain the defaults.
+type MeerFeePolicy struct {
+	Block                    uint64 `json:"block"`
+	GasLimit                 uint64 `json:"gasLimit,omitempty"`                 // Target gas limit of the blocks, overriding the miner's gas ceiling
+	ElasticityMultiplier     uint64 `json:"elasticityMultiplier,omitempty"`     // Bounds the maximum gas limit an EIP-1559 block may have
+	BaseFeeChangeDenominator uint64 `json:"baseFeeChangeDenominator,omitempty"` // Bounds the amount the base fee can change between blocks
+}
+
+// MeerBridgeConfig configures the UTXO <-> EVM bridge precompile of a Qitmeer
+// network, moving value between the MeerDAG UTXO layer and the EVM.
+type MeerBridgeConfig struct {
+	Block    uint64         `json:"block"`    // Activation block of the bridge precompile
+	Operator common.Address `json:"operator"` // Signer of the UTXO lock proofs accepted for imports
+}
+
+// MeerForkSchedule is the activation schedule of the Ethereum upgrades on a
+// Qitmeer network, which the chain config of the local genesis must follow.
+// Nil fields are not enforced.
+type MeerForkSchedule struct {
+	LondonBlock  *big.Int `json:"londonBlock,omitempty"`
+	ShanghaiTime *uint64  `json:"shanghaiTime,omitempty"`
+	CancunTime   *uint64  `json:"cancunTime,omitempty"`
+	PragueTime   *uint64  `json:"pragueTime,omitempty"`
+}
+
+// CheckCompatible checks whether the chain config activates the upgrades at
+// the scheduled block heights and timestamps.
+func (s *MeerForkSchedule) CheckCompatible(c *ChainConfig) error {
+	if s.LondonBlock != nil && !configBlockEqual(s.LondonBlock, c.LondonBlock) {
+		return newBlockCompatError("London fork block", c.LondonBlock, s.LondonBlock)
+	}
+	for _, fork := range []struct {
+		name            string
+		stored, network *uint64
+	}{
+		{"Shanghai fork timestamp", c.ShanghaiTime, s.ShanghaiTime},
+		{"Cancun fork timestamp", c.CancunTime, s.CancunTime},
+		{"Prague fork timestamp", c.PragueTime, s.PragueTime},
+	} {
+		if fork.network != nil && !configTimestampEqual(fork.stored, fork.network) {
+			return newTimestampCompatError(fork.name, fork.stored, fork.network)
+		}
+	}
+	return nil
+}
+
+// MeerChainConfig describes a Qitmeer network.
 type MeerChainConfig struct {
-	ChainID *big.Int // chainId identifies the current chain and is used for replay protection
+	ChainID     *big.Int        `json:"chainId"` // chainId identifies the current chain and is used for replay protection
+	Name        string          `json:"name"`
+	Family      MeerFamily      `json:"family"`
+	Kind        MeerNetworkKind `json:"kind"`
+	GenesisHash common.Hash     `json:"genesisHash,omitempty"` // Genesis the local chain must match, zero if not pinned
+	Bootnodes   []string        `json:"bootnodes,omitempty"`   // Default bootnodes (enode URLs)
+	QngEngine   bool            `json:"qngEngine"`             // Whether the chain is driven through the QNG engine API, enabling it by default
+
+	Forks       *MeerForkSchedule `json:"forks,omitempty"`       // Upgrades the genesis chain config must schedule, nil if not enforced
+	FeePolicies []*MeerFeePolicy  `json:"feePolicies,omitempty"` // Block size and base fee policies, ordered by activation block
+	Bridge      *MeerBridgeConfig `json:"bridge,omitempty"`      // UTXO <-> EVM bridge, nil if not activated
+}
+
+// validate checks that the network description is complete.
+func (c *MeerChainConfig) validate() error {
+	if c.ChainID == nil || c.ChainID.Sign() <= 0 {
+		return errors.New("missing chain id")
+	}
+	if c.Name == "" {
+		return fmt.Errorf("network %v: missing name", c.ChainID)
+	}
+	if c.Family.consensus() == "" {
+		return fmt.Errorf("network %v: unknown family %q", c.ChainID, c.Family)
+	}
+	switch c.Kind {
+	case MeerMainnet, MeerTestnet, MeerMixnet, MeerPrivnet:
+	default:
+		return fmt.Errorf("network %v: unknown kind %q", c.ChainID, c.Kind)
+	}
+	for i, policy := range c.FeePolicies {
+		if i > 0 && policy.Block <= c.FeePolicies[i-1].Block {
+			return fmt.Errorf("network %v: fee policy #%d not ordered after #%d", c.ChainID, policy.Block, c.FeePolicies[i-1].Block)
+		}
+		if policy.GasLimit != 0 && policy.GasLimit < MinGasLimit {
+			return fmt.Errorf("network %v: fee policy #%d gas limit %d below minimum %d", c.ChainID, policy.Block, policy.GasLimit, MinGasLimit)
+		}
+	}
+	if c.Bridge != nil && c.Bridge.Operator == (common.Address{}) {
+		return fmt.Errorf("network %v: missing bridge operator", c.ChainID)
+	}
+	return nil
+}
+
+// FeePolicy returns the fee policy active at the given block height, or nil
+// if the network runs with the default policy.
+func (c *MeerChainConfig) FeePolicy(num uint64) *MeerFeePolicy {
+	return activeFeePolicy(c.FeePolicies, num)
+}
+
+// activeFeePolicy returns the policy of the list, ordered by activation block,
+// which is active at the given block height.
+func activeFeePolicy(policies []*MeerFeePolicy, num uint64) *MeerFeePolicy {
+	for i := len(policies) - 1; i >= 0; i-- {
+		if policies[i].Block <= num {
+			return policies[i]
+		}
+	}
+	return nil
 }
 
 var (
 	QngMainnetChainConfig = &MeerChainConfig{
-		ChainID: big.NewInt(813),
+		ChainID:   big.NewInt(813),
+		Name:      "qng",
+		Family:    MeerFamilyQng,
+		Kind:      MeerMainnet,
+		QngEngine: true,
 	}
 	QngTestnetChainConfig = &MeerChainConfig{
-		ChainID: big.NewInt(8131),
+		ChainID:   big.NewInt(8131),
+		Name:      "qng-test",
+		Family:    MeerFamilyQng,
+		Kind:      MeerTestnet,
+		QngEngine: true,
 	}
 	QngMixnetChainConfig = &MeerChainConfig{
-		ChainID: big.NewInt(8132),
+		ChainID:   big.NewInt(8132),
+		Name:      "qng-mix",
+		Family:    MeerFamilyQng,
+		Kind:      MeerMixnet,
+		QngEngine: true,
 	}
 	QngPrivnetChainConfig = &MeerChainConfig{
-		ChainID: big.NewInt(8133),
+		ChainID:   big.NewInt(8133),
+		Name:      "qng-priv",
+		Family:    MeerFamilyQng,
+		Kind:      MeerPrivnet,
+		QngEngine: true,
 	}
 
 	AmanaChainConfig = &MeerChainConfig{
 		ChainID: big.NewInt(8134),
+		Name:    "amana",
+		Family:  MeerFamilyAmana,
+		Kind:    MeerMainnet,
 	}
 	AmanaTestnetChainConfig = &MeerChainConfig{
 		ChainID: big.NewInt(81341),
+		Name:    "amana-test",
+		Family:  MeerFamilyAmana,
+		Kind:    MeerTestnet,
 	}
 	AmanaMixnetChainConfig = &MeerChainConfig{
 		ChainID: big.NewInt(81342),
+		Name:    "amana-mix",
+		Family:  MeerFamilyAmana,
+		Kind:    MeerMixnet,
 	}
 	AmanaPrivnetChainConfig = &MeerChainConfig{
 		ChainID: big.NewInt(81343),
+		Name:    "amana-priv",
+		Family:  MeerFamilyAmana,
+		Kind:    MeerPrivnet,
 	}
 
 	FlanaChainConfig = &MeerChainConfig{
 		ChainID: big.NewInt(8135),
+		Name:    "flana",
+		Family:  MeerFamilyFlana,
+		Kind:    MeerMainnet,
 	}
 	FlanaTestnetChainConfig = &MeerChainConfig{
 		ChainID: big.NewInt(81351),
+		Name:    "flana-test",
+		Family:  MeerFamilyFlana,
+		Kind:    MeerTestnet,
 	}
 	FlanaMixnetChainConfig = &MeerChainConfig{
 		ChainID: big.NewInt(81352),
+		Name:    "flana-mix",
+		Family:  MeerFamilyFlana,
+		Kind:    MeerMixnet,
 	}
 	FlanaPrivnetChainConfig = &MeerChainConfig{
 		ChainID: big.NewInt(81353),
+		Name:    "flana-priv",
+		Family:  MeerFamilyFlana,
+		Kind:    MeerPrivnet,
 	}
 
 	MizanaChainConfig = &MeerChainConfig{
 		ChainID: big.NewInt(8136),
+		Name:    "mizana",
+		Family:  MeerFamilyMizana,
+		Kind:    MeerMainnet,
 	}
 	MizanaTestnetChainConfig = &MeerChainConfig{
 		ChainID: big.NewInt(81361),
+		Name:    "mizana-test",
+		Family:  MeerFamilyMizana,
+		Kind:    MeerTestnet,
 	}
 	MizanaMixnetChainConfig = &MeerChainConfig{
 		ChainID: big.NewInt(81362),
+		Name:    "mizana-mix",
+		Family:  MeerFamilyMizana,
+		Kind:    MeerMixnet,
 	}
 	MizanaPrivnetChainConfig = &MeerChainConfig{
 		ChainID: big.NewInt(81363),
+		Name:    "mizana-priv",
+		Family:  MeerFamilyMizana,
+		Kind:    MeerPrivnet,
 	}
+
+	// MeerNetworks is the registry of all known Qitmeer networks.
+	MeerNetworks = NewMeerNetworkRegistry()
 )
 
 func init() {
-	NetworkNames[QngMainnetChainConfig.ChainID.String()] = "qng"
-	NetworkNames[QngTestnetChainConfig.ChainID.String()] = "qng-test"
-	NetworkNames[QngMixnetChainConfig.ChainID.String()] = "qng-mix"
-	NetworkNames[QngPrivnetChainConfig.ChainID.String()] = "qng-priv"
+	for _, config := range []*MeerChainConfig{
+		QngMainnetChainConfig, QngTestnetChainConfig, QngMixnetChainConfig, QngPrivnetChainConfig,
+		AmanaChainConfig, AmanaTestnetChainConfig, AmanaMixnetChainConfig, AmanaPrivnetChainConfig,
+		FlanaChainConfig, FlanaTestnetChainConfig, FlanaMixnetChainConfig, FlanaPrivnetChainConfig,
+		MizanaChainConfig, MizanaTestnetChainConfig, MizanaMixnetChainConfig, MizanaPrivnetChainConfig,
+	} {
+		if err := MeerNetworks.Register(config); err != nil {
+			panic(err)
+		}
+		NetworkNames[config.ChainID.String()] = config.Name
+	}
+}
 
-	NetworkNames[AmanaChainConfig.ChainID.String()] = "amana"
-	NetworkNames[AmanaTestnetChainConfig.ChainID.String()] = "amana-test"
-	NetworkNames[AmanaMixnetChainConfig.ChainID.String()] = "amana-mix"
-	NetworkNames[AmanaPrivnetChainConfig.ChainID.String()] = "amana-priv"
+// MeerNetworkRegistry is a set of Qitmeer networks, indexed by chain id.
+type MeerNetworkRegistry struct {
+	networks map[string]*MeerChainConfig
+	lock     sync.RWMutex
+}
 
-	NetworkNames[FlanaChainConfig.ChainID.String()] = "flana"
-	NetworkNames[FlanaTestnetChainConfig.ChainID.String()] = "flana-test"
-	NetworkNames[FlanaMixnetChainConfig.ChainID.String()] = "flana-mix"
-	NetworkNames[FlanaPrivnetChainConfig.ChainID.String()] = "flana-priv"
+// NewMeerNetworkRegistry creates an empty network registry.
+func NewMeerNetworkRegistry() *MeerNetworkRegistry {
+	return &MeerNetworkRegistry{networks: make(map[string]*MeerChainConfig)}
+}
 
-	NetworkNames[MizanaChainConfig.ChainID.String()] = "mizana"
-	NetworkNames[MizanaTestnetChainConfig.ChainID.String()] = "mizana-test"
-	NetworkNames[MizanaMixnetChainConfig.ChainID.String()] = "mizana-mix"
-	NetworkNames[MizanaPrivnetChainConfig.ChainID.String()] = "mizana-priv"
+// Register adds a network to the registry, replacing any network previously
+// registered with the same chain id.
+func (r *MeerNetworkRegistry) Register(config *MeerChainConfig) error {
+	return r.register([]*MeerChainConfig{config})
 }
 
-func IsQngNetwork(chainID *big.Int) bool {
-	if chainID.Cmp(QngMainnetChainConfig.ChainID) == 0 ||
-		chainID.Cmp(QngTestnetChainConfig.ChainID) == 0 ||
-		chainID.Cmp(QngMixnetChainConfig.ChainID) == 0 ||
-		chainID.Cmp(QngPrivnetChainConfig.ChainID) == 0 {
-		return true
+// register adds a set of networks to the registry. Nothing is registered if
+// any of the networks is invalid or their names clash.
+func (r *MeerNetworkRegistry) register(configs []*MeerChainConfig) error {
+	for _, config := range configs {
+		if err := config.validate(); err != nil {
+			return err
+		}
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	networks := maps.Clone(r.networks)
+	for _, config := range configs {
+		networks[config.ChainID.String()] = config
 	}
-	return false
+	names := make(map[string]string, len(networks))
+	for id, network := range networks {
+		if other, ok := names[network.Name]; ok {
+			return fmt.Errorf("network name %q used by both chain %s and %s", network.Name, other, id)
+		}
+		names[network.Name] = id
+	}
+	r.networks = networks
+	return nil
 }
 
-func IsAmanaNetwork(chainID *big.Int) bool {
-	if chainID.Cmp(AmanaChainConfig.ChainID) == 0 ||
-		chainID.Cmp(AmanaTestnetChainConfig.ChainID) == 0 ||
-		chainID.Cmp(AmanaMixnetChainConfig.ChainID) == 0 ||
-		chainID.Cmp(AmanaPrivnetChainConfig.ChainID) == 0 {
-		return true
+// Unregister removes the network with the given chain id from the registry.
+func (r *MeerNetworkRegistry) Unregister(chainID *big.Int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.networks, chainID.String())
+}
+
+// Get returns the network with the given chain id, or nil if unknown.
+func (r *MeerNetworkRegistry) Get(chainID *big.Int) *MeerChainConfig {
+	if chainID == nil {
+		return nil
 	}
-	return false
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.networks[chainID.String()]
 }
 
-func IsFlanaNetwork(chainID *big.Int) bool {
-	if chainID.Cmp(FlanaChainConfig.ChainID) == 0 ||
-		chainID.Cmp(FlanaTestnetChainConfig.ChainID) == 0 ||
-		chainID.Cmp(FlanaMixnetChainConfig.ChainID) == 0 ||
-		chainID.Cmp(FlanaPrivnetChainConfig.ChainID) == 0 {
-		return true
+// GetByName returns the network with the given name, or nil if unknown.
+func (r *MeerNetworkRegistry) GetByName(name string) *MeerChainConfig {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, network := range r.networks {
+		if network.Name == name {
+			return network
+		}
+	}
+	return nil
+}
+
+// Networks returns all the registered networks, ordered by chain id.
+func (r *MeerNetworkRegistry) Networks() []*MeerChainConfig {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	networks := make([]*MeerChainConfig, 0, len(r.networks))
+	for _, network := range r.networks {
+		networks = append(networks, network)
+	}
+	slices.SortFunc(networks, func(a, b *MeerChainConfig) int {
+		return a.ChainID.Cmp(b.ChainID)
+	})
+	return networks
+}
+
+// Family returns the family of the network with the given chain id, or the
+// empty family if it's not a Qitmeer network.
+func (r *MeerNetworkRegistry) Family(chainID *big.Int) MeerFamily {
+	if network := r.Get(chainID); network != nil {
+		return network.Family
+	}
+	return ""
+}
+
+// MarshalJSON implements json.Marshaler, encoding the registry as a list of
+// networks ordered by chain id.
+func (r *MeerNetworkRegistry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Networks())
+}
+
+// LoadJSON registers all the networks from a JSON encoded list, as produced by
+// MarshalJSON. Networks with an already known chain id replace the existing
+// definition. Nothing is registered if any of the networks is invalid.
+func (r *MeerNetworkRegistry) LoadJSON(data []byte) error {
+	var networks []*MeerChainConfig
+	if err := json.Unmarshal(data, &networks); err != nil {
+		return err
+	}
+	return r.register(networks)
+}
+
+// NetworkName returns the user friendly name of the chain, or the empty string
+// if it's not a known network.
+func NetworkName(chainID *big.Int) string {
+	if network := MeerNetworks.Get(chainID); network != nil {
+		return network.Name
+	}
+	if chainID == nil {
+		return ""
 	}
-	return false
+	return NetworkNames[chainID.String()]
+}
+
+// IsMeerNetwork returns whether the chain id belongs to a known Qitmeer network.
+func IsMeerNetwork(chainID *big.Int) bool {
+	return MeerNetworks.Get(chainID) != nil
+}
+
+func IsQngNetwork(chainID *big.Int) bool {
+	return MeerNetworks.Family(chainID) == MeerFamilyQng
+}
+
+func IsAmanaNetwork(chainID *big.Int) bool {
+	return MeerNetworks.Family(chainID) == MeerFamilyAmana
+}
+
+func IsFlanaNetwork(chainID *big.Int) bool {
+	return MeerNetworks.Family(chainID) == MeerFamilyFlana
 }
 
 func IsMizanaNetwork(chainID *big.Int) bool {
-	if chainID.Cmp(MizanaChainConfig.ChainID) == 0 ||
-		chainID.Cmp(MizanaTestnetChainConfig.ChainID) == 0 ||
-		chainID.Cmp(MizanaMixnetChainConfig.ChainID) == 0 ||
-		chainID.Cmp(MizanaPrivnetChainConfig.ChainID) == 0 {
-		return true
+	return MeerNetworks.Family(chainID) == MeerFamilyMizana
+}
+
+// meerFeePolicy returns the fee policy of the Qitmeer network active at the
+// given block height, or nil if the default policy applies.
+func (c *ChainConfig) meerFeePolicy(num *big.Int) *MeerFeePolicy {
+	if num == nil {
+		return nil
 	}
-	return false
+	return activeFeePolicy(c.meerFeePolicies, num.Uint64())
+}
+
+// ElasticityMultiplierAt returns the elasticity multiplier of the block at the
+// given height, honoring the fee policy of Qitmeer networks.
+func (c *ChainConfig) ElasticityMultiplierAt(num *big.Int) uint64 {
+	if policy := c.meerFeePolicy(num); policy != nil && policy.ElasticityMultiplier != 0 {
+		return policy.ElasticityMultiplier
+	}
+	return c.ElasticityMultiplier()
+}
+
+// BaseFeeChangeDenominatorAt returns the base fee change denominator of the
+// block at the given height, honoring the fee policy of Qitmeer networks.
+func (c *ChainConfig) BaseFeeChangeDenominatorAt(num *big.Int) uint64 {
+	if policy := c.meerFeePolicy(num); policy != nil && policy.BaseFeeChangeDenominator != 0 {
+		return policy.BaseFeeChangeDenominator
+	}
+	return c.BaseFeeChangeDenominator()
+}
+
+// TargetGasLimit returns the gas limit the Qitmeer network policy targets for
+// the block at the given height, or zero if the miner's gas ceiling applies.
+func (c *ChainConfig) TargetGasLimit(num *big.Int) uint64 {
+	if policy := c.meerFeePolicy(num); policy != nil {
+		return policy.GasLimit
+	}
+	return 0
+}
+
+// GasLimitTransition returns the target gas limit if the block at the given
+// height activates a Qitmeer fee policy changing it. Such blocks may jump to
+// the new gas limit directly instead of converging towards it.
+func (c *ChainConfig) GasLimitTransition(num *big.Int) (uint64, bool) {
+	policy := c.meerFeePolicy(num)
+	if policy == nil || policy.GasLimit == 0 || policy.Block != num.Uint64() {
+		return 0, false
+	}
+	return policy.GasLimit, true
+}
+
+// ResolveMeerNetwork binds the definition of the Qitmeer network the chain id
+// refers to into the config. It must be called once the config is loaded, as
+// the per-block rules don't consult the network registry.
+func (c *ChainConfig) ResolveMeerNetwork() {
+	var (
+		bridge   *MeerBridgeConfig
+		policies []*MeerFeePolicy
+	)
+	if network := MeerNetworks.Get(c.ChainID); network != nil {
+		bridge, policies = network.Bridge, network.FeePolicies
+	}
+	c.SetMeerBridge(bridge)
+	c.SetMeerFeePolicies(policies)
+}
+
+// SetMeerFeePolicies binds the fee policies of the Qitmeer network into the
+// config, ordered by activation block. Nil restores the default policy.
+func (c *ChainConfig) SetMeerFeePolicies(policies []*MeerFeePolicy) {
+	// Avoid writing shared configs unless something changes
+	if !slices.Equal(c.meerFeePolicies, policies) {
+		c.meerFeePolicies = slices.Clone(policies)
+	}
+}
+
+// SetMeerBridge binds the bridge configuration of the Qitmeer network into the
+// config, nil deactivating the bridge precompile.
+func (c *ChainConfig) SetMeerBridge(bridge *MeerBridgeConfig) {
+	// Avoid writing shared configs unless something changes
+	if c.meerBridge != bridge {
+		c.meerBridge = bridge
+	}
+}
+
+// MeerBridge returns the bridge configuration of the Qitmeer network if the
+// bridge precompile is active at the given block height, nil otherwise.
+func (c *ChainConfig) MeerBridge(num *big.Int) *MeerBridgeConfig {
+	if c.meerBridge == nil || num == nil || num.Uint64() < c.meerBridge.Block {
+		return nil
+	}
+	return c.meerBridge
 }
 
 func QngEIPsBanner(banner string, c *ChainConfig) string {
+	if network := MeerNetworks.Get(c.ChainID); network != nil {
+		banner += fmt.Sprintf("Network:   %s (%s)\n", network.Family, network.Kind)
+		if network.GenesisHash != (common.Hash{}) {
+			banner += fmt.Sprintf("Genesis:   %x\n", network.GenesisHash)
+		}
+		if len(network.Bootnodes) > 0 {
+			banner += fmt.Sprintf("Bootnodes: %d configured\n", len(network.Bootnodes))
+		}
+		if network.QngEngine {
+			banner += "Driven by the QNG engine API\n"
+		}
+		if forks := network.Forks; forks != nil {
+			if forks.LondonBlock != nil {
+				banner += fmt.Sprintf("Scheduled London at #%-8v\n", forks.LondonBlock)
+			}
+			for _, fork := range []struct {
+				name string
+				time *uint64
+			}{{"Shanghai", forks.ShanghaiTime}, {"Cancun", forks.CancunTime}, {"Prague", forks.PragueTime}} {
+				if fork.time != nil {
+					banner += fmt.Sprintf("Scheduled %s at @%-10v\n", fork.name, *fork.time)
+				}
+			}
+		}
+		for _, policy := range network.FeePolicies {
+			banner += fmt.Sprintf("Fee policy from #%-8v (gas limit %d, elasticity %d, base fee denominator %d)\n",
+				policy.Block, policy.GasLimit, policy.ElasticityMultiplier, policy.BaseFeeChangeDenominator)
+		}
+		if network.Bridge != nil {
+			banner += fmt.Sprintf("UTXO bridge from #%-8v (operator %v)\n", network.Bridge.Block, network.Bridge.Operator)
+		}
+	}
 	banner += "\n"
 
 	// Create a list of forks with a short description of them. Forks that only
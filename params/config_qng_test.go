@@ -0,0 +1,147 @@
+package params
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestMeerNetworkRegistry(t *testing.T) {
+	tests := []struct {
+		chainID *big.Int
+		family  MeerFamily
+	}{
+		{big.NewInt(813), MeerFamilyQng},
+		{big.NewInt(81343), MeerFamilyAmana},
+		{big.NewInt(8135), MeerFamilyFlana},
+		{big.NewInt(81362), MeerFamilyMizana},
+		{big.NewInt(1), ""},
+	}
+	for i, tt := range tests {
+		if have := MeerNetworks.Family(tt.chainID); have != tt.family {
+			t.Errorf("test %d: family mismatch: have %q, want %q", i, have, tt.family)
+		}
+	}
+	if !IsQngNetwork(big.NewInt(8133)) || IsQngNetwork(big.NewInt(8134)) {
+		t.Errorf("IsQngNetwork mismatch")
+	}
+	if network := MeerNetworks.GetByName("amana-test"); network != AmanaTestnetChainConfig {
+		t.Errorf("network lookup by name failed: %v", network)
+	}
+	if len(MeerNetworks.Networks()) != 16 {
+		t.Errorf("unexpected number of networks: %d", len(MeerNetworks.Networks()))
+	}
+}
+
+func TestMeerNetworkRegistryJSON(t *testing.T) {
+	registry := NewMeerNetworkRegistry()
+	if err := registry.LoadJSON([]byte(`[
+		{"chainId": 990001, "name": "qng-dev", "family": "qng", "kind": "priv", "qngEngine": true, "genesisHash": "0x0000000000000000000000000000000000000000000000000000000000000001"},
+		{"chainId": 990002, "name": "amana-dev", "family": "amana", "kind": "priv", "bootnodes": ["enode://aa@127.0.0.1:30303"]}
+	]`)); err != nil {
+		t.Fatalf("failed to load networks: %v", err)
+	}
+	network := registry.Get(big.NewInt(990001))
+	if network == nil || !network.QngEngine || network.GenesisHash != common.HexToHash("0x01") {
+		t.Fatalf("network not loaded correctly: %+v", network)
+	}
+	if network := registry.GetByName("amana-dev"); network == nil || len(network.Bootnodes) != 1 {
+		t.Fatalf("network not loaded correctly: %+v", network)
+	}
+	// Exported registries must load back identically
+	blob, err := json.Marshal(registry)
+	if err != nil {
+		t.Fatalf("failed to export networks: %v", err)
+	}
+	reloaded := NewMeerNetworkRegistry()
+	if err := reloaded.LoadJSON(blob); err != nil {
+		t.Fatalf("failed to reload networks: %v", err)
+	}
+	if reblob, _ := json.Marshal(reloaded); string(reblob) != string(blob) {
+		t.Fatalf("export mismatch:\nhave %s\nwant %s", reblob, blob)
+	}
+	// Invalid definitions must be rejected as a whole
+	for i, invalid := range []string{
+		`[{"name": "x", "family": "qng", "kind": "main"}]`,
+		`[{"chainId": 990003, "name": "x", "family": "eth", "kind": "main"}]`,
+		`[{"chainId": 990003, "name": "x", "family": "qng", "kind": "beta"}]`,
+		`[{"chainId": 990003, "name": "qng-dev", "family": "qng", "kind": "main"}]`,
+		`[{"chainId": 990003, "name": "x", "family": "qng", "kind": "main", "feePolicies": [{"block": 10}, {"block": 5}]}]`,
+		`[{"chainId": 990003, "name": "x", "family": "qng", "kind": "main", "feePolicies": [{"block": 10, "gasLimit": 100}]}]`,
+		`[{"chainId": 990003, "name": "x", "family": "qng", "kind": "main"}, {"chainId": 990004, "name": "x", "family": "qng", "kind": "main"}]`,
+		`[{"chainId": 990003, "name": "x", "family": "qng", "kind": "main"}, {"chainId": 990004, "name": "amana-dev", "family": "qng", "kind": "main"}]`,
+	} {
+		if err := registry.LoadJSON([]byte(invalid)); err == nil {
+			t.Errorf("test %d: expected invalid network to be rejected", i)
+		}
+		if network := registry.Get(big.NewInt(990003)); network != nil {
+			t.Errorf("test %d: network of rejected definitions registered", i)
+		}
+	}
+}
+
+func TestMeerNetworkDescription(t *testing.T) {
+	config := *AllDevChainProtocolChanges
+	config.ChainID = QngTestnetChainConfig.ChainID
+	desc := config.Description()
+	for _, want := range []string{"(qng-test)", "MeerDAG (proof-of-work)", "qng (test)", "QNG engine API"} {
+		if !strings.Contains(desc, want) {
+			t.Errorf("description missing %q:\n%s", want, desc)
+		}
+	}
+}
+
+func TestMeerNetworkResolve(t *testing.T) {
+	network := &MeerChainConfig{
+		ChainID:     big.NewInt(990300),
+		Name:        "resolve-test",
+		Family:      MeerFamilyQng,
+		Kind:        MeerPrivnet,
+		FeePolicies: []*MeerFeePolicy{{Block: 10, GasLimit: 30000000}},
+	}
+	if err := MeerNetworks.Register(network); err != nil {
+		t.Fatalf("failed to register network: %v", err)
+	}
+	t.Cleanup(func() { MeerNetworks.Unregister(network.ChainID) })
+
+	config := *AllDevChainProtocolChanges
+	config.ChainID = network.ChainID
+	config.ResolveMeerNetwork()
+
+	// The bound policies must not follow later changes of the registry
+	MeerNetworks.Unregister(network.ChainID)
+	if have := config.TargetGasLimit(big.NewInt(10)); have != 30000000 {
+		t.Fatalf("target gas limit mismatch: have %d, want %d", have, 30000000)
+	}
+	if have := config.TargetGasLimit(big.NewInt(9)); have != 0 {
+		t.Fatalf("inactive target gas limit mismatch: have %d, want 0", have)
+	}
+	config.ResolveMeerNetwork()
+	if have := config.TargetGasLimit(big.NewInt(10)); have != 0 {
+		t.Fatalf("unbound target gas limit mismatch: have %d, want 0", have)
+	}
+}
+
+func TestMeerForkSchedule(t *testing.T) {
+	config := *AllDevChainProtocolChanges
+	config.CancunTime = newUint64(100)
+
+	tests := []struct {
+		forks *MeerForkSchedule
+		ok    bool
+	}{
+		{&MeerForkSchedule{}, true},
+		{&MeerForkSchedule{LondonBlock: big.NewInt(0), CancunTime: newUint64(100)}, true},
+		{&MeerForkSchedule{LondonBlock: big.NewInt(10)}, false},
+		{&MeerForkSchedule{CancunTime: newUint64(200)}, false},
+		{&MeerForkSchedule{PragueTime: newUint64(300)}, false},
+	}
+	for i, tt := range tests {
+		if err := tt.forks.CheckCompatible(&config); (err == nil) != tt.ok {
+			t.Errorf("test %d: compatibility mismatch: have %v, want ok %v", i, err, tt.ok)
+		}
+	}
+}
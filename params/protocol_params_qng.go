@@ -0,0 +1,18 @@
+package params
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	MeerBridgeImportGas     uint64 = 60000 // Gas cost of importing a locked UTXO into the EVM
+	MeerBridgeExportGas     uint64 = 40000 // Base gas cost of exporting native balance to the UTXO layer
+	MeerBridgeExportByteGas uint64 = 16    // Gas cost per byte of the UTXO layer destination of an export
+	MeerBridgeQueryGas      uint64 = 2100  // Gas cost of checking whether a UTXO was imported
+
+	MaxMeerBridgeDestination = 128 // Maximum length of the UTXO layer destination of an export
+)
+
+// MeerBridgeAddress is the address of the UTXO <-> EVM bridge precompile of
+// Qitmeer networks.
+var MeerBridgeAddress = common.HexToAddress("0x0000000000000000000000000000000000000813")